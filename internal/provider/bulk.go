@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// bulkOperation is a single named step of a bulk resource, such as copying
+// one object or tagging one node.
+type bulkOperation struct {
+	ID  string
+	Run func() error
+}
+
+// bulkError reports the failures of a bulk run. Aborted is set when the run
+// stopped at the first failure, in which case Skipped holds the operations
+// that were never attempted.
+type bulkError struct {
+	Total   int
+	Aborted bool
+	Failed  []string
+	Errors  []error
+	Skipped []string
+}
+
+func (e *bulkError) Error() string {
+	var b strings.Builder
+	if e.Aborted {
+		fmt.Fprintf(&b, "aborted after %s failed; %d of %d operations were not attempted", e.Failed[0], len(e.Skipped), e.Total)
+	} else {
+		fmt.Fprintf(&b, "partial failure: %d of %d operations failed", len(e.Failed), e.Total)
+	}
+	for i, id := range e.Failed {
+		fmt.Fprintf(&b, "\n  - %s: %s", id, e.Errors[i])
+	}
+	return b.String()
+}
+
+// runBulk runs every operation in order. By default all operations are
+// attempted and their failures collected; with stopOnError the run halts at
+// the first failure. Either way the result is nil or a *bulkError.
+func runBulk(ops []bulkOperation, stopOnError bool) error {
+	result := &bulkError{Total: len(ops)}
+
+	for i, op := range ops {
+		if err := op.Run(); err != nil {
+			result.Failed = append(result.Failed, op.ID)
+			result.Errors = append(result.Errors, err)
+
+			if stopOnError {
+				result.Aborted = true
+				for _, skipped := range ops[i+1:] {
+					result.Skipped = append(result.Skipped, skipped.ID)
+				}
+				break
+			}
+		}
+	}
+
+	if len(result.Failed) == 0 {
+		return nil
+	}
+	return result
+}
+
+// runBulkConcurrent runs every operation with up to concurrency of them at
+// once, collecting all failures. Failures are reported in operation order
+// regardless of the order they finished in.
+func runBulkConcurrent(ops []bulkOperation, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(ops))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, op := range ops {
+		i, op := i, op
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			errs[i] = op.Run()
+		}()
+	}
+	wg.Wait()
+
+	result := &bulkError{Total: len(ops)}
+	for i, err := range errs {
+		if err != nil {
+			result.Failed = append(result.Failed, ops[i].ID)
+			result.Errors = append(result.Errors, err)
+		}
+	}
+
+	if len(result.Failed) == 0 {
+		return nil
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func testBulkOperations(calls *[]string) []bulkOperation {
+	op := func(id string, err error) bulkOperation {
+		return bulkOperation{ID: id, Run: func() error {
+			*calls = append(*calls, id)
+			return err
+		}}
+	}
+	return []bulkOperation{
+		op("a", nil),
+		op("b", errors.New("boom")),
+		op("c", nil),
+		op("d", errors.New("bang")),
+	}
+}
+
+func TestRunBulk_collectAll(t *testing.T) {
+	var calls []string
+	err := runBulk(testBulkOperations(&calls), false)
+
+	if len(calls) != 4 {
+		t.Fatalf("expected every operation to run, got %v", calls)
+	}
+	bulkErr, ok := err.(*bulkError)
+	if !ok {
+		t.Fatalf("expected a *bulkError, got %#v", err)
+	}
+	if bulkErr.Aborted || len(bulkErr.Failed) != 2 {
+		t.Fatalf("unexpected result: %#v", bulkErr)
+	}
+	if !strings.HasPrefix(err.Error(), "partial failure: 2 of 4 operations failed") {
+		t.Fatalf("unexpected message: %s", err)
+	}
+}
+
+func TestRunBulk_stopOnError(t *testing.T) {
+	var calls []string
+	err := runBulk(testBulkOperations(&calls), true)
+
+	if len(calls) != 2 {
+		t.Fatalf("expected the run to stop at the first failure, got %v", calls)
+	}
+	bulkErr, ok := err.(*bulkError)
+	if !ok {
+		t.Fatalf("expected a *bulkError, got %#v", err)
+	}
+	if !bulkErr.Aborted || len(bulkErr.Skipped) != 2 {
+		t.Fatalf("unexpected result: %#v", bulkErr)
+	}
+	if !strings.HasPrefix(err.Error(), "aborted after b failed; 2 of 4 operations were not attempted") {
+		t.Fatalf("unexpected message: %s", err)
+	}
+}
+
+func TestRunBulk_success(t *testing.T) {
+	if err := runBulk([]bulkOperation{{ID: "a", Run: func() error { return nil }}}, true); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestRunBulkConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	ops := testBulkOperations(&calls)
+	for i := range ops {
+		run := ops[i].Run
+		ops[i].Run = func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			return run()
+		}
+	}
+
+	err := runBulkConcurrent(ops, 3)
+	if len(calls) != 4 {
+		t.Fatalf("expected every operation to run, got %v", calls)
+	}
+	bulkErr, ok := err.(*bulkError)
+	if !ok {
+		t.Fatalf("expected a *bulkError, got %#v", err)
+	}
+	if len(bulkErr.Failed) != 2 || bulkErr.Failed[0] != "b" || bulkErr.Failed[1] != "d" {
+		t.Fatalf("expected failures in operation order, got %v", bulkErr.Failed)
+	}
+}
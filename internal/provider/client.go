@@ -0,0 +1,321 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// errPreconditionFailed is returned by conditionalPut when the server
+// rejected an update because the object changed since it was last read.
+var errPreconditionFailed = errors.New("object was modified on the Chef server since it was last read; refresh and try again")
+
+// chefAccessKey mirrors chefc.AccessKey, but normalizes the expiration
+// date the server returns.
+type chefAccessKey struct {
+	Name           string        `json:"name,omitempty"`
+	PublicKey      string        `json:"public_key,omitempty"`
+	ExpirationDate chefTimestamp `json:"expiration_date,omitempty"`
+}
+
+// newRequestWithContext builds and signs a request like client.NewRequest,
+// bound to ctx so that it is abandoned as soon as Terraform cancels the
+// operation. go-chef's own service methods have no way to take a context,
+// so requests the provider builds itself should be made with this.
+func newRequestWithContext(ctx context.Context, client *chefc.Client, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := client.NewRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	return req.WithContext(context.WithValue(ctx, operationContextKey{}, true)), nil
+}
+
+// operationContextKey marks the context of a request made with
+// newRequestWithContext, which carries the logger of the Terraform operation
+// the request is made for.
+type operationContextKey struct{}
+
+func operationContext(ctx context.Context) bool {
+	return ctx.Value(operationContextKey{}) != nil
+}
+
+// generateAccessKey asks the server to generate a key pair and add it to
+// the user or client under path, such as users/NAME, returning the private
+// key. go-chef's AccessKey has no create_key field, so the request is made
+// here.
+func generateAccessKey(ctx context.Context, client *chefc.Client, path, name, expirationDate string) (string, error) {
+	body, err := chefc.JSONReader(map[string]interface{}{
+		"name":            name,
+		"create_key":      true,
+		"expiration_date": expirationDate,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := newRequestWithContext(ctx, client, "POST", path+"/keys", body)
+	if err != nil {
+		return "", err
+	}
+
+	var key struct {
+		PrivateKey string `json:"private_key"`
+	}
+	res, err := client.Do(req, &key)
+	if res != nil {
+		defer res.Body.Close()
+	}
+	return key.PrivateKey, err
+}
+
+// getAccessKey reads a single user or client key from path, such as
+// users/NAME/keys/KEY or clients/NAME/keys/KEY.
+func getAccessKey(ctx context.Context, client *chefc.Client, path string) (key chefAccessKey, err error) {
+	req, err := newRequestWithContext(ctx, client, "GET", path, nil)
+	if err != nil {
+		return key, err
+	}
+
+	res, err := client.Do(req, &key)
+	if res != nil {
+		defer res.Body.Close()
+	}
+	return key, err
+}
+
+// listAccessKeys lists the keys of the user or client under path, such as
+// users/NAME.
+func listAccessKeys(ctx context.Context, client *chefc.Client, path string) (keys []chefc.KeyItem, err error) {
+	err = sendAccessKeyRequest(ctx, client, "GET", path+"/keys", nil, &keys)
+	return keys, err
+}
+
+// addAccessKey adds key to the user or client under path, such as
+// users/NAME.
+func addAccessKey(ctx context.Context, client *chefc.Client, path string, key chefc.AccessKey) error {
+	return sendAccessKeyRequest(ctx, client, "POST", path+"/keys", key, nil)
+}
+
+// updateAccessKey replaces the key at path, such as users/NAME/keys/KEY,
+// with key, which may rename it.
+func updateAccessKey(ctx context.Context, client *chefc.Client, path string, key chefc.AccessKey) error {
+	return sendAccessKeyRequest(ctx, client, "PUT", path, key, nil)
+}
+
+// deleteAccessKey deletes the key at path, such as users/NAME/keys/KEY.
+func deleteAccessKey(ctx context.Context, client *chefc.Client, path string) error {
+	return sendAccessKeyRequest(ctx, client, "DELETE", path, nil, nil)
+}
+
+// sendAccessKeyRequest makes the key requests go-chef's Users and Clients
+// services would, but bound to ctx.
+func sendAccessKeyRequest(ctx context.Context, client *chefc.Client, method, path string, body, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		var err error
+		if reader, err = chefc.JSONReader(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := newRequestWithContext(ctx, client, method, path, reader)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req, v)
+	if res != nil {
+		defer res.Body.Close()
+	}
+	return err
+}
+
+// chefValidators holds the cache validators the server returned for an
+// object, which are replayed as preconditions on the next update.
+type chefValidators struct {
+	ETag         string
+	LastModified string
+}
+
+func validatorsFromResourceData(d *schema.ResourceData) chefValidators {
+	return chefValidators{
+		ETag:         d.Get("etag").(string),
+		LastModified: d.Get("last_modified").(string),
+	}
+}
+
+func (v chefValidators) setResourceData(d *schema.ResourceData) {
+	d.Set("etag", v.ETag)
+	d.Set("last_modified", v.LastModified)
+}
+
+// getWithValidators behaves like a plain GET of path decoded into v, but
+// also returns any ETag or Last-Modified headers sent with the response.
+func (c *chefClient) getWithValidators(ctx context.Context, path string, v interface{}) (chefValidators, error) {
+	req, err := newRequestWithContext(ctx, c.Client, "GET", path, nil)
+	if err != nil {
+		return chefValidators{}, err
+	}
+
+	res, err := c.do(c.Client, req, v)
+	if res != nil {
+		defer res.Body.Close()
+	}
+	if err != nil {
+		return chefValidators{}, err
+	}
+
+	return chefValidators{
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// conditionalPut sends body to path as a PUT, guarded by If-Match or
+// If-Unmodified-Since when validators were captured on the previous read.
+// Servers that did not send validators get a plain, unconditional PUT.
+func (c *chefClient) conditionalPut(ctx context.Context, path string, body interface{}, validators chefValidators) error {
+	reader, err := chefc.JSONReader(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := newRequestWithContext(ctx, c.Client, "PUT", path, reader)
+	if err != nil {
+		return err
+	}
+
+	if validators.ETag != "" {
+		req.Header.Set("If-Match", validators.ETag)
+	} else if validators.LastModified != "" {
+		req.Header.Set("If-Unmodified-Since", validators.LastModified)
+	}
+
+	res, err := c.Do(req, nil)
+	if res != nil {
+		defer res.Body.Close()
+	}
+	var errRes *chefc.ErrorResponse
+	if errors.As(err, &errRes) && errRes.Response != nil && errRes.StatusCode() == http.StatusPreconditionFailed {
+		return fmt.Errorf("%s: %w", path, errPreconditionFailed)
+	}
+	return err
+}
+
+// orgDo sends a request for path within the named organization through the
+// server-level client, for resources that manage an organization other
+// than the one the provider is configured against.
+func (c *chefClient) orgDo(ctx context.Context, org, method, path string, body, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		var err error
+		if reader, err = chefc.JSONReader(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := newRequestWithContext(ctx, c.Global, method, "organizations/"+org+"/"+path, reader)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.do(c.Global, req, v)
+	if res != nil {
+		defer res.Body.Close()
+	}
+	return err
+}
+
+// signedHeaders signs a request for path exactly as it would be sent and
+// returns its headers, including the chunked X-Ops-Authorization-N
+// signature, without sending it. Only the resulting signature leaves the
+// client; the private key itself is never part of the header set.
+func signedHeaders(client *chefc.Client, method, path string, body io.Reader) (string, map[string]string, error) {
+	req, err := client.NewRequest(method, path, body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	headers := make(map[string]string, len(req.Header))
+	for name := range req.Header {
+		headers[name] = req.Header.Get(name)
+	}
+	return req.URL.String(), headers, nil
+}
+
+// do sends req through client and decodes the response into v.
+//
+// go-chef only hands a body labeled exactly text/plain to a *string, and
+// decodes anything else as JSON. Here a *[]byte gets the raw body whatever
+// its type, a *string gets any text/plain body, and a text/plain body for
+// any other v is decoded by decodeTextResponse rather than failing as
+// malformed JSON. go-chef also ignores fields v has no
+// place for, so with StrictDecoding any field the provider doesn't handle
+// is an error. Requests sent through go-chef's services don't come here
+// and are never decoded strictly.
+func (c *chefClient) do(client *chefc.Client, req *http.Request, v interface{}) (*http.Response, error) {
+	switch v.(type) {
+	case nil, io.Writer:
+		return client.Do(req, v)
+	}
+
+	var buf bytes.Buffer
+	res, err := client.Do(req, &buf)
+	if err != nil {
+		return res, err
+	}
+
+	if raw, ok := v.(*[]byte); ok {
+		*raw = buf.Bytes()
+		return res, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if text, ok := v.(*string); ok && mediaType == "text/plain" {
+		*text = buf.String()
+		return res, nil
+	}
+
+	if mediaType == "text/plain" {
+		err = decodeTextResponse(buf.Bytes(), v)
+	} else {
+		dec := json.NewDecoder(&buf)
+		if c.options != nil && c.options.StrictDecoding {
+			dec.DisallowUnknownFields()
+		}
+		err = dec.Decode(v)
+	}
+	if err != nil {
+		return res, fmt.Errorf("decoding response to %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	return res, nil
+}
+
+// decodeTextResponse decodes a text/plain body into v: through
+// UnmarshalText when v has it, or as JSON when the body is JSON sent with
+// the wrong type, as some servers and proxies do. Anything else can't be
+// decoded into v and is an error that shows the start of the text.
+func decodeTextResponse(data []byte, v interface{}) error {
+	if u, ok := v.(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText(data)
+	}
+	if json.Valid(data) {
+		return json.Unmarshal(data, v)
+	}
+
+	text := string(data)
+	if len(text) > 100 {
+		text = text[:100] + "..."
+	}
+	return fmt.Errorf("text/plain response can't be decoded into %T: %q", v, text)
+}
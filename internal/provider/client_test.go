@@ -0,0 +1,325 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// testChefConfig returns a client configuration pointed at an in-process
+// HTTP server running handler, for exercising request-level helpers without
+// a real Chef server.
+func testChefConfig(t *testing.T, handler http.HandlerFunc) chefc.Config {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+
+	return chefc.Config{
+		Name:    "test",
+		Key:     string(keyPEM),
+		BaseURL: server.URL + "/organizations/test/",
+	}
+}
+
+func testChefClient(t *testing.T, handler http.HandlerFunc) *chefClient {
+	t.Helper()
+
+	config := testChefConfig(t, handler)
+	client, err := chefc.NewClient(&config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return &chefClient{client, client, &clientOptions{}, "test"}
+}
+
+func TestConditionalPut_ifMatch(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("If-Match"); got != `"abc"` {
+			t.Errorf("wrong If-Match header: %q", got)
+		}
+		if got := r.Header.Get("If-Unmodified-Since"); got != "" {
+			t.Errorf("unexpected If-Unmodified-Since header: %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	})
+
+	validators := chefValidators{ETag: `"abc"`, LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"}
+	if err := c.conditionalPut(context.Background(), "roles/web", map[string]string{"name": "web"}, validators); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestConditionalPut_unconditional(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Match") != "" || r.Header.Get("If-Unmodified-Since") != "" {
+			t.Errorf("unexpected precondition headers: %v", r.Header)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	})
+
+	if err := c.conditionalPut(context.Background(), "roles/web", map[string]string{"name": "web"}, chefValidators{}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestConditionalPut_preconditionFailed(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+	})
+
+	validators := chefValidators{LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"}
+	err := c.conditionalPut(context.Background(), "roles/web", map[string]string{"name": "web"}, validators)
+	if !errors.Is(err, errPreconditionFailed) {
+		t.Fatalf("expected errPreconditionFailed, got %v", err)
+	}
+}
+
+func TestSignedHeaders(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+
+	url, headers, err := signedHeaders(c.Client, "PUT", "nodes/web1", strings.NewReader(`{"name":"web1"}`))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !strings.HasSuffix(url, "/organizations/test/nodes/web1") {
+		t.Fatalf("wrong url: %s", url)
+	}
+	for _, name := range []string{"X-Ops-Userid", "X-Ops-Timestamp", "X-Ops-Content-Hash", "X-Ops-Sign", "X-Ops-Authorization-1"} {
+		if headers[name] == "" {
+			t.Errorf("missing header %s in %v", name, headers)
+		}
+	}
+	for name, value := range headers {
+		if strings.Contains(value, "PRIVATE KEY") {
+			t.Errorf("header %s contains the private key", name)
+		}
+	}
+}
+
+// TestBodyHash pins the content hashes go-chef signs requests with, since a
+// wrong hash makes the server reject every request carrying that body.
+func TestBodyHash(t *testing.T) {
+	cases := []struct {
+		body         chefc.Body
+		sha1, sha256 string
+	}{
+		{chefc.Body{}, "2jmj7l5rSw0yVb/vlWAYkK/YBwk=", "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+		{chefc.Body{Reader: bytes.NewReader(nil)}, "2jmj7l5rSw0yVb/vlWAYkK/YBwk=", "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+		{chefc.Body{Reader: bytes.NewReader([]byte(`{"name":"web1"}`))}, "", ""},
+	}
+	sum1 := sha1.Sum([]byte(`{"name":"web1"}`))
+	sum256 := sha256.Sum256([]byte(`{"name":"web1"}`))
+	cases[2].sha1 = base64.StdEncoding.EncodeToString(sum1[:])
+	cases[2].sha256 = base64.StdEncoding.EncodeToString(sum256[:])
+
+	for i, c := range cases {
+		if got := c.body.Hash(); got != c.sha1 {
+			t.Errorf("case %d: Hash() = %q, expected %q", i, got, c.sha1)
+		}
+		if got := c.body.Hash256(); got != c.sha256 {
+			t.Errorf("case %d: Hash256() = %q, expected %q", i, got, c.sha256)
+		}
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"web","description":"","policy_name":"web"}`))
+	})
+
+	var role struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if _, err := c.getWithValidators(context.Background(), "roles/web", &role); err != nil || role.Name != "web" {
+		t.Fatalf("expected lenient decoding to ignore unknown fields, got %v", err)
+	}
+
+	c.options.StrictDecoding = true
+	_, err := c.getWithValidators(context.Background(), "roles/web", &role)
+	if err == nil || !strings.Contains(err.Error(), "policy_name") {
+		t.Fatalf("expected strict decoding to reject the unknown field, got %v", err)
+	}
+}
+
+// textRecipe decodes a text/plain body through UnmarshalText.
+type textRecipe struct {
+	Content string
+}
+
+func (r *textRecipe) UnmarshalText(text []byte) error {
+	r.Content = string(text)
+	return nil
+}
+
+func TestDoTextResponse(t *testing.T) {
+	body := "package 'ntp'\n"
+	contentType := "text/plain; charset=utf-8"
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(body))
+	})
+	get := func(v interface{}) error {
+		req, err := newRequestWithContext(context.Background(), c.Client, "GET", "required_recipe", nil)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		_, err = c.do(c.Client, req, v)
+		return err
+	}
+
+	var text string
+	if err := get(&text); err != nil || text != body {
+		t.Fatalf("expected the text in a *string, got %q, %v", text, err)
+	}
+
+	var raw []byte
+	if err := get(&raw); err != nil || string(raw) != body {
+		t.Fatalf("expected the raw body in a *[]byte, got %q, %v", raw, err)
+	}
+
+	var recipe textRecipe
+	if err := get(&recipe); err != nil || recipe.Content != body {
+		t.Fatalf("expected the text to be decoded with UnmarshalText, got %q, %v", recipe.Content, err)
+	}
+
+	var stats struct {
+		Name string `json:"name"`
+	}
+	err := get(&stats)
+	if err == nil || !strings.Contains(err.Error(), "text/plain response can't be decoded") || !strings.Contains(err.Error(), "package 'ntp'") {
+		t.Fatalf("expected an error showing the text, got %v", err)
+	}
+
+	// JSON labeled as plain text is decoded as JSON.
+	body = `{"name":"erchef"}`
+	if err := get(&stats); err != nil || stats.Name != "erchef" {
+		t.Fatalf("expected mislabeled JSON to be decoded, got %+v, %v", stats, err)
+	}
+
+	// A *[]byte gets the raw body whatever its type.
+	contentType = "application/json"
+	if err := get(&raw); err != nil || string(raw) != body {
+		t.Fatalf("expected the raw JSON body in a *[]byte, got %q, %v", raw, err)
+	}
+}
+
+func TestNewRequestWithContext(t *testing.T) {
+	release := make(chan struct{})
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := getAccessKey(ctx, c.Client, "users/alice/keys/default")
+		done <- err
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected the request to be cancelled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request kept waiting after its context was cancelled")
+	}
+}
+
+func TestAccessKeyRequests(t *testing.T) {
+	var requests []string
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "GET" {
+			w.Write([]byte(`[{"name":"default","uri":"https://chef/users/alice/keys/default","expired":false}]`))
+			return
+		}
+		w.Write([]byte("{}"))
+	})
+
+	ctx := context.Background()
+	key := chefc.AccessKey{Name: "ci", PublicKey: "PUBLIC", ExpirationDate: "infinity"}
+	keys, err := listAccessKeys(ctx, c.Global, "users/alice")
+	if err != nil || len(keys) != 1 || keys[0].Name != "default" {
+		t.Fatalf("unexpected keys %v: %v", keys, err)
+	}
+	if err := addAccessKey(ctx, c.Global, "users/alice", key); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := updateAccessKey(ctx, c.Global, "users/alice/keys/ci", key); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := deleteAccessKey(ctx, c.Global, "users/alice/keys/ci"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{
+		"GET /organizations/test/users/alice/keys",
+		"POST /organizations/test/users/alice/keys",
+		"PUT /organizations/test/users/alice/keys/ci",
+		"DELETE /organizations/test/users/alice/keys/ci",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected requests\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(requests, "\n"))
+	}
+}
+
+func TestAccessKeyRequests_cancelled(t *testing.T) {
+	release := make(chan struct{})
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- addAccessKey(ctx, c.Global, "users/alice", chefc.AccessKey{Name: "ci"})
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected the request to be cancelled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request kept waiting after its context was cancelled")
+	}
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// defaultCredentialsFile is where knife and the chef CLI look for their
+// credentials.
+const defaultCredentialsFile = "~/.chef/credentials"
+
+// knifeProfile holds the settings of one profile of a knife credentials
+// file that the provider can connect with.
+type knifeProfile struct {
+	ClientName    string
+	ChefServerURL string
+
+	// ClientKey is the PEM-encoded key itself, read from the file named by
+	// client_key unless the profile holds the key inline.
+	ClientKey string
+}
+
+// knifeContextProfile returns the profile selected with `knife config
+// use-profile`, which knife records in ~/.chef/context, or "default".
+func knifeContextProfile() string {
+	if path, err := expandHomePath("~/.chef/context"); err == nil {
+		if contents, err := os.ReadFile(path); err == nil {
+			if name := strings.TrimSpace(string(contents)); name != "" {
+				return name
+			}
+		}
+	}
+	return "default"
+}
+
+// knifeCredentials is the part of a knife credentials profile the provider
+// uses. Any other settings, such as knife's own, are ignored.
+type knifeCredentials struct {
+	ClientName    string `toml:"client_name"`
+	NodeName      string `toml:"node_name"`
+	ClientKey     string `toml:"client_key"`
+	ChefServerURL string `toml:"chef_server_url"`
+}
+
+// loadKnifeProfile reads the profile called name from the credentials file
+// at path. Errors reading the file itself are returned as is, so that
+// callers can tell a missing file apart with os.IsNotExist.
+func loadKnifeProfile(path, name string) (profile knifeProfile, err error) {
+	if path, err = expandHomePath(path); err != nil {
+		return profile, err
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return profile, err
+	}
+
+	var profiles map[string]knifeCredentials
+	if _, err := toml.Decode(string(contents), &profiles); err != nil {
+		return profile, fmt.Errorf("parsing %s: %s", path, err)
+	}
+	settings, ok := profiles[name]
+	if !ok {
+		return profile, fmt.Errorf("no profile named %q in %s", name, path)
+	}
+
+	// knife accepts node_name as another name for client_name.
+	profile.ClientName = settings.ClientName
+	if profile.ClientName == "" {
+		profile.ClientName = settings.NodeName
+	}
+
+	// knife doesn't need the trailing slash the provider requires.
+	profile.ChefServerURL = settings.ChefServerURL
+	if profile.ChefServerURL != "" && !strings.HasSuffix(profile.ChefServerURL, "/") {
+		profile.ChefServerURL += "/"
+	}
+
+	// As in knife, client_key holds either the key or the path to it.
+	switch key := settings.ClientKey; {
+	case strings.Contains(key, "PRIVATE KEY-----"):
+		profile.ClientKey = key
+	case key != "":
+		keyPath, err := expandHomePath(key)
+		if err == nil {
+			var contents []byte
+			contents, err = os.ReadFile(keyPath)
+			profile.ClientKey = string(contents)
+		}
+		if err != nil {
+			return profile, fmt.Errorf("reading client_key of profile %q: %s", name, err)
+		}
+	}
+	return profile, nil
+}
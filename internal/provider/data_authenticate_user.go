@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func dataChefAuthenticateUser() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadAuthenticateUser,
+
+		Schema: map[string]*schema.Schema{
+			"username": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"password": {
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
+			},
+			"verified": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the server accepted the username and password.",
+			},
+			"linked_ldap_account": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the user is linked to an LDAP account. Only known when verified is true.",
+			},
+		},
+	}
+}
+
+func ReadAuthenticateUser(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	username := d.Get("username").(string)
+	body, err := chefc.JSONReader(chefc.Authenticate{
+		UserName: username,
+		Password: d.Get("password").(string),
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	req, err := newRequestWithContext(ctx, client.Global, "POST", "authenticate_user", body)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var res struct {
+		Status string `json:"status"`
+	}
+	_, err = client.Global.Do(req, &res)
+
+	verified := err == nil
+	if isRejectedCredentials(err) {
+		err = nil
+	}
+	if err != nil {
+		return chefErrToDiag("Error authenticating user", err, cty.GetAttrPath("username"))
+	}
+
+	d.SetId(username)
+	d.Set("verified", verified)
+	d.Set("linked_ldap_account", verified && res.Status == "linked")
+	return nil
+}
+
+// isRejectedCredentials reports whether err is the server turning down the
+// username and password, as opposed to the provider's own signed request
+// being refused, which the server also reports as a 401.
+func isRejectedCredentials(err error) bool {
+	var errRes *chefc.ErrorResponse
+	if !errors.As(err, &errRes) || errRes.Response == nil || errRes.StatusCode() != http.StatusUnauthorized {
+		return false
+	}
+	msg := errRes.StatusMsg()
+	return !strings.Contains(msg, "Failed to authenticate as") && !strings.Contains(msg, "Invalid signature")
+}
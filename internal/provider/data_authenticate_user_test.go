@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestReadAuthenticateUser(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/organizations/test/authenticate_user" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var creds struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		json.NewDecoder(r.Body).Decode(&creds)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case creds.Username == "alice" && creds.Password == "secret":
+			w.Write([]byte(`{"status": "linked", "user": {"username": "alice"}}`))
+		case creds.Username == "alice":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "Failed to authenticate: Username and password incorrect"}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": ["Failed to authenticate as 'pivotal'. Ensure that your node_name and client key are correct."]}`))
+		}
+	})
+
+	cases := []struct {
+		username, password string
+		verified, linked   bool
+	}{
+		{"alice", "secret", true, true},
+		{"alice", "wrong", false, false},
+	}
+	for _, tc := range cases {
+		d := schema.TestResourceDataRaw(t, dataChefAuthenticateUser().Schema, map[string]interface{}{
+			"username": tc.username,
+			"password": tc.password,
+		})
+		if diags := ReadAuthenticateUser(context.Background(), d, c); diags.HasError() {
+			t.Fatalf("err: %v", diags)
+		}
+		if d.Get("verified").(bool) != tc.verified || d.Get("linked_ldap_account").(bool) != tc.linked {
+			t.Fatalf("%s/%s: expected verified %t and linked %t, got %v and %v", tc.username, tc.password,
+				tc.verified, tc.linked, d.Get("verified"), d.Get("linked_ldap_account"))
+		}
+	}
+
+	// The provider's own request being refused is an error, not a failed
+	// authentication.
+	d := schema.TestResourceDataRaw(t, dataChefAuthenticateUser().Schema, map[string]interface{}{
+		"username": "bob",
+		"password": "secret",
+	})
+	if diags := ReadAuthenticateUser(context.Background(), d, c); !diags.HasError() {
+		t.Fatal("expected a rejected request signature to be an error")
+	}
+}
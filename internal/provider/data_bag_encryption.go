@@ -0,0 +1,267 @@
+package provider
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// dataBagEncryptionVersions are the encrypted data bag item formats Chef
+// understands: 1 is AES-256-CBC, 2 adds an HMAC over the ciphertext and 3
+// is AES-256-GCM.
+var dataBagEncryptionVersions = []int{1, 2, 3}
+
+// encryptedDataBagValue is a single encrypted field of a data bag item.
+// Every field but id is encrypted separately.
+type encryptedDataBagValue struct {
+	EncryptedData string `json:"encrypted_data"`
+	IV            string `json:"iv"`
+	Version       int    `json:"version"`
+	Cipher        string `json:"cipher"`
+	HMAC          string `json:"hmac,omitempty"`
+	AuthTag       string `json:"auth_tag,omitempty"`
+}
+
+func validateDataBagEncryptionVersion(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := dataBagEncryptionCipher(val.(int)); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %s", key, err))
+	}
+	return
+}
+
+func dataBagEncryptionCipher(version int) (string, error) {
+	switch version {
+	case 1, 2:
+		return "aes-256-cbc", nil
+	case 3:
+		return "aes-256-gcm", nil
+	}
+	return "", fmt.Errorf("unsupported encrypted data bag version %d, must be one of %v", version, dataBagEncryptionVersions)
+}
+
+// dataBagSecretKey derives the AES key from a shared secret the same way
+// chef-client does, ignoring the surrounding whitespace a secret file
+// usually has.
+func dataBagSecretKey(secret string) ([]byte, []byte) {
+	raw := []byte(strings.TrimSpace(secret))
+	key := sha256.Sum256(raw)
+	return raw, key[:]
+}
+
+// encryptDataBagItem encrypts every field of item except id.
+func encryptDataBagItem(item map[string]interface{}, secret string, version int) (map[string]interface{}, error) {
+	encrypted := make(map[string]interface{}, len(item))
+	for k, v := range item {
+		if k == "id" {
+			encrypted[k] = v
+			continue
+		}
+		value, err := encryptDataBagValue(v, secret, version)
+		if err != nil {
+			return nil, fmt.Errorf("encrypting %s: %s", k, err)
+		}
+		encrypted[k] = value
+	}
+	return encrypted, nil
+}
+
+// decryptDataBagItem decrypts every encrypted field of item. Fields that
+// aren't encrypted are returned as they are, so that an item changed to
+// plain text on the server shows up as drift rather than an error.
+func decryptDataBagItem(item map[string]interface{}, secret string) (map[string]interface{}, error) {
+	decrypted := make(map[string]interface{}, len(item))
+	for k, v := range item {
+		value, ok := encryptedDataBagValueFrom(v)
+		if k == "id" || !ok {
+			decrypted[k] = v
+			continue
+		}
+		plain, err := decryptDataBagValue(value, secret)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting %s: %s", k, err)
+		}
+		decrypted[k] = plain
+	}
+	return decrypted, nil
+}
+
+func encryptedDataBagValueFrom(v interface{}) (encryptedDataBagValue, bool) {
+	var value encryptedDataBagValue
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return value, false
+	}
+	if _, ok := m["encrypted_data"]; !ok {
+		return value, false
+	}
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return value, false
+	}
+	return value, json.Unmarshal(raw, &value) == nil
+}
+
+func encryptDataBagValue(v interface{}, secret string, version int) (*encryptedDataBagValue, error) {
+	cipherName, err := dataBagEncryptionCipher(version)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := json.Marshal(map[string]interface{}{"json_wrapper": v})
+	if err != nil {
+		return nil, err
+	}
+
+	raw, key := dataBagSecretKey(secret)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	value := &encryptedDataBagValue{Version: version, Cipher: cipherName}
+	switch version {
+	case 1, 2:
+		iv := make([]byte, aes.BlockSize)
+		if _, err := rand.Read(iv); err != nil {
+			return nil, err
+		}
+		padded := pkcs7Pad(plain, aes.BlockSize)
+		ciphertext := make([]byte, len(padded))
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+
+		value.IV = base64.StdEncoding.EncodeToString(iv)
+		value.EncryptedData = base64.StdEncoding.EncodeToString(ciphertext)
+		if version == 2 {
+			value.HMAC = dataBagHMAC(raw, value.EncryptedData)
+		}
+	case 3:
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		iv := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(iv); err != nil {
+			return nil, err
+		}
+		sealed := gcm.Seal(nil, iv, plain, nil)
+		tagStart := len(sealed) - gcm.Overhead()
+
+		value.IV = base64.StdEncoding.EncodeToString(iv)
+		value.EncryptedData = base64.StdEncoding.EncodeToString(sealed[:tagStart])
+		value.AuthTag = base64.StdEncoding.EncodeToString(sealed[tagStart:])
+	}
+	return value, nil
+}
+
+func decryptDataBagValue(value encryptedDataBagValue, secret string) (interface{}, error) {
+	if _, err := dataBagEncryptionCipher(value.Version); err != nil {
+		return nil, err
+	}
+
+	raw, key := dataBagSecretKey(secret)
+	iv, err := decodeDataBagBase64(value.IV)
+	if err != nil {
+		return nil, fmt.Errorf("iv: %s", err)
+	}
+	ciphertext, err := decodeDataBagBase64(value.EncryptedData)
+	if err != nil {
+		return nil, fmt.Errorf("encrypted_data: %s", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var plain []byte
+	switch value.Version {
+	case 1, 2:
+		if value.Version == 2 {
+			expected, err := decodeDataBagBase64(dataBagHMAC(raw, value.EncryptedData))
+			if err != nil {
+				return nil, err
+			}
+			actual, err := decodeDataBagBase64(value.HMAC)
+			if err != nil || !hmac.Equal(expected, actual) {
+				return nil, errors.New("HMAC does not match; the secret is wrong or the data was tampered with")
+			}
+		}
+		if len(iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+			return nil, errors.New("malformed ciphertext")
+		}
+		padded := make([]byte, len(ciphertext))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(padded, ciphertext)
+		if plain, err = pkcs7Unpad(padded, aes.BlockSize); err != nil {
+			return nil, errors.New("decryption failed; the secret is probably wrong")
+		}
+	case 3:
+		tag, err := decodeDataBagBase64(value.AuthTag)
+		if err != nil {
+			return nil, fmt.Errorf("auth_tag: %s", err)
+		}
+		gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+		if err != nil {
+			return nil, err
+		}
+		if len(tag) != gcm.Overhead() {
+			return nil, errors.New("malformed auth_tag")
+		}
+		if plain, err = gcm.Open(nil, iv, append(ciphertext, tag...), nil); err != nil {
+			return nil, errors.New("authentication failed; the secret is wrong or the data was tampered with")
+		}
+	}
+
+	var wrapper struct {
+		JSONWrapper interface{} `json:"json_wrapper"`
+	}
+	if err := json.Unmarshal(plain, &wrapper); err != nil {
+		return nil, fmt.Errorf("decrypted data is not valid JSON; the secret is probably wrong: %s", err)
+	}
+	return wrapper.JSONWrapper, nil
+}
+
+// dataBagHMAC is the version 2 HMAC, keyed with the secret itself rather
+// than the derived key, over the base64 ciphertext exactly as stored.
+func dataBagHMAC(secret []byte, encryptedData string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(encryptedData))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// decodeDataBagBase64 accepts the line-wrapped base64 Ruby writes as well
+// as the unwrapped form.
+func decodeDataBagBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
+
+func pkcs7Pad(data []byte, blockSize int) []byte {
+	n := blockSize - len(data)%blockSize
+	padded := make([]byte, len(data), len(data)+n)
+	copy(padded, data)
+	for i := 0; i < n; i++ {
+		padded = append(padded, byte(n))
+	}
+	return padded
+}
+
+func pkcs7Unpad(data []byte, blockSize int) ([]byte, error) {
+	if len(data) == 0 || len(data)%blockSize != 0 {
+		return nil, errors.New("invalid padding")
+	}
+	n := int(data[len(data)-1])
+	if n == 0 || n > blockSize || n > len(data) {
+		return nil, errors.New("invalid padding")
+	}
+	for _, b := range data[len(data)-n:] {
+		if int(b) != n {
+			return nil, errors.New("invalid padding")
+		}
+	}
+	return data[:len(data)-n], nil
+}
@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDataBagEncryption(t *testing.T) {
+	item := map[string]interface{}{
+		"id":       "db",
+		"password": "hunter2",
+		"hosts":    []interface{}{"a", "b"},
+		"port":     float64(5432),
+	}
+
+	for _, version := range dataBagEncryptionVersions {
+		encrypted, err := encryptDataBagItem(item, "secret\n", version)
+		if err != nil {
+			t.Fatalf("v%d: err: %s", version, err)
+		}
+		if encrypted["id"] != "db" {
+			t.Fatalf("v%d: expected id to be left in plain text, got %v", version, encrypted["id"])
+		}
+		value, ok := encrypted["password"].(*encryptedDataBagValue)
+		if !ok || value.Version != version {
+			t.Fatalf("v%d: expected password to be encrypted, got %#v", version, encrypted["password"])
+		}
+
+		// Round-trip through JSON, as the item would be stored.
+		var stored map[string]interface{}
+		if err := json.Unmarshal([]byte(mustJSON(encrypted)), &stored); err != nil {
+			t.Fatalf("v%d: err: %s", version, err)
+		}
+
+		decrypted, err := decryptDataBagItem(stored, "secret")
+		if err != nil {
+			t.Fatalf("v%d: err: %s", version, err)
+		}
+		if !reflect.DeepEqual(decrypted, item) {
+			t.Fatalf("v%d: expected %#v, got %#v", version, item, decrypted)
+		}
+
+		if _, err := decryptDataBagItem(stored, "wrong"); err == nil {
+			t.Fatalf("v%d: expected decrypting with the wrong secret to fail", version)
+		}
+	}
+
+	if _, err := encryptDataBagItem(item, "secret", 4); err == nil {
+		t.Fatal("expected an unsupported version to be rejected")
+	}
+	if _, errs := validateDataBagEncryptionVersion(0, "version"); len(errs) == 0 {
+		t.Fatal("expected an unsupported version to fail validation")
+	}
+}
+
+func TestDataBagEncryption_plainFields(t *testing.T) {
+	item := map[string]interface{}{"id": "db", "password": "hunter2"}
+	decrypted, err := decryptDataBagItem(item, "secret")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(decrypted, item) {
+		t.Fatalf("expected unencrypted fields to be returned as they are, got %#v", decrypted)
+	}
+}
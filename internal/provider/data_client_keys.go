@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataChefClientKeys() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadClientKeys,
+
+		Schema: map[string]*schema.Schema{
+			"client": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"keys": keyListSchema(),
+		},
+	}
+}
+
+func ReadClientKeys(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	name := d.Get("client").(string)
+	keys, err := listAccessKeys(ctx, client.Client, "clients/"+name)
+	if isChefNotFound(err) {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Client not found",
+				Detail:        fmt.Sprintf("No client named %s exists in the organization", name),
+				AttributePath: cty.GetAttrPath("client"),
+			},
+		}
+	}
+	if err != nil {
+		return chefErrToDiag("Error listing client keys", err, cty.GetAttrPath("client"))
+	}
+
+	d.SetId(name)
+	d.Set("keys", flattenKeyItems(keys))
+	return nil
+}
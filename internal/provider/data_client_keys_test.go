@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestReadClientKeys(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/organizations/test/clients/web1/keys" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"name": "default", "uri": "https://chef/organizations/test/clients/web1/keys/default", "expired": true}]`))
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefClientKeys().Schema, map[string]interface{}{
+		"client": "web1",
+	})
+	if diags := ReadClientKeys(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "web1" || d.Get("keys.#").(int) != 1 || !d.Get("keys.0.expired").(bool) {
+		t.Fatalf("unexpected keys %v", d.Get("keys"))
+	}
+}
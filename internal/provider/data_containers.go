@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// defaultContainers are the containers every Chef organization is created
+// with. They are reported when the server returns an empty listing.
+var defaultContainers = []string{
+	"clients",
+	"containers",
+	"cookbook_artifacts",
+	"cookbooks",
+	"data",
+	"environments",
+	"groups",
+	"nodes",
+	"policies",
+	"policy_groups",
+	"roles",
+	"sandboxes",
+}
+
+func dataChefContainers() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadContainers,
+
+		Schema: map[string]*schema.Schema{
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"paths": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func ReadContainers(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	list, err := client.Containers.List()
+	if err != nil {
+		return chefErrToDiag("Error listing containers", err, nil)
+	}
+
+	names := make([]string, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		names = append(names, defaultContainers...)
+	}
+	sort.Strings(names)
+
+	paths := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		container, err := client.Containers.Get(name)
+		if err != nil {
+			if isChefNotFound(err) {
+				paths[name] = name
+				continue
+			}
+			return chefErrToDiag("Error reading container", err, nil)
+		}
+		paths[name] = container.ContainerPath
+	}
+
+	d.SetId(client.BaseURL.String())
+	d.Set("names", names)
+	d.Set("paths", paths)
+
+	return nil
+}
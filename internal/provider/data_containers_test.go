@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestReadContainers_defaults(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/organizations/test/containers" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte("{}"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefContainers().Schema, map[string]interface{}{})
+	if diags := ReadContainers(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	names := make([]string, 0)
+	for _, v := range d.Get("names").([]interface{}) {
+		names = append(names, v.(string))
+	}
+	if !reflect.DeepEqual(names, defaultContainers) {
+		t.Fatalf("wrong names; expected %#v, got %#v", defaultContainers, names)
+	}
+	if got := d.Get("paths.nodes"); got != "nodes" {
+		t.Fatalf("wrong path for nodes: %v", got)
+	}
+}
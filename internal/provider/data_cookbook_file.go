@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func dataChefCookbookFile() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadCookbookFile,
+
+		Schema: map[string]*schema.Schema{
+			"cookbook": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"version": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "_latest",
+			},
+			"path": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"resolved_version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"checksum": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"content": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func ReadCookbookFile(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	name := d.Get("cookbook").(string)
+	path := d.Get("path").(string)
+
+	cookbook, err := client.Cookbooks.GetVersion(name, d.Get("version").(string))
+	if err != nil {
+		return chefErrToDiag("Error reading cookbook manifest", err, cty.GetAttrPath("version"))
+	}
+
+	item, ok := findCookbookItem(&cookbook, path)
+	if !ok {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Cookbook file not found",
+				Detail:        fmt.Sprintf("%s@%s has no file at %s", name, cookbook.Version, path),
+				AttributePath: cty.GetAttrPath("path"),
+			},
+		}
+	}
+
+	content, err := downloadCookbookFile(ctx, client.Client, item)
+	if err != nil {
+		return chefErrToDiag("Error downloading cookbook file", err, cty.GetAttrPath("path"))
+	}
+
+	d.SetId(name + "@" + cookbook.Version + "/" + path)
+	d.Set("resolved_version", cookbook.Version)
+	d.Set("checksum", item.Checksum)
+	d.Set("content", string(content))
+
+	return nil
+}
+
+func findCookbookItem(cookbook *chefc.Cookbook, path string) (chefc.CookbookItem, bool) {
+	for _, segment := range cookbookSegments(cookbook) {
+		for _, item := range segment.items {
+			if item.Path == path {
+				return item, true
+			}
+		}
+	}
+	return chefc.CookbookItem{}, false
+}
+
+// downloadCookbookFile fetches a file from the location given in the
+// cookbook manifest and checks it against the manifest's checksum, so that
+// a truncated or corrupted download from the file store surfaces as an
+// error instead of as bad content. The URLs are pre-signed by the server,
+// so the request is sent unsigned through the client's transport.
+func downloadCookbookFile(ctx context.Context, client *chefc.Client, item chefc.CookbookItem) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", item.Url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := chefHTTPClient(client).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: %s", item.Path, res.Status)
+	}
+
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %s", item.Path, err)
+	}
+
+	if err := verifyCookbookFileChecksum(item, content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// verifyCookbookFileChecksum compares content with the MD5 checksum Chef
+// Server records for every cookbook file.
+func verifyCookbookFileChecksum(item chefc.CookbookItem, content []byte) error {
+	sum := md5.Sum(content)
+	actual := hex.EncodeToString(sum[:])
+	if actual != item.Checksum {
+		return fmt.Errorf("%s failed checksum verification: expected %s, got %s (%d bytes downloaded)",
+			item.Path, item.Checksum, actual, len(content))
+	}
+	return nil
+}
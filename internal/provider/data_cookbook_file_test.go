@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testCookbookFileClient(t *testing.T, served string) *chefClient {
+	return testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/organizations/test/cookbooks/web/_latest":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{
+				"cookbook_name": "web",
+				"version": "1.0.0",
+				"recipes": [{
+					"name": "default.rb",
+					"path": "recipes/default.rb",
+					"checksum": "5eb63bbbe01eeed093cb22bb8f5acdc3",
+					"url": "http://%s/bookshelf/5eb63bbbe01eeed093cb22bb8f5acdc3"
+				}]
+			}`, r.Host)
+		case "/bookshelf/5eb63bbbe01eeed093cb22bb8f5acdc3":
+			w.Write([]byte(served))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func TestReadCookbookFile(t *testing.T) {
+	c := testCookbookFileClient(t, "hello world")
+
+	d := schema.TestResourceDataRaw(t, dataChefCookbookFile().Schema, map[string]interface{}{
+		"cookbook": "web",
+		"path":     "recipes/default.rb",
+	})
+	if diags := ReadCookbookFile(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if got := d.Get("content").(string); got != "hello world" {
+		t.Fatalf("unexpected content %q", got)
+	}
+	if d.Id() != "web@1.0.0/recipes/default.rb" {
+		t.Fatalf("unexpected id %q", d.Id())
+	}
+}
+
+func TestReadCookbookFile_checksumMismatch(t *testing.T) {
+	c := testCookbookFileClient(t, "hello wor")
+
+	d := schema.TestResourceDataRaw(t, dataChefCookbookFile().Schema, map[string]interface{}{
+		"cookbook": "web",
+		"path":     "recipes/default.rb",
+	})
+	diags := ReadCookbookFile(context.Background(), d, c)
+	if !diags.HasError() {
+		t.Fatal("expected a checksum verification error")
+	}
+	if detail := diags[0].Detail; !strings.Contains(detail, "recipes/default.rb") ||
+		!strings.Contains(detail, "expected 5eb63bbbe01eeed093cb22bb8f5acdc3") {
+		t.Fatalf("error does not identify the file and checksums: %s", detail)
+	}
+}
@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func dataChefCookbookManifest() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadCookbookManifest,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"version": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "_latest",
+			},
+			"resolved_version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"file": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"segment": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"specificity": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"checksum": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"checksums": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func ReadCookbookManifest(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	name := d.Get("name").(string)
+	version := d.Get("version").(string)
+
+	cookbook, err := client.Cookbooks.GetVersion(name, version)
+	if err != nil {
+		return chefErrToDiag("Error reading cookbook manifest", err, cty.GetAttrPath("version"))
+	}
+
+	files, checksums := flattenCookbookManifest(&cookbook)
+
+	d.SetId(name + "@" + cookbook.Version)
+	d.Set("resolved_version", cookbook.Version)
+	d.Set("file", files)
+	d.Set("checksums", checksums)
+
+	return nil
+}
+
+// flattenCookbookManifest walks every segment of a cookbook version and
+// returns the files as schema blocks along with a path to checksum map.
+func flattenCookbookManifest(cookbook *chefc.Cookbook) ([]interface{}, map[string]interface{}) {
+	files := make([]interface{}, 0)
+	checksums := make(map[string]interface{})
+	for _, segment := range cookbookSegments(cookbook) {
+		for _, item := range segment.items {
+			files = append(files, map[string]interface{}{
+				"segment":     segment.name,
+				"name":        item.Name,
+				"path":        item.Path,
+				"specificity": item.Specificity,
+				"checksum":    item.Checksum,
+			})
+			checksums[item.Path] = item.Checksum
+		}
+	}
+
+	return files, checksums
+}
+
+type cookbookSegment struct {
+	name  string
+	items []chefc.CookbookItem
+}
+
+func cookbookSegments(cookbook *chefc.Cookbook) []cookbookSegment {
+	return []cookbookSegment{
+		{"attributes", cookbook.Attributes},
+		{"definitions", cookbook.Definitions},
+		{"files", cookbook.Files},
+		{"libraries", cookbook.Libraries},
+		{"providers", cookbook.Providers},
+		{"recipes", cookbook.Recipes},
+		{"resources", cookbook.Resources},
+		{"root_files", cookbook.RootFiles},
+		{"templates", cookbook.Templates},
+	}
+}
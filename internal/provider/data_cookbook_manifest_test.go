@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestFlattenCookbookManifest(t *testing.T) {
+	cookbook := &chefc.Cookbook{
+		Recipes: []chefc.CookbookItem{
+			{Name: "default.rb", Path: "recipes/default.rb", Specificity: "default", Checksum: "aaa"},
+		},
+		RootFiles: []chefc.CookbookItem{
+			{Name: "metadata.rb", Path: "metadata.rb", Specificity: "default", Checksum: "bbb"},
+		},
+	}
+
+	files, checksums := flattenCookbookManifest(cookbook)
+
+	expectedFiles := []interface{}{
+		map[string]interface{}{
+			"segment":     "recipes",
+			"name":        "default.rb",
+			"path":        "recipes/default.rb",
+			"specificity": "default",
+			"checksum":    "aaa",
+		},
+		map[string]interface{}{
+			"segment":     "root_files",
+			"name":        "metadata.rb",
+			"path":        "metadata.rb",
+			"specificity": "default",
+			"checksum":    "bbb",
+		},
+	}
+	if !reflect.DeepEqual(files, expectedFiles) {
+		t.Fatalf("wrong files; expected %#v, got %#v", expectedFiles, files)
+	}
+
+	expectedChecksums := map[string]interface{}{
+		"recipes/default.rb": "aaa",
+		"metadata.rb":        "bbb",
+	}
+	if !reflect.DeepEqual(checksums, expectedChecksums) {
+		t.Fatalf("wrong checksums; expected %#v, got %#v", expectedChecksums, checksums)
+	}
+}
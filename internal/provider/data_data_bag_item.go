@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// defaultDataBagItemMaxSize is the largest data bag item read unless the
+// configuration allows more.
+const defaultDataBagItemMaxSize = 64 << 20
+
+// dataChefDataBagItem reads a data bag item by streaming its raw JSON,
+// either into state or straight to a file, rather than decoding it through
+// go-chef, which holds a second copy of the body while decoding it.
+func dataChefDataBagItem() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadDataBagItemData,
+
+		Schema: map[string]*schema.Schema{
+			"data_bag_name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"output_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "If set, the item is written to this file and content_json is left empty.",
+			},
+			"max_size": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     defaultDataBagItemMaxSize,
+				Description: "Largest item, in bytes, that will be read. Larger items fail rather than being read into memory.",
+			},
+			"content_json": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"size": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"sha256": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func ReadDataBagItemData(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	bag := d.Get("data_bag_name").(string)
+	name := d.Get("name").(string)
+	maxSize := int64(d.Get("max_size").(int))
+
+	var size int64
+	var sum string
+	var err error
+	if path, ok := d.GetOk("output_path"); ok {
+		size, sum, err = writeDataBagItemFile(ctx, client.Client, bag, name, maxSize, path.(string))
+		d.Set("content_json", "")
+	} else {
+		var buf bytes.Buffer
+		size, sum, err = streamDataBagItem(ctx, client.Client, bag, name, maxSize, &buf)
+		if err == nil && !json.Valid(buf.Bytes()) {
+			err = fmt.Errorf("data bag item %s/%s is not valid JSON", bag, name)
+		}
+		d.Set("content_json", buf.String())
+	}
+	if err != nil {
+		return chefErrToDiag("Error reading data bag item", err, cty.GetAttrPath("name"))
+	}
+
+	d.SetId(bag + "/" + name)
+	d.Set("size", size)
+	d.Set("sha256", sum)
+	return nil
+}
+
+// streamDataBagItem copies a data bag item's JSON to w as it arrives,
+// returning its size and SHA-256. Items larger than maxSize fail, before
+// reading anything when the server sends a Content-Length.
+func streamDataBagItem(ctx context.Context, client *chefc.Client, bag, name string, maxSize int64, w io.Writer) (int64, string, error) {
+	req, err := newRequestWithContext(withStreamedBody(ctx), client, "GET", "data/"+bag+"/"+name, nil)
+	if err != nil {
+		return 0, "", err
+	}
+
+	res, err := chefHTTPClient(client).Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+
+	if err := chefc.CheckResponse(res); err != nil {
+		return 0, "", err
+	}
+	if res.ContentLength > maxSize {
+		return 0, "", fmt.Errorf("data bag item %s/%s is %d bytes, larger than max_size of %d", bag, name, res.ContentLength, maxSize)
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(w, hash), io.LimitReader(res.Body, maxSize+1))
+	if err != nil {
+		return n, "", err
+	}
+	if n > maxSize {
+		return n, "", fmt.Errorf("data bag item %s/%s is larger than max_size of %d bytes", bag, name, maxSize)
+	}
+	return n, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writeDataBagItemFile streams a data bag item to path, through a temporary
+// file so that a failed or oversized read never leaves a partial item
+// behind.
+func writeDataBagItemFile(ctx context.Context, client *chefc.Client, bag, name string, maxSize int64, path string) (int64, string, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return 0, "", err
+	}
+	defer os.Remove(f.Name())
+
+	size, sum, err := streamDataBagItem(ctx, client, bag, name, maxSize, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return 0, "", err
+	}
+	return size, sum, nil
+}
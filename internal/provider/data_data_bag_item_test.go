@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestReadDataBagItemData(t *testing.T) {
+	item := `{"id":"certs","blob":"` + strings.Repeat("x", 4096) + `"}`
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/organizations/test/data/secrets/certs" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(item))
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefDataBagItem().Schema, map[string]interface{}{
+		"data_bag_name": "secrets",
+		"name":          "certs",
+	})
+	if diags := ReadDataBagItemData(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Get("content_json").(string) != item || d.Get("size").(int) != len(item) {
+		t.Fatalf("unexpected content of %d bytes", d.Get("size"))
+	}
+
+	path := filepath.Join(t.TempDir(), "certs.json")
+	d = schema.TestResourceDataRaw(t, dataChefDataBagItem().Schema, map[string]interface{}{
+		"data_bag_name": "secrets",
+		"name":          "certs",
+		"output_path":   path,
+	})
+	if diags := ReadDataBagItemData(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if written, _ := os.ReadFile(path); string(written) != item {
+		t.Fatalf("expected the item to be written to %s, got %d bytes", path, len(written))
+	}
+	if d.Get("content_json").(string) != "" {
+		t.Fatal("expected content_json to be empty when writing to a file")
+	}
+
+	d = schema.TestResourceDataRaw(t, dataChefDataBagItem().Schema, map[string]interface{}{
+		"data_bag_name": "secrets",
+		"name":          "certs",
+		"max_size":      1024,
+	})
+	diags := ReadDataBagItemData(context.Background(), d, c)
+	if !diags.HasError() || !strings.Contains(diags[0].Detail, "larger than max_size") {
+		t.Fatalf("expected an oversized item to fail, got %v", diags)
+	}
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// dataChefEffectivePermissions reports what the client the provider
+// authenticates as may do to an object, taking both its direct ACL entries
+// and its group memberships, including nested groups, into account.
+func dataChefEffectivePermissions() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"object_type": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validateACLObjectType,
+		},
+		"object_name": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"principal": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"groups": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "Groups the principal belongs to, directly or through nested groups.",
+		},
+	}
+	for _, permission := range aclPermissions {
+		s[permission] = &schema.Schema{
+			Type:     schema.TypeBool,
+			Computed: true,
+		}
+	}
+
+	return &schema.Resource{
+		ReadContext: ReadEffectivePermissions,
+		Schema:      s,
+	}
+}
+
+func ReadEffectivePermissions(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	container := aclObjectContainers[d.Get("object_type").(string)]
+	name := d.Get("object_name").(string)
+	principal := client.Auth.ClientName
+
+	acl, err := client.ACLs.Get(container, name)
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Error reading object ACL",
+				Detail:        fmt.Sprintf("%s/%s: %s", container, name, err),
+				AttributePath: cty.GetAttrPath("object_name"),
+			},
+		}
+	}
+
+	groups, err := principalGroups(client.Client, principal)
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  "Error reading group memberships",
+				Detail:   fmt.Sprintf("Listing the groups %s belongs to requires read access to every group: %s", principal, err),
+			},
+		}
+	}
+
+	d.SetId(principal + "/" + container + "/" + name)
+	d.Set("principal", principal)
+	d.Set("groups", groups)
+	for permission, allowed := range effectivePermissions(acl, principal, groups) {
+		d.Set(permission, allowed)
+	}
+	return nil
+}
+
+// effectivePermissions reports, for each permission, whether the principal
+// is granted it directly or through one of its groups.
+func effectivePermissions(acl chefc.ACL, principal string, groups []string) map[string]bool {
+	member := make(map[string]bool)
+	for _, g := range groups {
+		member[g] = true
+	}
+
+	result := make(map[string]bool)
+	for _, permission := range aclPermissions {
+		allowed := false
+		for _, actor := range acl[permission].Actors {
+			allowed = allowed || actor == principal
+		}
+		for _, g := range acl[permission].Groups {
+			allowed = allowed || member[g]
+		}
+		result[permission] = allowed
+	}
+	return result
+}
+
+// principalGroups returns, sorted, every group the principal belongs to,
+// following groups nested within other groups.
+func principalGroups(client *chefc.Client, principal string) ([]string, error) {
+	list, err := client.Groups.List()
+	if err != nil {
+		return nil, err
+	}
+
+	// parents maps each group to the groups it is nested within.
+	parents := make(map[string][]string)
+	var pending []string
+	for name := range list {
+		group, err := client.Groups.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		for _, nested := range group.Groups {
+			parents[nested] = append(parents[nested], name)
+		}
+		if containsString(group.Actors, principal) || containsString(group.Users, principal) || containsString(group.Clients, principal) {
+			pending = append(pending, name)
+		}
+	}
+
+	member := make(map[string]bool)
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if member[name] {
+			continue
+		}
+		member[name] = true
+		pending = append(pending, parents[name]...)
+	}
+
+	groups := make([]string, 0, len(member))
+	for name := range member {
+		groups = append(groups, name)
+	}
+	sort.Strings(groups)
+	return groups, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
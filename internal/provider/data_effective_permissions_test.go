@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestReadEffectivePermissions(t *testing.T) {
+	groups := map[string]chefc.Group{
+		"ci":      {Name: "ci", Clients: []string{"test"}},
+		"deploy":  {Name: "deploy", Groups: []string{"ci"}},
+		"admins":  {Name: "admins", Users: []string{"alice"}},
+		"clients": {Name: "clients", Clients: []string{"web1"}},
+	}
+	acl := chefc.ACL{
+		"read":   {Groups: chefc.ACLitem{"clients", "deploy"}},
+		"update": {Groups: chefc.ACLitem{"admins", "deploy"}},
+		"delete": {Groups: chefc.ACLitem{"admins"}},
+		"grant":  {Actors: chefc.ACLitem{"test"}},
+	}
+
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/organizations/test/roles/web/_acl":
+			json.NewEncoder(w).Encode(acl)
+		case r.URL.Path == "/organizations/test/groups":
+			list := make(map[string]string)
+			for name := range groups {
+				list[name] = "https://chef/organizations/test/groups/" + name
+			}
+			json.NewEncoder(w).Encode(list)
+		case strings.HasPrefix(r.URL.Path, "/organizations/test/groups/"):
+			json.NewEncoder(w).Encode(groups[strings.TrimPrefix(r.URL.Path, "/organizations/test/groups/")])
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefEffectivePermissions().Schema, map[string]interface{}{
+		"object_type": "role",
+		"object_name": "web",
+	})
+	if diags := ReadEffectivePermissions(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	want := map[string]bool{"create": false, "read": true, "update": true, "delete": false, "grant": true}
+	for permission, allowed := range want {
+		if got := d.Get(permission).(bool); got != allowed {
+			t.Errorf("expected %s to be %v, got %v", permission, allowed, got)
+		}
+	}
+	if got := d.Get("groups").([]interface{}); len(got) != 2 || got[0] != "ci" || got[1] != "deploy" {
+		t.Fatalf("expected membership of ci and, through it, deploy, got %v", got)
+	}
+}
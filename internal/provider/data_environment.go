@@ -1,12 +1,17 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func dataChefEnvironment() *schema.Resource {
 	return &schema.Resource{
-		ReadContext: ReadEnvironment,
+		ReadContext: dataChefEnvironmentRead,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -33,6 +38,31 @@ func dataChefEnvironment() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"last_modified": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 		},
 	}
 }
+
+func dataChefEnvironmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := ReadEnvironment(ctx, d, meta); diags.HasError() {
+		return diags
+	}
+	if d.Id() == "" {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Environment not found",
+				Detail:        fmt.Sprintf("No environment named %s exists in the organization", d.Get("name")),
+				AttributePath: cty.GetAttrPath("name"),
+			},
+		}
+	}
+	return nil
+}
@@ -1,13 +1,16 @@
 package provider
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"reflect"
 	"testing"
 
 	chefc "github.com/go-chef/chef"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -80,3 +83,33 @@ data "chef_environment" "test" {
 	name = chef_environment.test.id
 }
 `
+
+func TestDataChefEnvironmentRead(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/organizations/test/environments/_default" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":["Cannot load environment"]}`))
+			return
+		}
+		w.Write([]byte(`{"name":"_default","description":"The default Chef environment","json_class":"Chef::Environment","chef_type":"environment","cookbook_versions":{}}`))
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefEnvironment().Schema, map[string]interface{}{
+		"name": "_default",
+	})
+	if diags := dataChefEnvironmentRead(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "_default" || d.Get("description").(string) != "The default Chef environment" {
+		t.Fatalf("unexpected state id=%q description=%q", d.Id(), d.Get("description"))
+	}
+
+	d = schema.TestResourceDataRaw(t, dataChefEnvironment().Schema, map[string]interface{}{
+		"name": "staging",
+	})
+	diags := dataChefEnvironmentRead(context.Background(), d, c)
+	if !diags.HasError() || diags[0].Summary != "Environment not found" {
+		t.Fatalf("expected an environment not found error, got %v", diags)
+	}
+}
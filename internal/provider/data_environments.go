@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// defaultEnvironment is the environment every Chef organization has, and
+// which nodes are placed in when they don't name one.
+const defaultEnvironment = "_default"
+
+func dataChefEnvironments() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadEnvironments,
+
+		Schema: map[string]*schema.Schema{
+			"include_default": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether to list the `_default` environment, which every organization has.",
+			},
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func ReadEnvironments(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	list, err := client.Environments.List()
+	if err != nil {
+		return chefErrToDiag("Error listing environments", err, nil)
+	}
+
+	includeDefault := d.Get("include_default").(bool)
+	names := make([]string, 0)
+	if list != nil {
+		for name := range *list {
+			if name != defaultEnvironment {
+				names = append(names, name)
+			}
+		}
+	}
+	// _default can't be deleted, so it is listed even if the server
+	// leaves it out.
+	if includeDefault {
+		names = append(names, defaultEnvironment)
+	}
+	sort.Strings(names)
+
+	d.SetId(client.BaseURL.String())
+	d.Set("names", names)
+
+	return nil
+}
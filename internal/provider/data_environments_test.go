@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestReadEnvironments(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/organizations/test/environments" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"prod": "https://chef/organizations/test/environments/prod",
+			"_default": "https://chef/organizations/test/environments/_default",
+			"dev": "https://chef/organizations/test/environments/dev"
+		}`))
+	})
+
+	cases := []struct {
+		includeDefault bool
+		expected       []string
+	}{
+		{true, []string{"_default", "dev", "prod"}},
+		{false, []string{"dev", "prod"}},
+	}
+	for _, tc := range cases {
+		d := schema.TestResourceDataRaw(t, dataChefEnvironments().Schema, map[string]interface{}{
+			"include_default": tc.includeDefault,
+		})
+		if diags := ReadEnvironments(context.Background(), d, c); diags.HasError() {
+			t.Fatalf("err: %v", diags)
+		}
+
+		names := make([]string, 0)
+		for _, v := range d.Get("names").([]interface{}) {
+			names = append(names, v.(string))
+		}
+		if !reflect.DeepEqual(names, tc.expected) {
+			t.Errorf("include_default=%v: expected %v, got %v", tc.includeDefault, tc.expected, names)
+		}
+	}
+}
@@ -1,18 +1,67 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// nodeAttributePrecedence lists the node attribute levels from lowest to
+// highest precedence, the order chef-client merges them in.
+var nodeAttributePrecedence = []string{"default", "normal", "override", "automatic"}
+
 func dataChefNode() *schema.Resource {
 	return &schema.Resource{
-		ReadContext: ReadNode,
+		ReadContext: dataChefNodeRead,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
 			},
+			"attribute_levels": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				ConflictsWith: []string{"select"},
+				Description:   "Attribute precedence levels to read, out of `default`, `normal`, `override` and `automatic`. Levels not listed are returned empty. Defaults to all levels.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateNodeAttributeLevel,
+				},
+			},
+			"select": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				ConflictsWith: []string{"attribute_levels"},
+				Description:   "Dot-separated attribute paths, such as `fqdn` or `cpu.total`, to fetch with a partial search instead of reading the whole node. When set, only `selected_attributes`, `selected_attributes_json`, `environment_name` and `run_list` are populated.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"selected_attributes": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The values of the `select` paths, keyed by path. Strings are returned as-is and any other value as JSON.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"selected_attributes_json": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The values of the `select` paths as a JSON object keyed by path.",
+			},
+			"effective_attributes_json": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The selected levels merged the way chef-client does, in increasing precedence: default, normal, override, automatic. Nested objects are merged key by key; any other value, including arrays, is replaced by the higher level.",
+			},
 			"environment_name": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -33,6 +82,14 @@ func dataChefNode() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"last_modified": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"run_list": {
 				Type:     schema.TypeList,
 				Computed: true,
@@ -44,3 +101,201 @@ func dataChefNode() *schema.Resource {
 		},
 	}
 }
+
+func dataChefNodeRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if len(d.Get("select").([]interface{})) > 0 {
+		return dataChefNodeSelect(ctx, d, meta)
+	}
+
+	if diags := ReadNode(ctx, d, meta); diags.HasError() {
+		return diags
+	}
+	if d.Id() == "" {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Node not found",
+				Detail:        fmt.Sprintf("No node named %s exists in the organization", d.Get("name")),
+				AttributePath: cty.GetAttrPath("name"),
+			},
+		}
+	}
+
+	selected := make(map[string]bool)
+	for _, v := range d.Get("attribute_levels").([]interface{}) {
+		selected[v.(string)] = true
+	}
+	if len(selected) == 0 {
+		for _, level := range nodeAttributePrecedence {
+			selected[level] = true
+		}
+	}
+
+	var levels []map[string]interface{}
+	for _, level := range nodeAttributePrecedence {
+		key := level + "_attributes_json"
+		if !selected[level] {
+			d.Set(key, "")
+			continue
+		}
+
+		var attrs map[string]interface{}
+		if err := json.Unmarshal([]byte(d.Get(key).(string)), &attrs); err != nil {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Error parsing node attributes",
+					Detail:        fmt.Sprint(err),
+					AttributePath: cty.GetAttrPath(key),
+				},
+			}
+		}
+		levels = append(levels, attrs)
+	}
+
+	effectiveJson, err := json.Marshal(mergeNodeAttributes(levels...))
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  "Error converting effective attributes into JSON",
+				Detail:   fmt.Sprint(err),
+			},
+		}
+	}
+	d.Set("effective_attributes_json", string(effectiveJson))
+
+	return nil
+}
+
+// dataChefNodeSelect reads only the selected attribute paths of a node
+// through partial search, so that large nodes don't have to be transferred
+// in full to read a handful of values.
+func dataChefNodeSelect(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	name := d.Get("name").(string)
+
+	var paths []string
+	params := map[string]interface{}{
+		"name":             []string{"name"},
+		"chef_environment": []string{"chef_environment"},
+		"run_list":         []string{"run_list"},
+	}
+	for _, v := range d.Get("select").([]interface{}) {
+		path := v.(string)
+		paths = append(paths, path)
+		params["selected:"+path] = strings.Split(path, ".")
+	}
+
+	query, err := client.Search.NewQuery("node", "name:"+escapeSearchValue(name))
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  "Error creating search query",
+				Detail:   fmt.Sprint(err),
+			},
+		}
+	}
+	// go-chef puts the query into the URL as it is.
+	query.Query = url.QueryEscape(query.Query)
+
+	res, err := query.DoPartial(client.Client, params)
+	if err != nil {
+		return chefErrToDiag("Error reading node attributes", err, cty.GetAttrPath("select"))
+	}
+
+	// Search matches terms rather than whole values, so only a row for
+	// the node itself is used.
+	var data map[string]interface{}
+	for _, r := range res.Rows {
+		row, _ := r.(map[string]interface{})
+		if row, ok := row["data"].(map[string]interface{}); ok && row["name"] == name {
+			data = row
+			break
+		}
+	}
+	if data == nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Node not found",
+				Detail:        fmt.Sprintf("No node named %s was returned by search", name),
+				AttributePath: cty.GetAttrPath("name"),
+			},
+		}
+	}
+
+	selected := make(map[string]interface{})
+	selectedStrings := make(map[string]interface{})
+	for _, path := range paths {
+		value := data["selected:"+path]
+		selected[path] = value
+		switch t := value.(type) {
+		case string:
+			selectedStrings[path] = t
+		default:
+			valueJson, _ := json.Marshal(t)
+			selectedStrings[path] = string(valueJson)
+		}
+	}
+
+	selectedJson, err := json.Marshal(selected)
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  "Error converting selected attributes into JSON",
+				Detail:   fmt.Sprint(err),
+			},
+		}
+	}
+
+	d.SetId(name)
+	d.Set("environment_name", data["chef_environment"])
+	d.Set("run_list", data["run_list"])
+	d.Set("selected_attributes", selectedStrings)
+	d.Set("selected_attributes_json", string(selectedJson))
+
+	return nil
+}
+
+func validateNodeAttributeLevel(val interface{}, key string) (warns []string, errs []error) {
+	level := val.(string)
+	for _, l := range nodeAttributePrecedence {
+		if level == l {
+			return
+		}
+	}
+	errs = append(errs, fmt.Errorf("%s must be one of %v, got %q", key, nodeAttributePrecedence, level))
+	return
+}
+
+// mergeNodeAttributes deep merges attribute levels given in increasing order
+// of precedence, the way chef-client computes a node's merged attributes:
+// nested hashes are merged key by key, while any other value, including
+// arrays, from a higher level replaces the lower one outright.
+func mergeNodeAttributes(levels ...map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for _, level := range levels {
+		deepMergeAttributes(merged, level)
+	}
+	return merged
+}
+
+func deepMergeAttributes(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		switch {
+		case srcIsMap && dstIsMap:
+			deepMergeAttributes(dstMap, srcMap)
+		case srcIsMap:
+			copied := make(map[string]interface{})
+			deepMergeAttributes(copied, srcMap)
+			dst[k] = copied
+		default:
+			dst[k] = v
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataChefNodeRunListDiff previews the changes that would bring a node's
+// run_list to a desired one, without managing the node.
+func dataChefNodeRunListDiff() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadNodeRunListDiff,
+
+		Schema: map[string]*schema.Schema{
+			"node_name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"run_list": {
+				Type:     schema.TypeList,
+				Required: true,
+				Elem: &schema.Schema{
+					Type:      schema.TypeString,
+					StateFunc: runListEntryStateFunc,
+				},
+			},
+			"current_run_list": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"add": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Entries of run_list missing from the node, in run_list order.",
+			},
+			"remove": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Entries of the node's run_list not in run_list, in the node's order.",
+			},
+			"order_changed": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the entries the two run lists share are in a different order.",
+			},
+		},
+	}
+}
+
+func ReadNodeRunListDiff(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	name := d.Get("node_name").(string)
+
+	node, err := client.Nodes.Get(name)
+	if err != nil {
+		return chefErrToDiag("Error reading node", err, cty.GetAttrPath("node_name"))
+	}
+
+	var desired []string
+	for _, v := range d.Get("run_list").([]interface{}) {
+		desired = append(desired, runListEntryStateFunc(v))
+	}
+	current := make([]string, 0, len(node.RunList))
+	for _, entry := range node.RunList {
+		current = append(current, runListEntryStateFunc(entry))
+	}
+
+	add, remove, orderChanged := diffRunLists(current, desired)
+
+	d.SetId(name)
+	d.Set("current_run_list", current)
+	d.Set("add", add)
+	d.Set("remove", remove)
+	d.Set("order_changed", orderChanged)
+	return nil
+}
+
+// diffRunLists returns the entries of desired not in current and of current
+// not in desired, each in the order of the list it comes from, and whether
+// the entries both share appear in a different order.
+func diffRunLists(current, desired []string) (add, remove []string, orderChanged bool) {
+	inCurrent := make(map[string]bool)
+	for _, entry := range current {
+		inCurrent[entry] = true
+	}
+	inDesired := make(map[string]bool)
+	for _, entry := range desired {
+		inDesired[entry] = true
+	}
+
+	add, remove = make([]string, 0), make([]string, 0)
+	var keptCurrent, keptDesired []string
+	for _, entry := range desired {
+		if inCurrent[entry] {
+			keptDesired = append(keptDesired, entry)
+		} else {
+			add = append(add, entry)
+		}
+	}
+	for _, entry := range current {
+		if inDesired[entry] {
+			keptCurrent = append(keptCurrent, entry)
+		} else {
+			remove = append(remove, entry)
+		}
+	}
+	return add, remove, !reflect.DeepEqual(keptCurrent, keptDesired)
+}
@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDiffRunLists(t *testing.T) {
+	cases := []struct {
+		current, desired []string
+		want             string
+	}{
+		{
+			[]string{"recipe[base]", "role[web]", "recipe[old]"},
+			[]string{"recipe[base]", "recipe[new]", "role[web]"},
+			"[recipe[new]] [recipe[old]] false",
+		},
+		{
+			[]string{"recipe[a]", "recipe[b]"},
+			[]string{"recipe[b]", "recipe[a]"},
+			"[] [] true",
+		},
+		{
+			nil,
+			[]string{"recipe[a]"},
+			"[recipe[a]] [] false",
+		},
+	}
+
+	for _, tc := range cases {
+		add, remove, orderChanged := diffRunLists(tc.current, tc.desired)
+		if got := fmt.Sprint(add, remove, orderChanged); got != tc.want {
+			t.Errorf("diffRunLists(%v, %v) = %s, want %s", tc.current, tc.desired, got, tc.want)
+		}
+	}
+}
@@ -1,13 +1,17 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"reflect"
 	"testing"
 
 	chefc "github.com/go-chef/chef"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -96,3 +100,114 @@ data "chef_node" "test" {
 	name = chef_node.test.id
 }
 `
+
+func TestMergeNodeAttributes(t *testing.T) {
+	defaults := map[string]interface{}{
+		"app": map[string]interface{}{
+			"port":  80,
+			"hosts": []interface{}{"a", "b"},
+			"name":  "web",
+		},
+		"only_default": true,
+	}
+	normal := map[string]interface{}{
+		"app": map[string]interface{}{
+			"port": 8080,
+		},
+	}
+	override := map[string]interface{}{
+		"app": map[string]interface{}{
+			"hosts": []interface{}{"c"},
+		},
+	}
+	automatic := map[string]interface{}{
+		"fqdn": "web1.example.com",
+	}
+
+	merged := mergeNodeAttributes(defaults, normal, override, automatic)
+
+	expected := map[string]interface{}{
+		"app": map[string]interface{}{
+			"port":  8080,
+			"hosts": []interface{}{"c"},
+			"name":  "web",
+		},
+		"only_default": true,
+		"fqdn":         "web1.example.com",
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("wrong merged attributes; expected %#v, got %#v", expected, merged)
+	}
+
+	if defaults["app"].(map[string]interface{})["port"] != 80 {
+		t.Fatal("merging must not modify the input levels")
+	}
+}
+
+func TestDataChefNodeSelect(t *testing.T) {
+	var params map[string][]string
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/organizations/test/search/node" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if q := r.URL.Query().Get("q"); q != `name:web\-1` {
+			t.Errorf("unexpected query %q", q)
+		}
+		json.NewDecoder(r.Body).Decode(&params)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"total":2,"start":0,"rows":[{"url":"nodes/web-1-old","data":{
+			"name":"web-1-old",
+			"chef_environment":"staging",
+			"run_list":[],
+			"selected:fqdn":"web-1-old.example.com",
+			"selected:cpu.total":2
+		}},{"url":"nodes/web-1","data":{
+			"name":"web-1",
+			"chef_environment":"prod",
+			"run_list":["recipe[web]"],
+			"selected:fqdn":"web1.example.com",
+			"selected:cpu.total":4
+		}}]}`))
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefNode().Schema, map[string]interface{}{
+		"name":   "web-1",
+		"select": []interface{}{"fqdn", "cpu.total"},
+	})
+	if diags := dataChefNodeRead(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if got := params["selected:cpu.total"]; len(got) != 2 || got[0] != "cpu" || got[1] != "total" {
+		t.Fatalf("unexpected partial search path %v", got)
+	}
+	selected := d.Get("selected_attributes").(map[string]interface{})
+	if selected["fqdn"] != "web1.example.com" || selected["cpu.total"] != "4" {
+		t.Fatalf("unexpected selected attributes %v", selected)
+	}
+	if got := d.Get("selected_attributes_json").(string); got != `{"cpu.total":4,"fqdn":"web1.example.com"}` {
+		t.Fatalf("unexpected selected attributes JSON %s", got)
+	}
+	if got := d.Get("environment_name").(string); got != "prod" {
+		t.Fatalf("unexpected environment %q", got)
+	}
+	if d.Get("automatic_attributes_json").(string) != "" {
+		t.Fatal("full node attributes should not be read")
+	}
+}
+
+func TestDataChefNodeNotFound(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":["Cannot load node web1"]}`))
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefNode().Schema, map[string]interface{}{
+		"name": "web1",
+	})
+	diags := dataChefNodeRead(context.Background(), d, c)
+	if !diags.HasError() || diags[0].Summary != "Node not found" {
+		t.Fatalf("expected a node not found error, got %v", diags)
+	}
+}
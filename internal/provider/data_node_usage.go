@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// nodeUsageUnlimited is reported as node_limit when the server's license
+// does not cap the number of nodes.
+const nodeUsageUnlimited = -1
+
+func dataChefNodeUsage() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadNodeUsage,
+
+		Schema: map[string]*schema.Schema{
+			"active_within": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+			},
+			"node_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"node_limit": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"percent_used": {
+				Type:     schema.TypeFloat,
+				Computed: true,
+			},
+			"limit_exceeded": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func ReadNodeUsage(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	license, err := client.Global.License.Get()
+	if err != nil {
+		return chefErrToDiag("Error reading Chef server license", err, nil)
+	}
+
+	count := license.NodeCount
+	if v, ok := d.GetOk("active_within"); ok {
+		// Only count nodes that have completed a Chef run recently, rather
+		// than every node object the license endpoint knows about.
+		window, _ := time.ParseDuration(v.(string))
+		since := time.Now().Add(-window).Unix()
+
+		query, err := client.Search.NewQuery("node", fmt.Sprintf("ohai_time:[%d TO *]", since))
+		if err != nil {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Error creating search query",
+					Detail:        fmt.Sprint(err),
+					AttributePath: cty.GetAttrPath("active_within"),
+				},
+			}
+		}
+		query.Rows = 0
+
+		res, err := query.Do(client.Client)
+		if err != nil {
+			return chefErrToDiag("Error counting active nodes", err, cty.GetAttrPath("active_within"))
+		}
+		count = res.Total
+	}
+
+	limit := license.NodeLicense
+	percent := 0.0
+	if limit <= 0 {
+		limit = nodeUsageUnlimited
+	} else {
+		percent = float64(count) / float64(limit) * 100
+	}
+
+	d.SetId(client.Global.BaseURL.String())
+	d.Set("node_count", count)
+	d.Set("node_limit", limit)
+	d.Set("percent_used", percent)
+	d.Set("limit_exceeded", limit != nodeUsageUnlimited && count > limit)
+
+	return nil
+}
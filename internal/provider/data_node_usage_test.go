@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testNodeUsageServer(t *testing.T, license string) *chefClient {
+	return testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/license"):
+			w.Write([]byte(license))
+		case strings.HasSuffix(r.URL.Path, "/search/node"):
+			if !strings.HasPrefix(r.URL.Query().Get("q"), "ohai_time:[") {
+				t.Errorf("unexpected search query: %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"total":30,"start":0,"rows":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func TestReadNodeUsage(t *testing.T) {
+	c := testNodeUsageServer(t, `{"node_license":25,"node_count":20,"limit_exceeded":false}`)
+
+	d := schema.TestResourceDataRaw(t, dataChefNodeUsage().Schema, map[string]interface{}{})
+	if diags := ReadNodeUsage(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if got := d.Get("node_count").(int); got != 20 {
+		t.Errorf("wrong node_count: %d", got)
+	}
+	if got := d.Get("node_limit").(int); got != 25 {
+		t.Errorf("wrong node_limit: %d", got)
+	}
+	if got := d.Get("percent_used").(float64); got != 80 {
+		t.Errorf("wrong percent_used: %v", got)
+	}
+	if d.Get("limit_exceeded").(bool) {
+		t.Errorf("expected limit not to be exceeded")
+	}
+}
+
+func TestReadNodeUsage_activeWithin(t *testing.T) {
+	c := testNodeUsageServer(t, `{"node_license":25,"node_count":20}`)
+
+	d := schema.TestResourceDataRaw(t, dataChefNodeUsage().Schema, map[string]interface{}{
+		"active_within": "24h",
+	})
+	if diags := ReadNodeUsage(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if got := d.Get("node_count").(int); got != 30 {
+		t.Errorf("wrong node_count: %d", got)
+	}
+	if !d.Get("limit_exceeded").(bool) {
+		t.Errorf("expected limit to be exceeded")
+	}
+}
+
+func TestReadNodeUsage_unlimited(t *testing.T) {
+	c := testNodeUsageServer(t, `{"node_license":0,"node_count":1000}`)
+
+	d := schema.TestResourceDataRaw(t, dataChefNodeUsage().Schema, map[string]interface{}{})
+	if diags := ReadNodeUsage(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if got := d.Get("node_limit").(int); got != nodeUsageUnlimited {
+		t.Errorf("wrong node_limit: %d", got)
+	}
+	if got := d.Get("percent_used").(float64); got != 0 {
+		t.Errorf("wrong percent_used: %v", got)
+	}
+}
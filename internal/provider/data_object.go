@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// chefObjectType describes where objects of one type live in the API.
+// Names of types with more than one path segment, such as data bag items,
+// are given as slash-separated parts, each filling one %s of the path.
+type chefObjectType struct {
+	path   string
+	global bool
+}
+
+var chefObjectTypes = map[string]chefObjectType{
+	"client":            {path: "clients/%s"},
+	"container":         {path: "containers/%s"},
+	"cookbook":          {path: "cookbooks/%s/%s"},
+	"cookbook_artifact": {path: "cookbook_artifacts/%s/%s"},
+	"data_bag":          {path: "data/%s"},
+	"data_bag_item":     {path: "data/%s/%s"},
+	"environment":       {path: "environments/%s"},
+	"group":             {path: "groups/%s"},
+	"node":              {path: "nodes/%s"},
+	"policy":            {path: "policies/%s"},
+	"policy_group":      {path: "policy_groups/%s"},
+	"role":              {path: "roles/%s"},
+	"user":              {path: "users/%s", global: true},
+}
+
+func chefObjectTypeNames() []string {
+	names := make([]string, 0, len(chefObjectTypes))
+	for name := range chefObjectTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func dataChefObject() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadObject,
+
+		Schema: map[string]*schema.Schema{
+			"object_type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateObjectType,
+				Description:  "Type of the object to read. One of " + strings.Join(chefObjectTypeNames(), ", ") + ".",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the object. Cookbooks and cookbook artifacts are named `NAME/VERSION` and `NAME/IDENTIFIER`, and data bag items `BAG/ITEM`.",
+			},
+			"json": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The object exactly as returned by the Chef server.",
+			},
+		},
+	}
+}
+
+func validateObjectType(val interface{}, key string) (warns []string, errs []error) {
+	if _, ok := chefObjectTypes[val.(string)]; !ok {
+		errs = append(errs, fmt.Errorf("%s must be one of %s, got %q",
+			key, strings.Join(chefObjectTypeNames(), ", "), val.(string)))
+	}
+	return
+}
+
+func ReadObject(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	objectType := d.Get("object_type").(string)
+	name := d.Get("name").(string)
+
+	path, global, err := chefObjectPath(objectType, name)
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Invalid object name",
+				Detail:        fmt.Sprint(err),
+				AttributePath: cty.GetAttrPath("name"),
+			},
+		}
+	}
+
+	c := client.Client
+	if global {
+		c = client.Global
+	} else if diags := client.requireOrg(); diags != nil {
+		return diags
+	}
+
+	req, err := newRequestWithContext(ctx, c, "GET", path, nil)
+	if err != nil {
+		return chefErrToDiag("Error reading object", err, nil)
+	}
+
+	var raw json.RawMessage
+	res, err := c.Do(req, &raw)
+	if res != nil {
+		defer res.Body.Close()
+	}
+	if err != nil {
+		if isChefNotFound(err) {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Object not found",
+					Detail:        fmt.Sprintf("No %s named %s exists on the Chef server", objectType, name),
+					AttributePath: cty.GetAttrPath("name"),
+				},
+			}
+		}
+		return chefErrToDiag("Error reading object", err, nil)
+	}
+
+	d.SetId(objectType + "/" + name)
+	d.Set("json", string(raw))
+	return nil
+}
+
+// chefObjectPath returns the API path of the named object, and whether it
+// lives at the root of the server rather than within the organization.
+func chefObjectPath(objectType, name string) (string, bool, error) {
+	t := chefObjectTypes[objectType]
+
+	parts := strings.Split(name, "/")
+	if want := strings.Count(t.path, "%s"); len(parts) != want {
+		if want == 1 {
+			return "", false, fmt.Errorf("%s names must not contain a slash, got %q", objectType, name)
+		}
+		return "", false, fmt.Errorf("%s names must have %d slash-separated parts, got %q", objectType, want, name)
+	}
+
+	args := make([]interface{}, len(parts))
+	for i, part := range parts {
+		if part == "" {
+			return "", false, fmt.Errorf("%s name %q has an empty part", objectType, name)
+		}
+		args[i] = url.PathEscape(part)
+	}
+	return fmt.Sprintf(t.path, args...), t.global, nil
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestChefObjectPath(t *testing.T) {
+	cases := []struct {
+		objectType string
+		name       string
+		path       string
+		wantErr    bool
+	}{
+		{"role", "web", "roles/web", false},
+		{"data_bag_item", "users/jdoe", "data/users/jdoe", false},
+		{"cookbook", "nginx/1.0.0", "cookbooks/nginx/1.0.0", false},
+		{"role", "web/extra", "", true},
+		{"data_bag_item", "users", "", true},
+		{"data_bag_item", "users/", "", true},
+	}
+
+	for _, tc := range cases {
+		path, _, err := chefObjectPath(tc.objectType, tc.name)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s %s: unexpected error state %v", tc.objectType, tc.name, err)
+			continue
+		}
+		if path != tc.path {
+			t.Errorf("%s %s: expected path %q, got %q", tc.objectType, tc.name, tc.path, path)
+		}
+	}
+}
+
+func TestReadObject(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/organizations/test/data/users/jdoe" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"jdoe","shell":"/bin/zsh"}`))
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefObject().Schema, map[string]interface{}{
+		"object_type": "data_bag_item",
+		"name":        "users/jdoe",
+	})
+	if diags := ReadObject(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if got := d.Get("json").(string); got != `{"id":"jdoe","shell":"/bin/zsh"}` {
+		t.Fatalf("unexpected json %s", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataChefObject().Schema, map[string]interface{}{
+		"object_type": "role",
+		"name":        "missing",
+	})
+	diags := ReadObject(context.Background(), d, c)
+	if !diags.HasError() || diags[0].Summary != "Object not found" {
+		t.Fatalf("expected a not found error, got %v", diags)
+	}
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func dataChefPrincipal() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadPrincipal,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the user or client to look up.",
+			},
+			"principals": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The actors with this name, as the server authenticates them. A name used by both a user and a client has one entry for each.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"public_key": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"authz_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"org_member": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func ReadPrincipal(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	name := d.Get("name").(string)
+	principals, err := getPrincipals(ctx, client.Client, name)
+	if isChefNotFound(err) {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Principal not found",
+				Detail:        fmt.Sprintf("No user or client named %s exists in the organization", name),
+				AttributePath: cty.GetAttrPath("name"),
+			},
+		}
+	}
+	if err != nil {
+		return chefErrToDiag("Error reading principal", err, cty.GetAttrPath("name"))
+	}
+
+	result := make([]interface{}, 0, len(principals))
+	for _, p := range principals {
+		result = append(result, map[string]interface{}{
+			"type":       p.Type,
+			"public_key": p.PublicKey,
+			"authz_id":   p.AuthzId,
+			"org_member": p.OrgMember,
+		})
+	}
+
+	d.SetId(name)
+	d.Set("principals", result)
+
+	return nil
+}
+
+// getPrincipals looks up the actors named name. Chef Server 12.4 and later
+// wrap them in a principals list, since a user and a client may share a
+// name; older servers return a single principal on its own, which go-chef's
+// Principal can't decode.
+func getPrincipals(ctx context.Context, client *chefc.Client, name string) ([]chefc.Principals, error) {
+	req, err := newRequestWithContext(ctx, client, "GET", "principals/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		chefc.Principals
+		List []chefc.Principals `json:"principals"`
+	}
+	if _, err := client.Do(req, &res); err != nil {
+		return nil, err
+	}
+
+	if res.List == nil && res.Type != "" {
+		return []chefc.Principals{res.Principals}, nil
+	}
+	return res.List, nil
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestReadPrincipal(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/organizations/test/principals/deploy":
+			w.Write([]byte(`{"principals": [
+				{"name": "deploy", "type": "user", "public_key": "user-key", "authz_id": "a1", "org_member": true},
+				{"name": "deploy", "type": "client", "public_key": "client-key", "authz_id": "a2", "org_member": true}
+			]}`))
+		case "/organizations/test/principals/legacy":
+			w.Write([]byte(`{"name": "legacy", "type": "client", "public_key": "legacy-key", "authz_id": "a3", "org_member": false}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": ["Cannot find principal"]}`))
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefPrincipal().Schema, map[string]interface{}{
+		"name": "deploy",
+	})
+	if diags := ReadPrincipal(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if n := d.Get("principals.#").(int); n != 2 {
+		t.Fatalf("expected 2 principals, got %d", n)
+	}
+	if d.Get("principals.0.type") != "user" || d.Get("principals.1.public_key") != "client-key" {
+		t.Fatalf("unexpected principals %v", d.Get("principals"))
+	}
+
+	d = schema.TestResourceDataRaw(t, dataChefPrincipal().Schema, map[string]interface{}{
+		"name": "legacy",
+	})
+	if diags := ReadPrincipal(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Get("principals.#").(int) != 1 || d.Get("principals.0.authz_id") != "a3" || d.Get("principals.0.org_member").(bool) {
+		t.Fatalf("unexpected principals %v", d.Get("principals"))
+	}
+
+	d = schema.TestResourceDataRaw(t, dataChefPrincipal().Schema, map[string]interface{}{
+		"name": "nobody",
+	})
+	diags := ReadPrincipal(context.Background(), d, c)
+	if !diags.HasError() || diags[0].Summary != "Principal not found" {
+		t.Fatalf("expected a not found error, got %v", diags)
+	}
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataChefRequiredRecipe reports whether the server enforces a required
+// recipe on every chef-client run, and optionally what that recipe is.
+func dataChefRequiredRecipe() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadRequiredRecipe,
+
+		Schema: map[string]*schema.Schema{
+			"include_content": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If set, the recipe source is stored in content.",
+			},
+			"checksum": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Expected SHA-256 of the recipe, as hex. Reading fails if the server's recipe doesn't match.",
+			},
+			"enabled": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"content": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"sha256": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func ReadRequiredRecipe(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	// The recipe is served through the authenticated API itself, so reading
+	// it is the only way to find out whether one is configured.
+	recipe, err := client.RequiredRecipe.Get()
+	enabled := true
+	if err != nil {
+		if !isChefNotFound(err) {
+			return chefErrToDiag("Error reading required recipe", err, nil)
+		}
+		enabled = false
+	}
+
+	sum := ""
+	if enabled {
+		hash := sha256.Sum256([]byte(recipe))
+		sum = hex.EncodeToString(hash[:])
+	}
+
+	if expected, ok := d.GetOk("checksum"); ok {
+		if err := verifyRequiredRecipeChecksum(enabled, expected.(string), sum); err != nil {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Required recipe failed checksum verification",
+					Detail:        fmt.Sprint(err),
+					AttributePath: cty.GetAttrPath("checksum"),
+				},
+			}
+		}
+	}
+
+	url := client.BaseURL.String() + "required_recipe"
+	d.SetId(url)
+	d.Set("url", url)
+	d.Set("enabled", enabled)
+	d.Set("sha256", sum)
+	if d.Get("include_content").(bool) {
+		d.Set("content", string(recipe))
+	} else {
+		d.Set("content", "")
+	}
+	return nil
+}
+
+func verifyRequiredRecipeChecksum(enabled bool, expected, actual string) error {
+	if !enabled {
+		return fmt.Errorf("expected a required recipe with SHA-256 %s, but the server has none enabled", expected)
+	}
+	if !strings.EqualFold(expected, actual) {
+		return fmt.Errorf("expected SHA-256 %s, got %s", expected, actual)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestReadRequiredRecipe(t *testing.T) {
+	const recipe = "include_recipe 'audit::default'\n"
+	hash := sha256.Sum256([]byte(recipe))
+	sum := hex.EncodeToString(hash[:])
+
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/organizations/test/required_recipe" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(recipe))
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefRequiredRecipe().Schema, map[string]interface{}{
+		"include_content": true,
+		"checksum":        sum,
+	})
+	if diags := ReadRequiredRecipe(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if !d.Get("enabled").(bool) || d.Get("content").(string) != recipe || d.Get("sha256").(string) != sum {
+		t.Fatalf("unexpected state enabled=%v sha256=%v", d.Get("enabled"), d.Get("sha256"))
+	}
+
+	d = schema.TestResourceDataRaw(t, dataChefRequiredRecipe().Schema, map[string]interface{}{
+		"checksum": "0000",
+	})
+	if diags := ReadRequiredRecipe(context.Background(), d, c); !diags.HasError() {
+		t.Fatal("expected a checksum mismatch to fail")
+	}
+}
+
+func TestVerifyRequiredRecipeChecksum_disabled(t *testing.T) {
+	if err := verifyRequiredRecipeChecksum(false, "abc", ""); err == nil {
+		t.Fatal("expected an error when no required recipe is enabled")
+	}
+}
@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataChefRole() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadRoleData,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"run_list": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"env_run_lists": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Run lists that replace run_list in particular environments, keyed by environment. Each is a JSON array; use `jsondecode` to get a list.",
+			},
+			"default_attributes_json": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"override_attributes_json": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func ReadRoleData(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	name := d.Get("name").(string)
+
+	role, err := client.Roles.Get(name)
+	if err != nil {
+		if isChefNotFound(err) {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Role not found",
+					Detail:        fmt.Sprintf("No role named %s exists in the organization", name),
+					AttributePath: cty.GetAttrPath("name"),
+				},
+			}
+		}
+		return chefErrToDiag("Error reading role", err, cty.GetAttrPath("name"))
+	}
+
+	envRunLists := make(map[string]interface{}, len(role.EnvRunList))
+	for env, runList := range role.EnvRunList {
+		if runList == nil {
+			runList = []string{}
+		}
+		encoded, err := json.Marshal(runList)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		envRunLists[env] = string(encoded)
+	}
+
+	defaultAttrs, err := json.Marshal(role.DefaultAttributes)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	overrideAttrs, err := json.Marshal(role.OverrideAttributes)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	runList := make([]string, len(role.RunList))
+	copy(runList, role.RunList)
+
+	d.SetId(role.Name)
+	d.Set("description", role.Description)
+	d.Set("run_list", runList)
+	d.Set("env_run_lists", envRunLists)
+	d.Set("default_attributes_json", string(defaultAttrs))
+	d.Set("override_attributes_json", string(overrideAttrs))
+	return nil
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestReadRoleData(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/organizations/test/roles/web" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":["Cannot load role missing"]}`))
+			return
+		}
+		w.Write([]byte(`{
+			"name": "web",
+			"description": "Web servers",
+			"chef_type": "role",
+			"json_class": "Chef::Role",
+			"run_list": ["recipe[base]", "role[monitoring]"],
+			"env_run_lists": {"staging": ["recipe[base]"], "retired": []},
+			"default_attributes": {"nginx": {"port": 80}},
+			"override_attributes": {}
+		}`))
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefRole().Schema, map[string]interface{}{"name": "web"})
+	if diags := ReadRoleData(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if d.Id() != "web" || d.Get("description") != "Web servers" {
+		t.Fatalf("unexpected role %s: %v", d.Id(), d.Get("description"))
+	}
+	if got := fmt.Sprint(d.Get("run_list")); got != "[recipe[base] role[monitoring]]" {
+		t.Fatalf("unexpected run_list %s", got)
+	}
+	envRunLists := d.Get("env_run_lists").(map[string]interface{})
+	var staging []string
+	if err := json.Unmarshal([]byte(envRunLists["staging"].(string)), &staging); err != nil || fmt.Sprint(staging) != "[recipe[base]]" {
+		t.Fatalf("unexpected staging run list %v (%v)", envRunLists["staging"], err)
+	}
+	if envRunLists["retired"] != "[]" {
+		t.Fatalf("expected an empty environment run list to be kept, got %v", envRunLists["retired"])
+	}
+	if got := d.Get("default_attributes_json"); got != `{"nginx":{"port":80}}` {
+		t.Fatalf("unexpected default_attributes_json %s", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataChefRole().Schema, map[string]interface{}{"name": "missing"})
+	diags := ReadRoleData(context.Background(), d, c)
+	if !diags.HasError() || diags[0].Summary != "Role not found" {
+		t.Fatalf("expected a not found diagnostic, got %v", diags)
+	}
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 
@@ -11,6 +12,11 @@ import (
 	chefc "github.com/go-chef/chef"
 )
 
+// searchPageSize is how many rows are fetched per request when paging
+// through partial search results, which are small enough that Chef's own
+// default page size is fine.
+const searchPageSize = 1000
+
 func dataChefSearch() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataChefSearchRead,
@@ -26,8 +32,9 @@ func dataChefSearch() *schema.Resource {
 				Required: true,
 			},
 			"filter": &schema.Schema{
-				Type:     schema.TypeSet,
-				Optional: true,
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Partial search: return only these attributes of each row. Each filter names an output key and gives the path to the attribute as a list of keys, such as `[\"cloud\", \"public_ipv4\"]`.",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"name": {
@@ -54,6 +61,14 @@ func dataChefSearch() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"results": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Every matching row's filtered attributes, as a JSON object per row. Only set when filter is, since without it each row is a whole document.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 			"total_num": &schema.Schema{
 				Type:     schema.TypeInt,
 				Computed: true,
@@ -63,7 +78,7 @@ func dataChefSearch() *schema.Resource {
 }
 
 func dataChefSearchRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*chefc.Client)
+	client := meta.(*chefClient)
 
 	query, err := client.Search.NewQuery(d.Get("index").(string), d.Get("query").(string))
 	if err != nil {
@@ -75,33 +90,58 @@ func dataChefSearchRead(ctx context.Context, d *schema.ResourceData, meta interf
 			},
 		}
 	}
-	query.Rows = 1
 
-	filter, ok := d.Get("filter").(*schema.Set)
-	var res chefc.SearchResult
-	if ok {
-		params := make(map[string]interface{})
+	var params map[string]interface{}
+	if filter := d.Get("filter").(*schema.Set); filter.Len() > 0 {
+		params = make(map[string]interface{})
 		for _, v := range filter.List() {
 			m := v.(map[string]interface{})
 			params[m["name"].(string)] = m["value"].([]interface{})
 		}
-		res, err = query.DoPartial(client, params)
+		query.Rows = searchPageSize
 	} else {
-		res, err = query.Do(client)
+		query.Rows = 1
 	}
-	if err != nil {
-		return diag.Diagnostics{
-			{
-				Severity: diag.Error,
-				Summary:  "Error executing search",
-				Detail:   fmt.Sprint(err),
-			},
+
+	var res chefc.SearchResult
+	results := make([]interface{}, 0)
+	for {
+		var page chefc.SearchResult
+		if params != nil {
+			page, err = query.DoPartial(client.Client, params)
+		} else {
+			page, err = query.Do(client.Client)
+		}
+		if err != nil {
+			return chefErrToDiag("Error executing search", err, nil)
+		}
+		if query.Start == 0 {
+			res = page
+		}
+		if params == nil {
+			break
+		}
+
+		for _, r := range page.Rows {
+			row, _ := r.(map[string]interface{})
+			data, err := json.Marshal(row["data"])
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			results = append(results, string(data))
+		}
+		query.Start += len(page.Rows)
+		if len(page.Rows) == 0 || query.Start >= page.Total {
+			break
 		}
 	}
 
 	log.Printf("Chef search result: %+v\n", res)
 	d.SetId("static")
 	d.Set("total_num", res.Total)
+	if params != nil {
+		d.Set("results", results)
+	}
 	if d.Get("unique").(bool) && res.Total != 1 {
 		return diag.Diagnostics{
 			{
@@ -111,15 +151,20 @@ func dataChefSearchRead(ctx context.Context, d *schema.ResourceData, meta interf
 			},
 		}
 	}
-	if res.Total > 0 {
+	if res.Total > 0 && len(res.Rows) > 0 {
 		result := make(map[string]string)
 		row := res.Rows[0].(map[string]interface{})
-		// For some indexes the data is returned in data and for others in raw_data
-		data, ok := row["data"]
+		// Partial search returns the data in data, and full search of data
+		// bags in raw_data. Other full searches return the documents as
+		// they are.
+		data, ok := row["data"].(map[string]interface{})
+		if !ok {
+			data, ok = row["raw_data"].(map[string]interface{})
+		}
 		if !ok {
-			data = row["raw_data"]
+			data = row
 		}
-		for k, v := range data.(map[string]interface{}) {
+		for k, v := range data {
 			switch t := v.(type) {
 			case string:
 				result[k] = t
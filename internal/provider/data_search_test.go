@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataChefSearchRead_partial(t *testing.T) {
+	nodes := []string{"web1", "web2", "web3"}
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/organizations/test/search/node" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var params map[string][]string
+		json.NewDecoder(r.Body).Decode(&params)
+		if !reflect.DeepEqual(params, map[string][]string{"ip": {"cloud", "public_ipv4"}}) {
+			t.Errorf("unexpected partial search body %v", params)
+		}
+
+		// Pages of two, whatever was asked for.
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		rows := make([]interface{}, 0)
+		for i := start; i < len(nodes) && i < start+2; i++ {
+			rows = append(rows, map[string]interface{}{
+				"url":  "https://chef/organizations/test/nodes/" + nodes[i],
+				"data": map[string]interface{}{"ip": fmt.Sprintf("10.0.0.%d", i+1)},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"total": len(nodes), "start": start, "rows": rows})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefSearch().Schema, map[string]interface{}{
+		"query": "role:web",
+		"filter": []interface{}{
+			map[string]interface{}{"name": "ip", "value": []interface{}{"cloud", "public_ipv4"}},
+		},
+	})
+	if diags := dataChefSearchRead(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	expected := []interface{}{`{"ip":"10.0.0.1"}`, `{"ip":"10.0.0.2"}`, `{"ip":"10.0.0.3"}`}
+	if results := d.Get("results").([]interface{}); !reflect.DeepEqual(results, expected) {
+		t.Fatalf("expected %v, got %v", expected, results)
+	}
+	if d.Get("total_num").(int) != 3 || d.Get("result.ip") != "10.0.0.1" {
+		t.Fatalf("unexpected total %d and result %v", d.Get("total_num"), d.Get("result"))
+	}
+}
+
+func TestDataChefSearchRead_unfiltered(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Query().Get("rows") != "1" {
+			t.Errorf("expected a single row full search, got %s %s", r.Method, r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"total": 2, "start": 0, "rows": [{"name": "web1", "chef_environment": "prod"}]}`))
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefSearch().Schema, map[string]interface{}{
+		"query": "role:web",
+	})
+	if diags := dataChefSearchRead(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if len(d.Get("results").([]interface{})) != 0 {
+		t.Fatal("expected results to be left empty without a filter")
+	}
+	if d.Get("result.chef_environment") != "prod" {
+		t.Fatalf("expected the first node's attributes, got %v", d.Get("result"))
+	}
+}
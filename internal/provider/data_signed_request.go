@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataChefSignedRequest signs a request without sending it, so that it can
+// be replayed through another client or kept as a record of what was
+// signed. Chef servers reject signatures more than 15 minutes old, so the
+// result is only useful shortly after it is read.
+func dataChefSignedRequest() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadSignedRequest,
+
+		Schema: map[string]*schema.Schema{
+			"method": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "GET",
+			},
+			"path": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Path of the request, relative to the organization.",
+			},
+			"body": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"headers": {
+				Type:      schema.TypeMap,
+				Computed:  true,
+				Sensitive: true,
+				Elem:      &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func ReadSignedRequest(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	method := strings.ToUpper(d.Get("method").(string))
+	var body io.Reader
+	if b, ok := d.GetOk("body"); ok {
+		body = strings.NewReader(b.(string))
+	}
+
+	url, headers, err := signedHeaders(client.Client, method, d.Get("path").(string), body)
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Error signing request",
+				Detail:        fmt.Sprint(err),
+				AttributePath: cty.GetAttrPath("path"),
+			},
+		}
+	}
+
+	d.SetId(method + " " + url)
+	d.Set("url", url)
+	d.Set("headers", headers)
+	return nil
+}
@@ -0,0 +1,202 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func dataChefUniverse() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadUniverse,
+
+		Schema: map[string]*schema.Schema{
+			"cookbook": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the versions of this cookbook. A cookbook that isn't on the server gives an empty `cookbooks` list.",
+			},
+			"cookbooks": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"versions": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"version": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"location_path": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"location_type": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"dependencies": {
+										Type:     schema.TypeMap,
+										Computed: true,
+										Elem:     &schema.Schema{Type: schema.TypeString},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// universeVersion is one cookbook version in the universe. go-chef's
+// UniverseVersion has no JSON tags, so it can't be decoded into directly.
+type universeVersion struct {
+	LocationPath string            `json:"location_path"`
+	LocationType string            `json:"location_type"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+func ReadUniverse(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	filter := d.Get("cookbook").(string)
+	universe, err := streamUniverse(ctx, client.Client, filter)
+	if err != nil {
+		return chefErrToDiag("Error reading universe", err, nil)
+	}
+
+	names := make([]string, 0, len(universe))
+	for name := range universe {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cookbooks := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		cookbooks = append(cookbooks, map[string]interface{}{
+			"name":     name,
+			"versions": flattenUniverseVersions(universe[name]),
+		})
+	}
+
+	id := client.BaseURL.String() + "universe"
+	if filter != "" {
+		id += "/" + filter
+	}
+	d.SetId(id)
+	d.Set("cookbooks", cookbooks)
+
+	return nil
+}
+
+// streamUniverse decodes the universe as it arrives, one cookbook at a
+// time. When cookbook is set every other cookbook is skipped without being
+// kept, so filtering a large universe stays cheap.
+func streamUniverse(ctx context.Context, client *chefc.Client, cookbook string) (map[string]map[string]universeVersion, error) {
+	req, err := newRequestWithContext(withStreamedBody(ctx), client, "GET", "universe", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := chefHTTPClient(client).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if err := chefc.CheckResponse(res); err != nil {
+		return nil, err
+	}
+	return decodeUniverse(res.Body, cookbook)
+}
+
+func decodeUniverse(r io.Reader, cookbook string) (map[string]map[string]universeVersion, error) {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("universe is not a JSON object")
+	}
+
+	universe := make(map[string]map[string]universeVersion)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name := tok.(string)
+
+		if cookbook != "" && name != cookbook {
+			if err := dec.Decode(&struct{}{}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var versions map[string]universeVersion
+		if err := dec.Decode(&versions); err != nil {
+			return nil, fmt.Errorf("decoding cookbook %s: %w", name, err)
+		}
+		universe[name] = versions
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return universe, nil
+}
+
+// flattenUniverseVersions lists a cookbook's versions oldest first.
+// Versions that don't parse sort after the rest, by name.
+func flattenUniverseVersions(versions map[string]universeVersion) []interface{} {
+	names := make([]string, 0, len(versions))
+	for v := range versions {
+		names = append(names, v)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		vi, _, erri := parseChefVersion(names[i])
+		vj, _, errj := parseChefVersion(names[j])
+		switch {
+		case erri == nil && errj == nil:
+			if c := vi.compare(vj); c != 0 {
+				return c < 0
+			}
+		case erri == nil:
+			return true
+		case errj == nil:
+			return false
+		}
+		return names[i] < names[j]
+	})
+
+	result := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		v := versions[name]
+		deps := make(map[string]interface{}, len(v.Dependencies))
+		for dep, constraint := range v.Dependencies {
+			deps[dep] = constraint
+		}
+		result = append(result, map[string]interface{}{
+			"version":       name,
+			"location_path": v.LocationPath,
+			"location_type": v.LocationType,
+			"dependencies":  deps,
+		})
+	}
+	return result
+}
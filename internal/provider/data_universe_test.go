@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestReadUniverse(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/organizations/test/universe" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"nginx": {
+				"10.0.0": {
+					"location_path": "https://chef/organizations/test/cookbooks/nginx/10.0.0",
+					"location_type": "chef_server",
+					"dependencies": {"ohai": ">= 4.0"}
+				},
+				"9.2.1": {
+					"location_path": "https://chef/organizations/test/cookbooks/nginx/9.2.1",
+					"location_type": "chef_server",
+					"dependencies": {}
+				}
+			},
+			"apt": {
+				"7.0.0": {
+					"location_path": "https://chef/organizations/test/cookbooks/apt/7.0.0",
+					"location_type": "chef_server",
+					"dependencies": {}
+				}
+			}
+		}`))
+	})
+
+	cases := []struct {
+		cookbook string
+		expected []string
+	}{
+		{"", []string{"apt", "nginx"}},
+		{"nginx", []string{"nginx"}},
+		{"missing", []string{}},
+	}
+	for _, tc := range cases {
+		d := schema.TestResourceDataRaw(t, dataChefUniverse().Schema, map[string]interface{}{
+			"cookbook": tc.cookbook,
+		})
+		if diags := ReadUniverse(context.Background(), d, c); diags.HasError() {
+			t.Fatalf("err: %v", diags)
+		}
+
+		names := make([]string, 0)
+		for i := 0; i < d.Get("cookbooks.#").(int); i++ {
+			names = append(names, d.Get(fmt.Sprintf("cookbooks.%d.name", i)).(string))
+		}
+		if !reflect.DeepEqual(names, tc.expected) {
+			t.Fatalf("cookbook %q: expected %v, got %v", tc.cookbook, tc.expected, names)
+		}
+	}
+
+	d := schema.TestResourceDataRaw(t, dataChefUniverse().Schema, map[string]interface{}{
+		"cookbook": "nginx",
+	})
+	if diags := ReadUniverse(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if v := d.Get("cookbooks.0.versions.0.version").(string); v != "9.2.1" {
+		t.Fatalf("expected versions oldest first, got %s first", v)
+	}
+	if dep := d.Get("cookbooks.0.versions.1.dependencies.ohai").(string); dep != ">= 4.0" {
+		t.Fatalf("expected nginx 10.0.0 to depend on ohai >= 4.0, got %q", dep)
+	}
+}
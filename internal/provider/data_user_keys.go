@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func dataChefUserKeys() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ReadUserKeys,
+
+		Schema: map[string]*schema.Schema{
+			"user": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"keys": keyListSchema(),
+		},
+	}
+}
+
+// keyListSchema is the keys attribute of the user and client key listings.
+func keyListSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "The keys, by name. expired is as reported by the server.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"expired": {
+					Type:     schema.TypeBool,
+					Computed: true,
+				},
+				"uri": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+func ReadUserKeys(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	user := d.Get("user").(string)
+	keys, err := listAccessKeys(ctx, client.Global, "users/"+user)
+	if isChefNotFound(err) {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "User not found",
+				Detail:        fmt.Sprintf("No user named %s exists on the server", user),
+				AttributePath: cty.GetAttrPath("user"),
+			},
+		}
+	}
+	if err != nil {
+		return chefErrToDiag("Error listing user keys", err, cty.GetAttrPath("user"))
+	}
+
+	d.SetId(user)
+	d.Set("keys", flattenKeyItems(keys))
+	return nil
+}
+
+// flattenKeyItems lists keys sorted by name. The server works out whether
+// each key has expired, so expired is taken from it rather than from the
+// key's expiration date.
+func flattenKeyItems(keys []chefc.KeyItem) []interface{} {
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+
+	result := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, map[string]interface{}{
+			"name":    key.Name,
+			"expired": key.Expired,
+			"uri":     key.Uri,
+		})
+	}
+	return result
+}
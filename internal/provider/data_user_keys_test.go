@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestReadUserKeys(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/organizations/test/users/alice/keys":
+			// expired comes from the server even though these keys carry
+			// no expiration date.
+			w.Write([]byte(`[
+				{"name": "laptop", "uri": "https://chef/users/alice/keys/laptop", "expired": true},
+				{"name": "default", "uri": "https://chef/users/alice/keys/default", "expired": false}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": ["not found"]}`))
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, dataChefUserKeys().Schema, map[string]interface{}{
+		"user": "alice",
+	})
+	if diags := ReadUserKeys(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Get("keys.#").(int) != 2 || d.Get("keys.0.name") != "default" || d.Get("keys.1.name") != "laptop" {
+		t.Fatalf("expected keys sorted by name, got %v", d.Get("keys"))
+	}
+	if d.Get("keys.0.expired").(bool) || !d.Get("keys.1.expired").(bool) {
+		t.Fatalf("expected expired as reported by the server, got %v", d.Get("keys"))
+	}
+
+	d = schema.TestResourceDataRaw(t, dataChefUserKeys().Schema, map[string]interface{}{
+		"user": "nobody",
+	})
+	diags := ReadUserKeys(context.Background(), d, c)
+	if !diags.HasError() || diags[0].Summary != "User not found" {
+		t.Fatalf("expected a not found error, got %v", diags)
+	}
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// chefStatusHints explain the error statuses whose server messages tend to
+// be terse.
+var chefStatusHints = map[int]string{
+	http.StatusUnauthorized: "The Chef server did not accept the provider's client name and key.",
+	http.StatusForbidden:    "The provider's client is not permitted to do this. Check the ACLs on the object or its container.",
+	http.StatusConflict:     "An object with this name already exists. Import it rather than creating it again.",
+}
+
+// chefErrToDiag turns err into an error diagnostic summarized by summary.
+// For Chef API errors the request and response status are added to the
+// summary and the server's message becomes the detail; any other error is
+// used as the detail as it is. attr may be nil for errors that aren't about
+// a particular attribute.
+func chefErrToDiag(summary string, err error, attr cty.Path) diag.Diagnostics {
+	d := diag.Diagnostic{
+		Severity:      diag.Error,
+		Summary:       summary,
+		Detail:        fmt.Sprint(err),
+		AttributePath: attr,
+	}
+
+	var errRes *chefc.ErrorResponse
+	if !errors.As(err, &errRes) || errRes.Response == nil {
+		return diag.Diagnostics{d}
+	}
+
+	status := fmt.Sprintf("%d %s", errRes.StatusCode(), http.StatusText(errRes.StatusCode()))
+	if req := errRes.Response.Request; req != nil {
+		d.Summary = fmt.Sprintf("%s: %s %s returned %s", summary, req.Method, req.URL.Redacted(), status)
+	} else {
+		d.Summary = fmt.Sprintf("%s: %s", summary, status)
+	}
+
+	msg := strings.TrimSpace(errRes.StatusMsg())
+	if msg == "" {
+		msg = strings.TrimSpace(string(errRes.StatusText()))
+	}
+	if msg == "" {
+		msg = status
+	}
+	if hint, ok := chefStatusHints[errRes.StatusCode()]; ok {
+		msg += "\n\n" + hint
+	}
+	d.Detail = msg
+
+	return diag.Diagnostics{d}
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+)
+
+func TestChefErrToDiag(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error":["Client already exists"]}`))
+	})
+	_, err := c.Clients.Get("ci")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, err := range []error{err, fmt.Errorf("creating client: %w", err)} {
+		diags := chefErrToDiag("Error creating client", err, cty.GetAttrPath("name"))
+		if len(diags) != 1 {
+			t.Fatalf("expected one diagnostic, got %v", diags)
+		}
+		d := diags[0]
+		if !strings.HasPrefix(d.Summary, "Error creating client: GET http://") ||
+			!strings.HasSuffix(d.Summary, "/organizations/test/clients/ci returned 409 Conflict") {
+			t.Errorf("unexpected summary %q", d.Summary)
+		}
+		if !strings.HasPrefix(d.Detail, "Client already exists\n\n") || !strings.Contains(d.Detail, "already exists. Import it") {
+			t.Errorf("unexpected detail %q", d.Detail)
+		}
+		if !d.AttributePath.Equals(cty.GetAttrPath("name")) {
+			t.Errorf("unexpected attribute path %#v", d.AttributePath)
+		}
+	}
+
+	diags := chefErrToDiag("Error creating client", errors.New("connection refused"), nil)
+	if d := diags[0]; d.Summary != "Error creating client" || d.Detail != "connection refused" || d.AttributePath != nil {
+		t.Errorf("expected other errors to be used as they are, got %#v", d)
+	}
+}
+
+func TestIsChefNotFound(t *testing.T) {
+	for status, want := range map[int]bool{
+		http.StatusNotFound:            true,
+		http.StatusForbidden:           false,
+		http.StatusInternalServerError: false,
+	} {
+		status := status
+		c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":["nope"]}`))
+		})
+		_, err := c.Nodes.Get("web1")
+		if err == nil {
+			t.Fatalf("%d: expected an error", status)
+		}
+
+		for _, err := range []error{err, fmt.Errorf("reading node: %w", err)} {
+			if got := isChefNotFound(err); got != want {
+				t.Errorf("%d: expected isChefNotFound(%q) to be %t", status, err, want)
+			}
+		}
+	}
+
+	for _, err := range []error{nil, errors.New("404 not found")} {
+		if isChefNotFound(err) {
+			t.Errorf("expected %v not to be a Chef not found error", err)
+		}
+	}
+}
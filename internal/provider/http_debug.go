@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// redactedHeaderValue replaces the value of every header that carries a
+// credential.
+const redactedHeaderValue = "REDACTED"
+
+// httpDebugTransport logs each request and response sent to the Chef
+// server, for diagnosing problems such as rejected signatures. Bodies are
+// never logged, since they can hold private keys, and credential headers
+// are redacted.
+type httpDebugTransport struct {
+	base http.RoundTripper
+
+	// ctx carries the provider's logger, for the requests go-chef's
+	// services send without a context. Requests made for an operation with
+	// newRequestWithContext are logged through that operation's context.
+	ctx context.Context
+
+	// log is tflog.Debug, replaced in tests.
+	log func(ctx context.Context, msg string, fields ...map[string]interface{})
+}
+
+func newHTTPDebugTransport(ctx context.Context, base http.RoundTripper) *httpDebugTransport {
+	return &httpDebugTransport{base: base, ctx: ctx, log: tflog.Debug}
+}
+
+func (t *httpDebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := t.ctx
+	if operationContext(req.Context()) {
+		ctx = req.Context()
+	}
+
+	fields := map[string]interface{}{
+		"method":  req.Method,
+		"url":     req.URL.Redacted(),
+		"headers": redactedHeaders(req.Header),
+	}
+	if content, ok := signedContent(req); ok {
+		fields["signed_content"] = content
+	}
+	t.log(ctx, "Chef HTTP request", fields)
+
+	start := time.Now()
+	res, err := t.base.RoundTrip(req)
+	fields = map[string]interface{}{
+		"method":     req.Method,
+		"url":        req.URL.Redacted(),
+		"elapsed_ms": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		t.log(ctx, "Chef HTTP request failed", fields)
+		return res, err
+	}
+	fields["status"] = res.StatusCode
+	fields["headers"] = redactedHeaders(res.Header)
+	t.log(ctx, "Chef HTTP response", fields)
+	return res, nil
+}
+
+// redactedHeaders flattens h for logging, with the values of credential
+// headers replaced.
+func redactedHeaders(h http.Header) map[string]string {
+	result := make(map[string]string, len(h))
+	for name, values := range h {
+		value := strings.Join(values, ", ")
+		if credentialHeader(name) {
+			value = redactedHeaderValue
+		}
+		result[name] = value
+	}
+	return result
+}
+
+func credentialHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	switch name {
+	case "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie":
+		return true
+	}
+	return strings.HasPrefix(name, "X-Ops-Authorization-")
+}
+
+// signedContent rebuilds the canonical string a request's signature was
+// made over, from the headers it was sent with. Comparing it with what the
+// server expects is usually the quickest way to find why a signature was
+// rejected. Unsigned requests have none.
+func signedContent(req *http.Request) (string, bool) {
+	if req.Header.Get("X-Ops-Authorization-1") == "" {
+		return "", false
+	}
+
+	sign := req.Header.Get("X-Ops-Sign")
+	auth := chefc.AuthConfig{AuthenticationVersion: "1.0"}
+	if strings.Contains(sign, "version=1.3") {
+		auth.AuthenticationVersion = "1.3"
+	}
+
+	endpoint := req.URL.Path
+	if endpoint != "" {
+		endpoint = path.Clean(endpoint)
+	}
+	return auth.SignatureContent(map[string]string{
+		"Method":                   req.Method,
+		"Path":                     endpoint,
+		"Hashed Path":              chefc.HashStr(endpoint),
+		"X-Ops-Content-Hash":       req.Header.Get("X-Ops-Content-Hash"),
+		"X-Ops-Sign":               sign,
+		"X-Ops-Timestamp":          req.Header.Get("X-Ops-Timestamp"),
+		"X-Ops-UserId":             req.Header.Get("X-Ops-UserId"),
+		"X-Ops-Server-API-Version": req.Header.Get("X-Ops-Server-API-Version"),
+	}), true
+}
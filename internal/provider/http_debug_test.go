@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestHTTPDebugTransport(t *testing.T) {
+	var authorization string
+	config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+		var chunks []string
+		for i := 1; r.Header.Get(fmt.Sprintf("X-Ops-Authorization-%d", i)) != ""; i++ {
+			chunks = append(chunks, r.Header.Get(fmt.Sprintf("X-Ops-Authorization-%d", i)))
+		}
+		authorization = strings.Join(chunks, "")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret-session")
+		w.Write([]byte(`{"name":"web1"}`))
+	})
+	config.AuthenticationVersion = "1.3"
+	config.BaseURL = strings.Replace(config.BaseURL, "http://", "http://admin:basic-secret@", 1)
+
+	client, err := chefc.NewClient(&config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	var logged []map[string]interface{}
+	debug := newHTTPDebugTransport(context.Background(), chefHTTPClient(client).Transport)
+	debug.log = func(ctx context.Context, msg string, fields ...map[string]interface{}) {
+		logged = append(logged, fields...)
+	}
+	chefHTTPClient(client).Transport = debug
+
+	if _, err := client.Nodes.Get("web1"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(logged) != 2 {
+		t.Fatalf("expected a request and a response to be logged, got %v", logged)
+	}
+
+	all := fmt.Sprint(logged)
+	for _, secret := range []string{authorization[:20], "basic-secret", "secret-session", strings.Split(config.Key, "\n")[1]} {
+		if strings.Contains(all, secret) {
+			t.Errorf("expected %q to be left out of the log, got %s", secret, all)
+		}
+	}
+
+	headers := logged[0]["headers"].(map[string]string)
+	if headers["X-Ops-Authorization-1"] != redactedHeaderValue {
+		t.Fatalf("expected the signature to be redacted, got %v", headers)
+	}
+	if headers["X-Ops-Userid"] != "test" {
+		t.Fatalf("expected other headers to be logged, got %v", headers)
+	}
+
+	// The logged canonical string is the one the request was signed over.
+	content := logged[0]["signed_content"].(string)
+	sig, err := base64.StdEncoding.DecodeString(authorization)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	hashed := sha256.Sum256([]byte(content))
+	if err := rsa.VerifyPKCS1v15(&client.Auth.PrivateKey.PublicKey, crypto.SHA256, hashed[:], sig); err != nil {
+		t.Fatalf("signature doesn't match logged content %q: %s", content, err)
+	}
+
+	if status := logged[1]["status"]; status != http.StatusOK {
+		t.Fatalf("expected the response status to be logged, got %v", status)
+	}
+}
+
+func TestSignedContent_unsigned(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://bookshelf.example.com/file", nil)
+	if _, ok := signedContent(req); ok {
+		t.Fatal("expected no signed content for an unsigned request")
+	}
+}
+
+func TestHTTPDebugTransport_serverAPIVersion(t *testing.T) {
+	var authorization, apiVersion string
+	config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+		var chunks []string
+		for i := 1; r.Header.Get(fmt.Sprintf("X-Ops-Authorization-%d", i)) != ""; i++ {
+			chunks = append(chunks, r.Header.Get(fmt.Sprintf("X-Ops-Authorization-%d", i)))
+		}
+		authorization = strings.Join(chunks, "")
+		apiVersion = r.Header.Get("X-Ops-Server-API-Version")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"web1"}`))
+	})
+	config.AuthenticationVersion = "1.3"
+
+	var providerLog bytes.Buffer
+	client, err := (&clientOptions{
+		ServerAPIVersion: 2,
+		HTTPDebug:        tflogtest.RootLogger(context.Background(), &providerLog),
+	}).newClient(config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := client.Nodes.Get("web1"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&providerLog)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(entries) != 2 || entries[0]["@message"] != "Chef HTTP request" {
+		t.Fatalf("expected a request and a response to be logged, got %v", entries)
+	}
+	headers := entries[0]["headers"].(map[string]interface{})
+	if apiVersion != "2" || headers["X-Ops-Server-Api-Version"] != "2" {
+		t.Fatalf("expected the re-signed API version to be logged, sent %q, logged %v", apiVersion, headers)
+	}
+
+	// The logged canonical string is the one the re-signed request was
+	// signed over.
+	sig, err := base64.StdEncoding.DecodeString(authorization)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	hashed := sha256.Sum256([]byte(entries[0]["signed_content"].(string)))
+	if err := rsa.VerifyPKCS1v15(&client.Auth.PrivateKey.PublicKey, crypto.SHA256, hashed[:], sig); err != nil {
+		t.Fatalf("signature doesn't match logged content %q: %s", entries[0]["signed_content"], err)
+	}
+
+	// Requests made for an operation are logged through its context.
+	var operationLog bytes.Buffer
+	req, err := newRequestWithContext(tflogtest.RootLogger(context.Background(), &operationLog), client, "GET", "nodes/web1", nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	res, err := client.Do(req, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	res.Body.Close()
+	if providerLog.Len() != 0 || !strings.Contains(operationLog.String(), "Chef HTTP request") {
+		t.Fatalf("expected the request to be logged to the operation's logger only, got %q and %q", providerLog.String(), operationLog.String())
+	}
+}
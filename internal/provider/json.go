@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
+)
+
+func jsonStateFunc(value interface{}) string {
+	// Parse and re-stringify the JSON to make sure it's always kept
+	// in a normalized form.
+	jsonValue, err := structure.NormalizeJsonString(value)
+	if err != nil {
+		return "null"
+	}
+
+	return jsonValue
+}
+
+// suppressEquivalentJSON treats two JSON documents as equal when they decode
+// to the same value, whatever their key order, whitespace or number
+// formatting. Invalid JSON on either side is always a change, so that the
+// error surfaces rather than being hidden.
+func suppressEquivalentJSON(k, old, new string, d *schema.ResourceData) bool {
+	var oldValue, newValue interface{}
+	if err := json.Unmarshal([]byte(old), &oldValue); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(new), &newValue); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(oldValue, newValue)
+}
@@ -0,0 +1,25 @@
+package provider
+
+import "testing"
+
+func TestSuppressEquivalentJSON(t *testing.T) {
+	cases := []struct {
+		old, new   string
+		equivalent bool
+	}{
+		{`{"a":1,"b":{"c":[1,2]}}`, `{"b":{"c":[1,2]},"a":1}`, true},
+		{`{"a":1}`, "{\n  \"a\" : 1\n}\n", true},
+		{`{"a":1}`, `{"a":1.0}`, true},
+		{`{"a":1}`, `{"a":1e0}`, true},
+		{`{"a":[1,2]}`, `{"a":[2,1]}`, false},
+		{`{"a":1}`, `{"a":"1"}`, false},
+		{`{"a":null}`, `{}`, false},
+		{`{}`, `{`, false},
+		{``, `{}`, false},
+	}
+	for _, c := range cases {
+		if got := suppressEquivalentJSON("content_json", c.old, c.new, nil); got != c.equivalent {
+			t.Errorf("suppressEquivalentJSON(%q, %q) = %v, expected %v", c.old, c.new, got, c.equivalent)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// requestMetrics receives an event for every request the provider sends,
+// so that failures and retries can be counted per endpoint. Endpoints are
+// the API collection a request addresses, such as "nodes" or "roles".
+type requestMetrics interface {
+	RequestStarted(method, endpoint string)
+	RequestFinished(method, endpoint string, status int, elapsed time.Duration)
+	RequestFailed(method, endpoint string, status int, err error)
+	RequestRetried(method, endpoint string, attempt int)
+}
+
+// noopMetrics is used when metrics are disabled.
+type noopMetrics struct{}
+
+func (noopMetrics) RequestStarted(method, endpoint string)                                     {}
+func (noopMetrics) RequestFinished(method, endpoint string, status int, elapsed time.Duration) {}
+func (noopMetrics) RequestFailed(method, endpoint string, status int, err error)               {}
+func (noopMetrics) RequestRetried(method, endpoint string, attempt int)                        {}
+
+// logMetrics writes each event to the provider log as key=value pairs, for
+// collection by whatever already ships Terraform logs.
+type logMetrics struct{}
+
+func (logMetrics) RequestStarted(method, endpoint string) {
+	log.Printf("[DEBUG] chef_metrics event=start method=%s endpoint=%s", method, endpoint)
+}
+
+func (logMetrics) RequestFinished(method, endpoint string, status int, elapsed time.Duration) {
+	log.Printf("[DEBUG] chef_metrics event=end method=%s endpoint=%s status=%d elapsed_ms=%d",
+		method, endpoint, status, elapsed.Milliseconds())
+}
+
+func (logMetrics) RequestFailed(method, endpoint string, status int, err error) {
+	if err != nil {
+		log.Printf("[DEBUG] chef_metrics event=error method=%s endpoint=%s status=%d error=%q", method, endpoint, status, err)
+		return
+	}
+	log.Printf("[DEBUG] chef_metrics event=error method=%s endpoint=%s status=%d", method, endpoint, status)
+}
+
+func (logMetrics) RequestRetried(method, endpoint string, attempt int) {
+	log.Printf("[DEBUG] chef_metrics event=retry method=%s endpoint=%s attempt=%d", method, endpoint, attempt)
+}
+
+// metricsEndpoint reduces a request path to the API collection it
+// addresses, dropping the organization prefix and any object names so that
+// events aggregate per endpoint rather than per object.
+func metricsEndpoint(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "organizations" {
+			segments = segments[i+2:]
+			break
+		}
+	}
+	if len(segments) == 0 || segments[0] == "" {
+		return "/"
+	}
+	return segments[0]
+}
+
+// metricsTransport reports every attempt at a request to metrics.
+type metricsTransport struct {
+	base    http.RoundTripper
+	metrics requestMetrics
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := metricsEndpoint(req.URL.Path)
+	t.metrics.RequestStarted(req.Method, endpoint)
+	start := time.Now()
+
+	res, err := t.base.RoundTrip(req)
+
+	status := 0
+	if res != nil {
+		status = res.StatusCode
+	}
+	t.metrics.RequestFinished(req.Method, endpoint, status, time.Since(start))
+	if err != nil || status >= 400 {
+		t.metrics.RequestFailed(req.Method, endpoint, status, err)
+	}
+	return res, err
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (m *recordingMetrics) record(event string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+}
+
+func (m *recordingMetrics) RequestStarted(method, endpoint string) {
+	m.record("start " + endpoint)
+}
+
+func (m *recordingMetrics) RequestFinished(method, endpoint string, status int, elapsed time.Duration) {
+	m.record("end " + endpoint)
+}
+
+func (m *recordingMetrics) RequestFailed(method, endpoint string, status int, err error) {
+	m.record("error " + endpoint)
+}
+
+func (m *recordingMetrics) RequestRetried(method, endpoint string, attempt int) {
+	m.record("retry " + endpoint)
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	cases := map[string]string{
+		"/organizations/test/nodes/web1":      "nodes",
+		"/organizations/test/data/users/jdoe": "data",
+		"/organizations/test/":                "/",
+		"/users/jdoe/keys/default":            "users",
+		"/chef/organizations/test/roles":      "roles",
+		"/":                                   "/",
+	}
+	for path, want := range cases {
+		if got := metricsEndpoint(path); got != want {
+			t.Errorf("%s: expected %q, got %q", path, want, got)
+		}
+	}
+}
+
+func TestMetricsTransport_retry(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	opts := &clientOptions{MaxRetries: 1, Metrics: metrics}
+	client := &http.Client{Transport: opts.wrapTransport(http.DefaultTransport)}
+
+	res, err := client.Get(server.URL + "/organizations/test/nodes/web1")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	res.Body.Close()
+
+	want := []string{"start nodes", "end nodes", "error nodes", "retry nodes", "start nodes", "end nodes"}
+	if len(metrics.events) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, metrics.events)
+	}
+	for i := range want {
+		if metrics.events[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, metrics.events)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package provider
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// orgNamePattern matches the short names Chef Server accepts for
+// organizations: lowercase letters, digits, hyphens and underscores, not
+// starting with a hyphen or underscore.
+var orgNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,254}$`)
+
+// orgNameFromURL extracts the organization short name from a server URL of
+// the form https://chef.example.com/organizations/NAME/. An empty name and
+// no error are returned for URLs that point at the root of the server.
+func orgNameFromURL(serverURL string) (string, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return "", err
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, segment := range segments {
+		if segment != "organizations" {
+			continue
+		}
+
+		if i+1 >= len(segments) || segments[i+1] == "" {
+			return "", fmt.Errorf("%s has no organization name after /organizations/", serverURL)
+		}
+		if i+2 < len(segments) {
+			return "", fmt.Errorf("%s has unexpected path segments after the organization name %q", serverURL, segments[i+1])
+		}
+
+		name := segments[i+1]
+		if !orgNamePattern.MatchString(name) {
+			return "", fmt.Errorf("%q is not a valid Chef organization name; names may only contain lowercase letters, "+
+				"digits, hyphens and underscores, and must start with a letter or digit", name)
+		}
+		return name, nil
+	}
+
+	return "", nil
+}
+
+// orgScoped wraps the operations of a resource or data source whose API
+// endpoints live under an organization, so that they fail up front with a
+// clear diagnostic instead of a confusing 404 when server_url points at the
+// root of the server.
+func orgScoped(r *schema.Resource) *schema.Resource {
+	r.CreateContext = requireOrg(r.CreateContext)
+	r.ReadContext = requireOrg(r.ReadContext)
+	r.UpdateContext = requireOrg(r.UpdateContext)
+	r.DeleteContext = requireOrg(r.DeleteContext)
+
+	r.Create = requireOrgLegacy(r.Create)
+	r.Read = requireOrgLegacy(r.Read)
+	r.Update = requireOrgLegacy(r.Update)
+	r.Delete = requireOrgLegacy(r.Delete)
+	return r
+}
+
+func requireOrg(fn func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if fn == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		if diags := meta.(*chefClient).requireOrg(); diags != nil {
+			return diags
+		}
+		return fn(ctx, d, meta)
+	}
+}
+
+func requireOrgLegacy(fn func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+	if fn == nil {
+		return nil
+	}
+
+	return func(d *schema.ResourceData, meta interface{}) error {
+		if diags := meta.(*chefClient).requireOrg(); diags != nil {
+			return fmt.Errorf("%s: %s", diags[0].Summary, diags[0].Detail)
+		}
+		return fn(d, meta)
+	}
+}
+
+// requireOrg returns an error diagnostic when the provider was configured
+// without an organization, for operations on objects that belong to one.
+func (c *chefClient) requireOrg() diag.Diagnostics {
+	if c.Org != "" {
+		return nil
+	}
+	return diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  "Chef organization is not configured",
+			Detail: fmt.Sprintf("The provider server_url %s does not include an organization. "+
+				"Objects of this type belong to an organization, so server_url must be of the form "+
+				"https://chef.example.com/organizations/NAME/.", c.BaseURL),
+		},
+	}
+}
+
+// withOrganization adds an optional organization attribute to a resource
+// whose objects belong to an organization. When it is set, the resource's
+// operations run against that organization instead of the one in the
+// provider's server_url. It must wrap orgScoped, so that the organization
+// is in place before that check runs.
+func withOrganization(r *schema.Resource) *schema.Resource {
+	r.Schema["organization"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ForceNew:     true,
+		Description:  "Organization to manage the object in, overriding the one in the provider's server_url. When unset, the provider's organization is used.",
+		ValidateFunc: validateOrgName,
+	}
+
+	r.CreateContext = inOrganization(r.CreateContext)
+	r.ReadContext = inOrganization(r.ReadContext)
+	r.UpdateContext = inOrganization(r.UpdateContext)
+	r.DeleteContext = inOrganization(r.DeleteContext)
+
+	r.Create = inOrganizationLegacy(r.Create)
+	r.Read = inOrganizationLegacy(r.Read)
+	r.Update = inOrganizationLegacy(r.Update)
+	r.Delete = inOrganizationLegacy(r.Delete)
+
+	if customizeDiff := r.CustomizeDiff; customizeDiff != nil {
+		r.CustomizeDiff = func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+			c, err := meta.(*chefClient).forOrganization(d.Get("organization").(string))
+			if err != nil {
+				return fmt.Errorf("error creating Chef client for organization: %s", err)
+			}
+			return customizeDiff(ctx, d, c)
+		}
+	}
+	return r
+}
+
+func inOrganization(fn func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if fn == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		c, err := meta.(*chefClient).forOrganization(d.Get("organization").(string))
+		if err != nil {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Error creating Chef client for organization",
+					Detail:        fmt.Sprint(err),
+					AttributePath: cty.GetAttrPath("organization"),
+				},
+			}
+		}
+		return fn(ctx, d, c)
+	}
+}
+
+func inOrganizationLegacy(fn func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+	if fn == nil {
+		return nil
+	}
+
+	return func(d *schema.ResourceData, meta interface{}) error {
+		c, err := meta.(*chefClient).forOrganization(d.Get("organization").(string))
+		if err != nil {
+			return fmt.Errorf("error creating Chef client for organization: %s", err)
+		}
+		return fn(d, c)
+	}
+}
+
+// forOrganization returns a client for the organization org that
+// authenticates like c and shares its HTTP transport, so that retries and
+// concurrency limits still apply across the whole provider. A new client is
+// returned rather than c being changed, since resources in different
+// organizations are applied concurrently. Service base path overrides are
+// not carried over.
+func (c *chefClient) forOrganization(org string) (*chefClient, error) {
+	if org == "" || org == c.Org {
+		return c, nil
+	}
+
+	// Global always points at the root of the server.
+	base := *c.Global.BaseURL
+	base.Path = strings.TrimSuffix(base.Path, "/") + "/organizations/" + org + "/"
+
+	client, err := cloneChefClient(c.Global, base.String(), c.Global.IsWebuiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &chefClient{client, c.Global, c.options, org}, nil
+}
+
+// cloneChefClient returns a client for baseURL that signs as src does,
+// apart from isWebuiKey, and shares its HTTP client. go-chef's services
+// hold a pointer back to the client they were created with, so a changed
+// copy of a client has to be built from scratch.
+func cloneChefClient(src *chefc.Client, baseURL string, isWebuiKey bool) (*chefc.Client, error) {
+	config := chefc.Config{
+		Name:    src.Auth.ClientName,
+		Key:     string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(src.Auth.PrivateKey)})),
+		BaseURL: baseURL,
+
+		AuthenticationVersion: src.Auth.AuthenticationVersion,
+		IsWebuiKey:            isWebuiKey,
+	}
+	client, err := chefc.NewClient(&config)
+	if err != nil {
+		return nil, err
+	}
+	*chefHTTPClient(client) = *chefHTTPClient(src)
+	return client, nil
+}
+
+func validateOrgName(val interface{}, key string) (warns []string, errs []error) {
+	if v := val.(string); !orgNamePattern.MatchString(v) {
+		errs = append(errs, fmt.Errorf("%s %q is not a valid Chef organization name; names may only contain lowercase "+
+			"letters, digits, hyphens and underscores, and must start with a letter or digit", key, v))
+	}
+	return
+}
@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	chefc "github.com/go-chef/chef"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestOrgNameFromURL(t *testing.T) {
+	cases := []struct {
+		url     string
+		org     string
+		wantErr bool
+	}{
+		{"https://chef.example.com/organizations/myorg/", "myorg", false},
+		{"https://chef.example.com/organizations/my-org_2", "my-org_2", false},
+		{"https://chef.example.com/chef/organizations/myorg/", "myorg", false},
+		{"https://chef.example.com/", "", false},
+		{"https://chef.example.com/organizations/", "", true},
+		{"https://chef.example.com/organizations/MyOrg/", "", true},
+		{"https://chef.example.com/organizations/-myorg/", "", true},
+		{"https://chef.example.com/organizations/myorg/nodes/", "", true},
+	}
+
+	for _, tc := range cases {
+		org, err := orgNameFromURL(tc.url)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: unexpected error state %v", tc.url, err)
+			continue
+		}
+		if org != tc.org {
+			t.Errorf("%s: expected org %q, got %q", tc.url, tc.org, org)
+		}
+	}
+}
+
+func TestOrgScoped(t *testing.T) {
+	called := false
+	r := orgScoped(&schema.Resource{
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+			called = true
+			return nil
+		},
+	})
+
+	c := testChefClient(t, nil)
+	c.Org = ""
+	if diags := r.ReadContext(context.Background(), nil, c); !diags.HasError() {
+		t.Fatal("expected an error without an organization")
+	}
+	if called {
+		t.Fatal("wrapped function should not run without an organization")
+	}
+
+	c.Org = "test"
+	if diags := r.ReadContext(context.Background(), nil, c); diags.HasError() || !called {
+		t.Fatalf("expected wrapped function to run, got %v", diags)
+	}
+}
+
+func TestOrgScoped_legacy(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	})
+	c.Org = ""
+
+	r := orgScoped(resourceChefRole())
+	d := r.Data(nil)
+	d.SetId("web")
+	d.Set("name", "web")
+	if err := r.Read(d, c); err == nil || !strings.Contains(err.Error(), "Chef organization is not configured") {
+		t.Fatalf("expected an error without an organization, got %v", err)
+	}
+}
+
+func TestWithOrganization(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/nodes/web1"):
+			w.Write([]byte(`{"name":"web1","chef_environment":"_default","run_list":[]}`))
+		case strings.HasSuffix(r.URL.Path, "/roles/web"):
+			w.Write([]byte(`{"name":"web","run_list":[]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	orgClient, err := chefc.NewClient(&config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "organizations/test/")
+	global, err := chefc.NewClient(&config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c := &chefClient{orgClient, global, &clientOptions{}, "test"}
+
+	node := withOrganization(orgScoped(resourceChefNode()))
+	role := withOrganization(orgScoped(resourceChefRole()))
+	read := func(r *schema.Resource, name, org string) {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"name": name, "organization": org})
+		d.SetId(name)
+		if r.ReadContext != nil {
+			if diags := r.ReadContext(context.Background(), d, c); diags.HasError() {
+				t.Errorf("err: %v", diags)
+			}
+		} else if err := r.Read(d, c); err != nil {
+			t.Errorf("err: %s", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, org := range []string{"", "other", "third"} {
+		wg.Add(1)
+		go func(org string) {
+			defer wg.Done()
+			read(node, "web1", org)
+		}(org)
+	}
+	wg.Wait()
+	read(role, "web", "other")
+
+	seen := map[string]bool{}
+	for _, path := range paths {
+		seen[path] = true
+	}
+	for _, expected := range []string{
+		"/organizations/test/nodes/web1",
+		"/organizations/other/nodes/web1",
+		"/organizations/third/nodes/web1",
+		"/organizations/other/roles/web",
+	} {
+		if !seen[expected] {
+			t.Errorf("expected a request to %s, got %v", expected, paths)
+		}
+	}
+	if c.BaseURL.Path != "/organizations/test/" {
+		t.Fatalf("expected the provider client to be left alone, got %s", c.BaseURL)
+	}
+
+	if _, errs := validateOrgName("Not_An_Org", "organization"); len(errs) == 0 {
+		t.Fatal("expected an invalid organization name to be rejected")
+	}
+}
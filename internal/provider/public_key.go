@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// parsePublicKeyPEM parses a PEM-encoded RSA public key in either PKIX
+// ("PUBLIC KEY") or PKCS #1 ("RSA PUBLIC KEY") form.
+func parsePublicKeyPEM(s string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(normalizePEM(s)))
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("expected an RSA public key, got %T", key)
+		}
+		return rsaKey, nil
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("expected a PUBLIC KEY or RSA PUBLIC KEY PEM block, got %s", block.Type)
+	}
+}
+
+// canonicalPublicKeyPEM re-encodes a public key as PKIX PEM, the form the
+// Chef server returns keys in, so that the same key compares equal however
+// it was wrapped, indented or encoded. Values that don't parse are only
+// whitespace-normalized.
+func canonicalPublicKeyPEM(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+
+	key, err := parsePublicKeyPEM(s)
+	if err != nil {
+		return normalizePEM(s)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return normalizePEM(s)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func validatePublicKeyPEM(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := parsePublicKeyPEM(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%s must be a PEM-encoded RSA public key, "+
+			"starting with -----BEGIN PUBLIC KEY----- or -----BEGIN RSA PUBLIC KEY-----: %s", key, err))
+	}
+	return
+}
+
+func publicKeyDiffSuppressFunc(k, old, new string, d *schema.ResourceData) bool {
+	return canonicalPublicKeyPEM(old) == canonicalPublicKeyPEM(new)
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+)
+
+// testPublicKeyPEM returns a freshly generated RSA public key as PKIX PEM.
+func testPublicKeyPEM(t *testing.T) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestCanonicalPublicKeyPEM(t *testing.T) {
+	publicKey := testPublicKeyPEM(t)
+	key, err := parsePublicKeyPEM(publicKey)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	pkcs1 := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(key)}))
+
+	equivalent := []string{
+		publicKey,
+		strings.TrimSpace(publicKey),
+		strings.ReplaceAll(publicKey, "\n", "\r\n"),
+		"\n    " + strings.ReplaceAll(publicKey, "\n", "\n    "),
+		pkcs1,
+	}
+	for _, v := range equivalent {
+		if got := canonicalPublicKeyPEM(v); got != publicKey {
+			t.Errorf("canonicalPublicKeyPEM(%q) = %q, expected %q", v, got, publicKey)
+		}
+	}
+
+	if got := canonicalPublicKeyPEM(""); got != "" {
+		t.Errorf("expected an empty key to stay empty, got %q", got)
+	}
+	if got := canonicalPublicKeyPEM("not a key \r\n"); got != "not a key\n" {
+		t.Errorf("expected an unparseable key to be whitespace-normalized, got %q", got)
+	}
+}
+
+func TestValidatePublicKeyPEM(t *testing.T) {
+	publicKey := testPublicKeyPEM(t)
+	if _, errs := validatePublicKeyPEM(publicKey, "public_key"); len(errs) != 0 {
+		t.Fatalf("expected a valid key to be accepted, got %v", errs)
+	}
+
+	invalid := []string{
+		"",
+		"ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC alice@laptop",
+		strings.Replace(publicKey, "PUBLIC KEY", "PRIVATE KEY", 2),
+		publicKey[:len(publicKey)/2] + "\n-----END PUBLIC KEY-----\n",
+	}
+	for _, v := range invalid {
+		if _, errs := validatePublicKeyPEM(v, "public_key"); len(errs) == 0 {
+			t.Errorf("expected %q to be rejected", v)
+		}
+	}
+}
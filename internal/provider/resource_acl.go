@@ -0,0 +1,261 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// resourceChefACL manages the permissions of a single object. Only the
+// permission blocks given in the configuration are written; the rest are
+// read back from the server so that drift in them is visible.
+func resourceChefACL() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"object_type": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			ValidateFunc: validateACLObjectPath,
+			Description:  "The kind of object, as it appears in the object's API path, such as `nodes`, `roles` or `containers`.",
+		},
+		"object_name": {
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+	}
+	for _, permission := range aclPermissions {
+		s[permission] = &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			Computed: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"actors": {
+						Type:     schema.TypeSet,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"groups": {
+						Type:     schema.TypeSet,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+				},
+			},
+		}
+	}
+
+	return &schema.Resource{
+		CreateContext: CreateACL,
+		UpdateContext: UpdateACL,
+		ReadContext:   ReadACL,
+		DeleteContext: DeleteACL,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: ACLImporter,
+		},
+
+		Schema: s,
+	}
+}
+
+func validateACLObjectPath(val interface{}, key string) (warns []string, errs []error) {
+	var paths []string
+	for _, path := range aclObjectContainers {
+		if val.(string) == path {
+			return
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	errs = append(errs, fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(paths, ", "), val.(string)))
+	return
+}
+
+func CreateACL(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var changed []string
+	for _, permission := range aclPermissions {
+		if len(d.Get(permission).([]interface{})) > 0 {
+			changed = append(changed, permission)
+		}
+	}
+	if diags := applyACL(d, meta, changed); diags != nil {
+		return diags
+	}
+
+	d.SetId(d.Get("object_type").(string) + "/" + d.Get("object_name").(string))
+	return ReadACL(ctx, d, meta)
+}
+
+func UpdateACL(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var changed []string
+	for _, permission := range aclPermissions {
+		if d.HasChange(permission) {
+			changed = append(changed, permission)
+		}
+	}
+	if diags := applyACL(d, meta, changed); diags != nil {
+		return diags
+	}
+
+	return ReadACL(ctx, d, meta)
+}
+
+func ReadACL(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	objectType := d.Get("object_type").(string)
+	name := d.Get("object_name").(string)
+
+	acl, err := getACL(ctx, client.Client, objectType, name)
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag(fmt.Sprintf("Error reading ACL of %s/%s", objectType, name), err, cty.GetAttrPath("object_name"))
+	}
+
+	setACLPermissions(d, acl)
+	return nil
+}
+
+func DeleteACL(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Every object always has an ACL, so there is nothing to delete; the
+	// permissions are left as they were last applied.
+	d.SetId("")
+	return nil
+}
+
+// ACLImporter takes an ID of object_type/object_name, such as
+// nodes/webserver, and reads the object's current permissions so that every
+// permission block is populated straight away.
+func ACLImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	id := d.Id()
+	parts := strings.SplitN(id, "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected object_type/object_name", id)
+	}
+	if _, errs := validateACLObjectPath(parts[0], "object_type"); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	acl, err := getACL(ctx, meta.(*chefClient).Client, parts[0], parts[1])
+	if err != nil {
+		if isChefNotFound(err) {
+			return nil, fmt.Errorf("%s not found", id)
+		}
+		return nil, fmt.Errorf("reading ACL of %s: %s", id, err)
+	}
+
+	d.Set("object_type", parts[0])
+	d.Set("object_name", parts[1])
+	setACLPermissions(d, acl)
+	return []*schema.ResourceData{d}, nil
+}
+
+// aclMembers is one permission as the server returns it. Besides actors,
+// newer servers can list the same users and clients again under users and
+// clients.
+type aclMembers struct {
+	Actors  []string `json:"actors"`
+	Groups  []string `json:"groups"`
+	Users   []string `json:"users"`
+	Clients []string `json:"clients"`
+}
+
+// getACL reads an object's permissions, folding users and clients into
+// actors so that each member is listed once, under actors or groups.
+func getACL(ctx context.Context, client *chefc.Client, objectType, name string) (map[string]chefc.ACLitems, error) {
+	req, err := newRequestWithContext(ctx, client, "GET", objectType+"/"+name+"/_acl", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]aclMembers
+	if _, err := client.Do(req, &raw); err != nil {
+		return nil, err
+	}
+
+	acl := make(map[string]chefc.ACLitems, len(raw))
+	for permission, members := range raw {
+		var actors []string
+		actors = append(actors, members.Actors...)
+		actors = append(actors, members.Users...)
+		actors = append(actors, members.Clients...)
+		acl[permission] = chefc.ACLitems{
+			Actors: uniqueSortedStrings(actors),
+			Groups: uniqueSortedStrings(members.Groups),
+		}
+	}
+	return acl, nil
+}
+
+// setACLPermissions stores every permission block, so that members added
+// outside Terraform show up as drift.
+func setACLPermissions(d *schema.ResourceData, acl map[string]chefc.ACLitems) {
+	for _, permission := range aclPermissions {
+		items := acl[permission]
+		d.Set(permission, []interface{}{
+			map[string]interface{}{
+				"actors": []string(items.Actors),
+				"groups": []string(items.Groups),
+			},
+		})
+	}
+}
+
+// uniqueSortedStrings returns values sorted with duplicates removed, never
+// nil.
+func uniqueSortedStrings(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !containsString(result, v) {
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// applyACL writes each of the given permissions. The server replaces a
+// permission's actors and groups wholesale, so the complete lists are
+// always sent; the permissions not given keep their current value.
+func applyACL(d *schema.ResourceData, meta interface{}, permissions []string) diag.Diagnostics {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	client := meta.(*chefClient)
+	objectType := d.Get("object_type").(string)
+	name := d.Get("object_name").(string)
+
+	// Reading the ACL first reports a missing object once, before any
+	// permission has been written.
+	if _, err := client.ACLs.Get(objectType, name); err != nil {
+		return chefErrToDiag(fmt.Sprintf("Error reading ACL of %s/%s", objectType, name), err, cty.GetAttrPath("object_name"))
+	}
+
+	for _, permission := range permissions {
+		// Empty lists must be sent as [] rather than null.
+		items := chefc.ACLitems{Actors: chefc.ACLitem{}, Groups: chefc.ACLitem{}}
+		if block := d.Get(permission).([]interface{}); len(block) > 0 && block[0] != nil {
+			m := block[0].(map[string]interface{})
+			items.Actors = append(items.Actors, sortedSetStrings(m["actors"].(*schema.Set))...)
+			items.Groups = append(items.Groups, sortedSetStrings(m["groups"].(*schema.Set))...)
+		}
+		if err := client.ACLs.Put(objectType, name, permission, &chefc.ACL{permission: items}); err != nil {
+			return chefErrToDiag(fmt.Sprintf("Error updating %s permission of %s/%s", permission, objectType, name), err, cty.GetAttrPath(permission))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// aclObjectContainers maps each object type whose ACL can be reset to the
+// container its default ACL comes from, which is also the path segment
+// the object's own ACL lives under.
+var aclObjectContainers = map[string]string{
+	"client":       "clients",
+	"container":    "containers",
+	"cookbook":     "cookbooks",
+	"data_bag":     "data",
+	"environment":  "environments",
+	"group":        "groups",
+	"node":         "nodes",
+	"policy":       "policies",
+	"policy_group": "policy_groups",
+	"role":         "roles",
+}
+
+// resourceChefACLInheritance keeps an object's ACL identical to the
+// default ACL of its container, undoing any permissions granted or removed
+// on the object by hand. Chef builds a new object's ACL from that default
+// plus the actor that created it, and the creator is not kept. The one
+// exception is the object's own client: a node or client keeps the client
+// of the same name wherever it has a permission, since chef-client
+// authenticates as that client and would otherwise be refused its own node.
+func resourceChefACLInheritance() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateACLInheritance,
+		ReadContext:   ReadACLInheritance,
+		DeleteContext: DeleteACLInheritance,
+		CustomizeDiff: diffACLInheritance,
+
+		Schema: map[string]*schema.Schema{
+			"object_type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateACLObjectType,
+			},
+			"object_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the object. A node or client keeps the permissions held by the client of the same name, which chef-client authenticates as; every other actor not in the container's default ACL, including the one that created the object, is removed.",
+			},
+			"drifted_permissions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Permissions on the object that differ from the container's default ACL.",
+			},
+		},
+	}
+}
+
+func validateACLObjectType(val interface{}, key string) (warns []string, errs []error) {
+	if _, ok := aclObjectContainers[val.(string)]; !ok {
+		var types []string
+		for t := range aclObjectContainers {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		errs = append(errs, fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(types, ", "), val.(string)))
+	}
+	return
+}
+
+func CreateACLInheritance(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := applyACLInheritance(d, meta); diags != nil {
+		return diags
+	}
+
+	d.SetId(d.Get("object_type").(string) + "/" + d.Get("object_name").(string))
+	return ReadACLInheritance(ctx, d, meta)
+}
+
+func ReadACLInheritance(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	container := aclObjectContainers[d.Get("object_type").(string)]
+
+	defaults, err := client.ACLs.Get("containers", container)
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Error reading container ACL",
+				Detail:        fmt.Sprintf("%s: %s", container, err),
+				AttributePath: cty.GetAttrPath("object_type"),
+			},
+		}
+	}
+
+	acl, err := client.ACLs.Get(container, d.Get("object_name").(string))
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading object ACL", err, cty.GetAttrPath("object_name"))
+	}
+
+	want := inheritedACL(d.Get("object_type").(string), d.Get("object_name").(string), defaults, acl)
+	d.Set("drifted_permissions", driftedACLPermissions(want, acl))
+	return nil
+}
+
+func DeleteACLInheritance(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// The object keeps the ACL it inherited; there is nothing to undo.
+	d.SetId("")
+	return nil
+}
+
+// diffACLInheritance plans to reapply the container's default whenever the
+// object's ACL has drifted from it. Since deleting leaves the ACL alone,
+// replacement amounts to resetting it again.
+func diffACLInheritance(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || len(d.Get("drifted_permissions").([]interface{})) == 0 {
+		return nil
+	}
+	if err := d.SetNew("drifted_permissions", []string{}); err != nil {
+		return err
+	}
+	return d.ForceNew("drifted_permissions")
+}
+
+// driftedACLPermissions returns the permissions, in order, whose actors or
+// groups differ between the container defaults and an object's ACL.
+func driftedACLPermissions(defaults, acl chefc.ACL) []string {
+	drifted := make([]string, 0)
+	for _, permission := range aclPermissions {
+		want, got := defaults[permission], acl[permission]
+		if !sameACLItem(want.Actors, got.Actors) || !sameACLItem(want.Groups, got.Groups) {
+			drifted = append(drifted, permission)
+		}
+	}
+	return drifted
+}
+
+// inheritedACL returns the ACL an object should have: its container's
+// defaults, plus, for a node or client, the client of the same name in
+// each permission it currently holds.
+func inheritedACL(objectType, name string, defaults, acl chefc.ACL) chefc.ACL {
+	keepsOwnClient := objectType == "node" || objectType == "client"
+
+	want := make(chefc.ACL, len(defaults))
+	for permission, items := range defaults {
+		// Empty lists must be sent as [] rather than null.
+		actors := append(chefc.ACLitem{}, items.Actors...)
+		if keepsOwnClient && containsString(acl[permission].Actors, name) && !containsString(actors, name) {
+			actors = append(actors, name)
+		}
+		want[permission] = chefc.ACLitems{Actors: actors, Groups: append(chefc.ACLitem{}, items.Groups...)}
+	}
+	return want
+}
+
+func sameACLItem(a, b chefc.ACLitem) bool {
+	x := append([]string{}, a...)
+	y := append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	return reflect.DeepEqual(x, y)
+}
+
+// applyACLInheritance copies every permission of the container's default
+// ACL onto the object, keeping a node's or client's own client.
+func applyACLInheritance(d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	container := aclObjectContainers[d.Get("object_type").(string)]
+	name := d.Get("object_name").(string)
+
+	defaults, err := client.ACLs.Get("containers", container)
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Error reading container ACL",
+				Detail:        fmt.Sprintf("%s: %s", container, err),
+				AttributePath: cty.GetAttrPath("object_type"),
+			},
+		}
+	}
+
+	current, err := client.ACLs.Get(container, name)
+	if err != nil {
+		return chefErrToDiag("Error reading object ACL", err, cty.GetAttrPath("object_name"))
+	}
+	want := inheritedACL(d.Get("object_type").(string), name, defaults, current)
+
+	for _, permission := range aclPermissions {
+		items, ok := want[permission]
+		if !ok {
+			continue
+		}
+		acl := chefc.NewACL(permission, items.Actors, items.Groups)
+		if err := client.ACLs.Put(container, name, permission, acl); err != nil {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Error resetting object ACL",
+					Detail:        fmt.Sprintf("%s/%s %s: %s", container, name, permission, err),
+					AttributePath: cty.GetAttrPath("object_name"),
+				},
+			}
+		}
+	}
+
+	return nil
+}
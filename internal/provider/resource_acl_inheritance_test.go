@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestDriftedACLPermissions(t *testing.T) {
+	defaults := chefc.ACL{
+		"read":   {Actors: chefc.ACLitem{"pivotal"}, Groups: chefc.ACLitem{"admins", "users"}},
+		"update": {Actors: chefc.ACLitem{"pivotal"}, Groups: chefc.ACLitem{"admins"}},
+	}
+	acl := chefc.ACL{
+		"read":   {Actors: chefc.ACLitem{"pivotal"}, Groups: chefc.ACLitem{"users", "admins"}},
+		"update": {Actors: chefc.ACLitem{"pivotal", "mallory"}, Groups: chefc.ACLitem{"admins"}},
+	}
+
+	if got := driftedACLPermissions(defaults, acl); len(got) != 1 || got[0] != "update" {
+		t.Fatalf("expected only update to have drifted, got %v", got)
+	}
+}
+
+func TestCreateACLInheritance(t *testing.T) {
+	defaults := chefc.ACL{
+		"read":   {Actors: chefc.ACLitem{"pivotal"}, Groups: chefc.ACLitem{"admins", "users"}},
+		"update": {Actors: chefc.ACLitem{"pivotal"}, Groups: chefc.ACLitem{"admins"}},
+	}
+	object := chefc.ACL{
+		"read":   {Actors: chefc.ACLitem{"pivotal"}, Groups: chefc.ACLitem{"admins", "users"}},
+		"update": {Actors: chefc.ACLitem{"pivotal", "mallory"}, Groups: chefc.ACLitem{"admins", "users"}},
+	}
+
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/organizations/test/containers/nodes/_acl":
+			json.NewEncoder(w).Encode(defaults)
+		case r.Method == "GET" && r.URL.Path == "/organizations/test/nodes/web1/_acl":
+			json.NewEncoder(w).Encode(object)
+		case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, "/organizations/test/nodes/web1/_acl/"):
+			var acl chefc.ACL
+			json.NewDecoder(r.Body).Decode(&acl)
+			for permission, items := range acl {
+				object[permission] = items
+			}
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefACLInheritance().Schema, map[string]interface{}{
+		"object_type": "node",
+		"object_name": "web1",
+	})
+	if diags := CreateACLInheritance(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if got := object["update"].Actors; len(got) != 1 || got[0] != "pivotal" {
+		t.Fatalf("expected update to be reset to the container default, got %v", got)
+	}
+	if drifted := d.Get("drifted_permissions").([]interface{}); len(drifted) != 0 {
+		t.Fatalf("expected no drift after reset, got %v", drifted)
+	}
+}
+
+func TestCreateACLInheritance_keepsOwnClient(t *testing.T) {
+	defaults := chefc.ACL{
+		"read":   {Actors: chefc.ACLitem{"pivotal"}, Groups: chefc.ACLitem{"admins", "clients"}},
+		"update": {Actors: chefc.ACLitem{"pivotal"}, Groups: chefc.ACLitem{"admins"}},
+	}
+	object := chefc.ACL{
+		"read":   {Actors: chefc.ACLitem{"pivotal", "web1", "alice"}, Groups: chefc.ACLitem{"admins", "clients"}},
+		"update": {Actors: chefc.ACLitem{"pivotal", "web1", "alice"}, Groups: chefc.ACLitem{"admins"}},
+	}
+
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/organizations/test/containers/nodes/_acl":
+			json.NewEncoder(w).Encode(defaults)
+		case r.Method == "GET" && r.URL.Path == "/organizations/test/nodes/web1/_acl":
+			json.NewEncoder(w).Encode(object)
+		case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, "/organizations/test/nodes/web1/_acl/"):
+			var acl chefc.ACL
+			json.NewDecoder(r.Body).Decode(&acl)
+			for permission, items := range acl {
+				object[permission] = items
+			}
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefACLInheritance().Schema, map[string]interface{}{
+		"object_type": "node",
+		"object_name": "web1",
+	})
+	if diags := CreateACLInheritance(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	for _, permission := range []string{"read", "update"} {
+		if got := object[permission].Actors; !reflect.DeepEqual([]string(got), []string{"pivotal", "web1"}) {
+			t.Fatalf("expected %s to keep the node's own client and drop the creator, got %v", permission, got)
+		}
+	}
+	if drifted := d.Get("drifted_permissions").([]interface{}); len(drifted) != 0 {
+		t.Fatalf("expected no drift after reset, got %v", drifted)
+	}
+}
@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestCreateACL(t *testing.T) {
+	acl := chefc.ACL{}
+	for _, permission := range aclPermissions {
+		acl[permission] = chefc.ACLitems{Actors: chefc.ACLitem{"pivotal"}, Groups: chefc.ACLitem{"admins"}}
+	}
+	var puts []string
+
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		const prefix = "/organizations/test/nodes/web1/_acl"
+		switch {
+		case r.Method == "GET" && r.URL.Path == prefix:
+			json.NewEncoder(w).Encode(acl)
+		case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, prefix+"/"):
+			permission := strings.TrimPrefix(r.URL.Path, prefix+"/")
+			var body map[string]json.RawMessage
+			json.NewDecoder(r.Body).Decode(&body)
+			var items chefc.ACLitems
+			json.Unmarshal(body[permission], &items)
+			acl[permission] = items
+			puts = append(puts, fmt.Sprintf("%s=%s", permission, body[permission]))
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefACL().Schema, map[string]interface{}{
+		"object_type": "nodes",
+		"object_name": "web1",
+		"update": []interface{}{
+			map[string]interface{}{
+				"groups": []interface{}{"operators", "admins"},
+			},
+		},
+	})
+	if diags := CreateACL(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if d.Id() != "nodes/web1" {
+		t.Fatalf("expected id nodes/web1, got %q", d.Id())
+	}
+	if got := fmt.Sprint(puts); got != `[update={"groups":["admins","operators"],"actors":[]}]` {
+		t.Fatalf("expected only the configured permission to be written in full, got %s", got)
+	}
+	if got := d.Get("read.0.actors").(*schema.Set).List(); len(got) != 1 || got[0] != "pivotal" {
+		t.Fatalf("expected unmanaged permissions to be read back, got %v", got)
+	}
+}
+
+func TestACLImporter(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/organizations/test/nodes/webserver/_acl" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":["not found"]}`))
+			return
+		}
+		// The same user and client are listed under actors and again
+		// under users and clients.
+		w.Write([]byte(`{
+			"create": {"actors": ["pivotal", "webserver"], "users": ["pivotal"], "clients": ["webserver"], "groups": ["admins"]},
+			"read":   {"actors": [], "users": ["alice"], "clients": [], "groups": ["users", "admins", "users"]},
+			"update": {"actors": ["pivotal"], "groups": ["admins"]},
+			"delete": {"actors": ["pivotal"], "groups": ["admins"]},
+			"grant":  {"actors": ["pivotal"], "groups": ["admins"]}
+		}`))
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefACL().Schema, map[string]interface{}{})
+	d.SetId("nodes/webserver")
+	if _, err := ACLImporter(context.Background(), d, c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if d.Get("object_type") != "nodes" || d.Get("object_name") != "webserver" {
+		t.Fatalf("unexpected object %v/%v", d.Get("object_type"), d.Get("object_name"))
+	}
+	for attr, want := range map[string]string{
+		"create.0.actors": "[pivotal webserver]",
+		"create.0.groups": "[admins]",
+		"read.0.actors":   "[alice]",
+		"read.0.groups":   "[admins users]",
+		"grant.0.actors":  "[pivotal]",
+	} {
+		if got := fmt.Sprint(sortedSetStrings(d.Get(attr).(*schema.Set))); got != want {
+			t.Errorf("%s: expected %s, got %s", attr, want, got)
+		}
+	}
+
+	for _, id := range []string{"nodes/missing", "nodes", "widgets/webserver"} {
+		d := schema.TestResourceDataRaw(t, resourceChefACL().Schema, map[string]interface{}{})
+		d.SetId(id)
+		if _, err := ACLImporter(context.Background(), d, c); err == nil {
+			t.Errorf("%s: expected an error", id)
+		}
+	}
+}
+
+func TestReadACL_drift(t *testing.T) {
+	acl := chefc.ACL{}
+	for _, permission := range aclPermissions {
+		acl[permission] = chefc.ACLitems{Actors: chefc.ACLitem{"pivotal"}, Groups: chefc.ACLitem{"admins"}}
+	}
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(acl)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefACL().Schema, map[string]interface{}{
+		"object_type": "nodes",
+		"object_name": "web1",
+		"update": []interface{}{
+			map[string]interface{}{
+				"actors": []interface{}{"pivotal"},
+				"groups": []interface{}{"admins"},
+			},
+		},
+	})
+	d.SetId("nodes/web1")
+
+	// Someone grants update outside Terraform.
+	acl["update"] = chefc.ACLitems{Actors: chefc.ACLitem{"pivotal", "mallory"}, Groups: chefc.ACLitem{"admins", "contractors"}}
+	if diags := ReadACL(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if got := fmt.Sprint(sortedSetStrings(d.Get("update.0.actors").(*schema.Set))); got != "[mallory pivotal]" {
+		t.Fatalf("expected the added actor in state, got %s", got)
+	}
+	if got := fmt.Sprint(sortedSetStrings(d.Get("update.0.groups").(*schema.Set))); got != "[admins contractors]" {
+		t.Fatalf("expected the added group in state, got %s", got)
+	}
+}
@@ -1,6 +1,10 @@
 package provider
 
 import (
+	"context"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	chefc "github.com/go-chef/chef"
@@ -8,10 +12,10 @@ import (
 
 func resourceChefClient() *schema.Resource {
 	return &schema.Resource{
-		Create: CreateClient,
-		Update: UpdateClient,
-		Read:   ReadClient,
-		Delete: DeleteClient,
+		CreateContext: CreateClient,
+		UpdateContext: UpdateClient,
+		ReadContext:   ReadClient,
+		DeleteContext: DeleteClient,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -24,58 +28,64 @@ func resourceChefClient() *schema.Resource {
 				Optional: true,
 				Default:  false,
 			},
+			"create_key": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				ForceNew:    true,
+				Description: "If set, the Chef server generates a default key pair for the client when it is created.",
+			},
+			"private_key": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Private key generated by the Chef server when create_key is set. The server only returns it when the client is created.",
+			},
 		},
 	}
 }
 
-func CreateClient(d *schema.ResourceData, meta interface{}) error {
+func CreateClient(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*chefClient)
 
-	client, err := clientFromResourceData(d)
+	client := clientFromResourceData(d)
+	result, err := c.Clients.Create(*client)
 	if err != nil {
-		return err
-	}
-
-	if _, err := c.Clients.Create(*client); err != nil {
-		return err
+		return chefErrToDiag("Error creating client", err, cty.GetAttrPath("name"))
 	}
 
 	d.SetId(client.Name)
-	return ReadClient(d, meta)
+	// private_key is never refreshed: the server only returns it once.
+	d.Set("private_key", result.ChefKey.PrivateKey)
+	return ReadClient(ctx, d, meta)
 }
 
-func UpdateClient(d *schema.ResourceData, meta interface{}) error {
+func UpdateClient(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*chefClient)
 
-	client, err := clientFromResourceData(d)
-	if err != nil {
-		return err
-	}
-
-	_, err = c.Clients.Update(client.Name, *client)
-	if err != nil {
-		return err
+	client := clientFromResourceData(d)
+	// create_key is only accepted when the client is created.
+	client.CreateKey = false
+	if _, err := c.Clients.Update(client.Name, *client); err != nil {
+		return chefErrToDiag("Error updating client", err, cty.GetAttrPath("name"))
 	}
 
 	d.SetId(client.Name)
-	return ReadClient(d, meta)
+	return ReadClient(ctx, d, meta)
 }
 
-func ReadClient(d *schema.ResourceData, meta interface{}) error {
+func ReadClient(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*chefClient)
 
 	name := d.Id()
 
 	client, err := c.Clients.Get(name)
 	if err != nil {
-		if errRes, ok := err.(*chefc.ErrorResponse); ok {
-			if errRes.Response.StatusCode == 404 {
-				d.SetId("")
-				return nil
-			}
-		} else {
-			return err
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
 		}
+		return chefErrToDiag("Error reading client", err, cty.GetAttrPath("name"))
 	}
 
 	d.Set("name", client.Name)
@@ -84,24 +94,21 @@ func ReadClient(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func DeleteClient(d *schema.ResourceData, meta interface{}) error {
+func DeleteClient(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*chefClient)
 
-	name := d.Id()
-	err := c.Clients.Delete(name)
-
-	if err == nil {
-		d.SetId("")
+	if err := c.Clients.Delete(d.Id()); err != nil {
+		return chefErrToDiag("Error deleting client", err, cty.GetAttrPath("name"))
 	}
 
-	return err
+	d.SetId("")
+	return nil
 }
 
-func clientFromResourceData(d *schema.ResourceData) (*chefc.ApiNewClient, error) {
-	client := &chefc.ApiNewClient{
+func clientFromResourceData(d *schema.ResourceData) *chefc.ApiNewClient {
+	return &chefc.ApiNewClient{
 		Name:      d.Get("name").(string),
 		Validator: d.Get("validator").(bool),
-		CreateKey: false,
+		CreateKey: d.Get("create_key").(bool),
 	}
-	return client, nil
 }
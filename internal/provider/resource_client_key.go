@@ -29,9 +29,15 @@ func resourceChefClientKey() *schema.Resource {
 				Optional: true,
 				Default:  "default",
 			},
-			"public_key": {
+			"expiration_date": {
 				Type:     schema.TypeString,
-				Required: true,
+				Computed: true,
+			},
+			"public_key": {
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: publicKeyDiffSuppressFunc,
+				ValidateFunc:     validatePublicKeyPEM,
 			},
 		},
 	}
@@ -50,15 +56,8 @@ func CreateClientKey(ctx context.Context, d *schema.ResourceData, meta interface
 		return err
 	}
 
-	if _, err := c.Clients.AddKey(key.Client, key.Key); err != nil {
-		return diag.Diagnostics{
-			{
-				Severity:      diag.Error,
-				Summary:       "Error creating client key",
-				Detail:        fmt.Sprint(err),
-				AttributePath: cty.GetAttrPath("key_name"),
-			},
-		}
+	if err := addAccessKey(ctx, c.Client, "clients/"+key.Client, key.Key); err != nil {
+		return chefErrToDiag("Error creating client key", err, cty.GetAttrPath("key_name"))
 	}
 
 	d.SetId(key.Client + "+" + key.Key.Name)
@@ -73,15 +72,8 @@ func UpdateClientKey(ctx context.Context, d *schema.ResourceData, meta interface
 		return err
 	}
 
-	if _, err := c.Clients.UpdateKey(key.Client, key.Key.Name, key.Key); err != nil {
-		return diag.Diagnostics{
-			{
-				Severity:      diag.Error,
-				Summary:       "Error updating client key",
-				Detail:        fmt.Sprint(err),
-				AttributePath: cty.GetAttrPath("key_name"),
-			},
-		}
+	if err := updateAccessKey(ctx, c.Client, "clients/"+key.Client+"/keys/"+key.Key.Name, key.Key); err != nil {
+		return chefErrToDiag("Error updating client key", err, cty.GetAttrPath("key_name"))
 	}
 
 	d.SetId(key.Client + "+" + key.Key.Name)
@@ -96,25 +88,17 @@ func ReadClientKey(ctx context.Context, d *schema.ResourceData, meta interface{}
 		return err
 	}
 
-	if k, err := c.Clients.GetKey(key.Client, key.Key.Name); err == nil {
+	if k, err := getAccessKey(ctx, c.Client, fmt.Sprintf("clients/%s/keys/%s", key.Client, key.Key.Name)); err == nil {
 		d.Set("client", key.Client)
 		d.Set("key_name", k.Name)
-		d.Set("public_key", k.PublicKey)
+		d.Set("public_key", canonicalPublicKeyPEM(k.PublicKey))
+		d.Set("expiration_date", k.ExpirationDate.String())
 	} else {
-		if errRes, ok := err.(*chefc.ErrorResponse); ok {
-			if errRes.Response.StatusCode == 404 {
-				d.SetId("")
-			}
-		} else {
-			return diag.Diagnostics{
-				{
-					Severity:      diag.Error,
-					Summary:       "Error reading client key",
-					Detail:        fmt.Sprint(err),
-					AttributePath: cty.GetAttrPath("key_name"),
-				},
-			}
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
 		}
+		return chefErrToDiag("Error reading client key", err, cty.GetAttrPath("key_name"))
 	}
 	return nil
 }
@@ -126,18 +110,11 @@ func DeleteClientKey(ctx context.Context, d *schema.ResourceData, meta interface
 	if err != nil {
 		return err
 	}
-	if _, err := c.Clients.DeleteKey(key.Client, key.Key.Name); err == nil {
+	if err := deleteAccessKey(ctx, c.Client, "clients/"+key.Client+"/keys/"+key.Key.Name); err == nil {
 		d.SetId("")
 		return nil
 	} else {
-		return diag.Diagnostics{
-			{
-				Severity:      diag.Error,
-				Summary:       "Error deleting client key",
-				Detail:        fmt.Sprint(err),
-				AttributePath: cty.GetAttrPath("key_name"),
-			},
-		}
+		return chefErrToDiag("Error deleting client key", err, cty.GetAttrPath("key_name"))
 	}
 }
 
@@ -146,7 +123,7 @@ func clientKeyFromResourceData(d *schema.ResourceData) (*chefClientKey, diag.Dia
 		Client: d.Get("client").(string),
 		Key: chefc.AccessKey{
 			Name:           d.Get("key_name").(string),
-			PublicKey:      d.Get("public_key").(string),
+			PublicKey:      canonicalPublicKeyPEM(d.Get("public_key").(string)),
 			ExpirationDate: "infinity",
 		},
 	}
@@ -1,12 +1,17 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
 	chefc "github.com/go-chef/chef"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -39,6 +44,41 @@ func TestAccClient_basic(t *testing.T) {
 	})
 }
 
+func TestCreateClientKey(t *testing.T) {
+	var created chefc.ApiNewClient
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/organizations/test/clients":
+			json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(chefc.ApiClientCreateResult{
+				Uri:     "https://chef/organizations/test/clients/ci",
+				ChefKey: chefc.ChefKey{Name: "default", PrivateKey: "PRIVATE"},
+			})
+		case r.Method == "GET" && r.URL.Path == "/organizations/test/clients/ci":
+			json.NewEncoder(w).Encode(chefc.ApiClient{Name: "ci"})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefClient().Schema, map[string]interface{}{
+		"name":       "ci",
+		"create_key": true,
+	})
+	if diags := CreateClient(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if !created.CreateKey {
+		t.Fatal("expected create_key to be sent to the server")
+	}
+	if got := d.Get("private_key").(string); got != "PRIVATE" {
+		t.Fatalf("expected the generated private key in state, got %q", got)
+	}
+}
+
 func testAccClientCheckExists(rn string, client *chefc.ApiNewClient) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[rn]
@@ -87,3 +127,21 @@ resource "chef_client" "test" {
   validator = true
 }
 `
+
+func TestReadClient_error(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":["missing read permission"]}`))
+	})
+
+	d := resourceChefClient().Data(nil)
+	d.SetId("ci")
+	diags := ReadClient(context.Background(), d, c)
+	if !diags.HasError() || d.Id() != "ci" {
+		t.Fatalf("expected a 403 to be reported and the client kept in state, got id=%q %v", d.Id(), diags)
+	}
+	if !strings.Contains(diags[0].Summary, "403") || !strings.Contains(diags[0].Detail, "missing read permission") {
+		t.Fatalf("expected the status and server message in the diagnostic, got %#v", diags[0])
+	}
+}
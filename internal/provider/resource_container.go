@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func resourceChefContainer() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateContainer,
+		ReadContext:   ReadContainer,
+		DeleteContext: DeleteContainer,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"container_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"container_path": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateContainer(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+	name := d.Get("container_name").(string)
+
+	// Containers live within the organization, so they are managed through
+	// the organization client rather than the server-level one.
+	if _, err := c.Containers.Create(chefc.Container{ContainerName: name, ContainerPath: name}); err != nil {
+		return chefErrToDiag("Error creating container", err, cty.GetAttrPath("container_name"))
+	}
+
+	d.SetId(name)
+	return ReadContainer(ctx, d, meta)
+}
+
+func ReadContainer(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	container, err := c.Containers.Get(d.Id())
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading container", err, cty.GetAttrPath("container_name"))
+	}
+
+	d.SetId(container.ContainerName)
+	d.Set("container_name", container.ContainerName)
+	d.Set("container_path", container.ContainerPath)
+	return nil
+}
+
+func DeleteContainer(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	if err := c.Containers.Delete(d.Id()); err != nil && !isChefNotFound(err) {
+		return chefErrToDiag("Error deleting container", err, cty.GetAttrPath("container_name"))
+	}
+
+	d.SetId("")
+	return nil
+}
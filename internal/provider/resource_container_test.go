@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestContainerLifecycle(t *testing.T) {
+	containers := make(map[string]chefc.Container)
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/organizations/test/containers":
+			var container chefc.Container
+			json.NewDecoder(r.Body).Decode(&container)
+			containers[container.ContainerName] = container
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"uri":"https://chef/organizations/test/containers/scoped"}`))
+		case r.URL.Path == "/organizations/test/containers/scoped":
+			container, ok := containers["scoped"]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":["not found"]}`))
+				return
+			}
+			if r.Method == "DELETE" {
+				delete(containers, "scoped")
+			}
+			json.NewEncoder(w).Encode(container)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefContainer().Schema, map[string]interface{}{
+		"container_name": "scoped",
+	})
+	if diags := CreateContainer(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "scoped" || d.Get("container_path").(string) != "scoped" {
+		t.Fatalf("unexpected state id=%q path=%q", d.Id(), d.Get("container_path"))
+	}
+
+	if diags := DeleteContainer(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	d.SetId("scoped")
+	if diags := ReadContainer(context.Background(), d, c); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected a deleted container to be removed from state, got id=%q %v", d.Id(), diags)
+	}
+}
+
+func TestReadContainer_error(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":["missing read permission"]}`))
+	})
+
+	d := resourceChefContainer().Data(nil)
+	d.SetId("scoped")
+	if diags := ReadContainer(context.Background(), d, c); !diags.HasError() || d.Id() != "scoped" {
+		t.Fatalf("expected a 403 to be reported and the container kept in state, got id=%q %v", d.Id(), diags)
+	}
+}
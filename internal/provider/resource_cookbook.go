@@ -0,0 +1,461 @@
+package provider
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// cookbookUploadBatchSize is how many files go into each sandbox. Every
+// sandbox is committed once its files are uploaded, and the server doesn't
+// ask for committed files again, so an upload that fails part way only
+// repeats its last batch when retried.
+const cookbookUploadBatchSize = 50
+
+// cookbookSegmentDirs are the top-level directories whose files belong to
+// a manifest segment of the same name. Other top-level files are root_files.
+var cookbookSegmentDirs = map[string]bool{
+	"attributes":  true,
+	"definitions": true,
+	"files":       true,
+	"libraries":   true,
+	"providers":   true,
+	"recipes":     true,
+	"resources":   true,
+	"templates":   true,
+}
+
+// resourceChefCookbook uploads a cookbook version from a local directory,
+// the way knife cookbook upload does.
+func resourceChefCookbook() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateCookbook,
+		UpdateContext: UpdateCookbook,
+		ReadContext:   ReadCookbook,
+		DeleteContext: DeleteCookbook,
+		CustomizeDiff: diffCookbook,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"version": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"path": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Local directory containing the cookbook, with a metadata.json or metadata.rb whose name and version match. Files and directories whose names start with a dot are not uploaded.",
+			},
+			"concurrency": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     4,
+				Description: "Number of files uploaded at once.",
+			},
+			"checksums": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "MD5 checksum of each file in the cookbook, by path. A change to any local file shows up here and uploads the cookbook again.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func CreateCookbook(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := uploadCookbook(ctx, d, meta.(*chefClient)); diags != nil {
+		return diags
+	}
+
+	d.SetId(d.Get("name").(string) + "/" + d.Get("version").(string))
+	return ReadCookbook(ctx, d, meta)
+}
+
+func UpdateCookbook(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := uploadCookbook(ctx, d, meta.(*chefClient)); diags != nil {
+		return diags
+	}
+	return ReadCookbook(ctx, d, meta)
+}
+
+func ReadCookbook(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	name, version := cookbookFromResourceData(d)
+	cookbook, err := client.Cookbooks.GetVersion(name, version)
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading cookbook", err, nil)
+	}
+
+	_, checksums := flattenCookbookManifest(&cookbook)
+	d.Set("name", name)
+	d.Set("version", version)
+	d.Set("checksums", checksums)
+
+	return nil
+}
+
+func DeleteCookbook(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	name, version := cookbookFromResourceData(d)
+	if err := client.Cookbooks.Delete(name, version); err != nil && !isChefNotFound(err) {
+		return chefErrToDiag("Error deleting cookbook", err, nil)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// cookbookFromResourceData returns the cookbook and version an ID refers
+// to. IDs are name/version.
+func cookbookFromResourceData(d *schema.ResourceData) (string, string) {
+	if parts := strings.SplitN(d.Id(), "/", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return d.Get("name").(string), d.Get("version").(string)
+}
+
+// diffCookbook reads the cookbook directory at plan time, checking its
+// metadata against name and version and planning an upload whenever a
+// file differs from what was uploaded.
+func diffCookbook(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("path") {
+		return nil
+	}
+	dir := d.Get("path").(string)
+
+	metadata, err := readCookbookMetadata(dir)
+	if err != nil {
+		return err
+	}
+	if d.NewValueKnown("name") && metadata["name"] != d.Get("name").(string) {
+		return fmt.Errorf("cookbook in %s is named %v, not %s", dir, metadata["name"], d.Get("name"))
+	}
+	if d.NewValueKnown("version") && metadata["version"] != d.Get("version").(string) {
+		return fmt.Errorf("cookbook in %s is version %v, not %s", dir, metadata["version"], d.Get("version"))
+	}
+
+	files, err := readCookbookDir(dir)
+	if err != nil {
+		return err
+	}
+	checksums := make(map[string]interface{}, len(files))
+	for _, f := range files {
+		checksums[f.path] = f.checksum
+	}
+	if reflect.DeepEqual(d.Get("checksums").(map[string]interface{}), checksums) {
+		return nil
+	}
+	return d.SetNew("checksums", checksums)
+}
+
+// cookbookFile is a file of a local cookbook. path is relative to the
+// cookbook and slash-separated, as in the manifest.
+type cookbookFile struct {
+	path     string
+	fullPath string
+	checksum string
+}
+
+// readCookbookDir lists and checksums the files of the cookbook in dir,
+// skipping anything whose name starts with a dot, such as .git.
+func readCookbookDir(dir string) ([]cookbookFile, error) {
+	var files []cookbookFile
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != dir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if parts := strings.SplitN(rel, "/", 2); len(parts) == 2 && !cookbookSegmentDirs[parts[0]] {
+			return nil
+		}
+
+		checksum, err := fileChecksum(p)
+		if err != nil {
+			return err
+		}
+		files = append(files, cookbookFile{path: rel, fullPath: p, checksum: checksum})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading cookbook: %w", err)
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, nil
+}
+
+// readCookbookMetadata returns the cookbook's metadata. metadata.json is
+// sent as it is; metadata.rb is parsed as far as go-chef is able to.
+func readCookbookMetadata(dir string) (map[string]interface{}, error) {
+	metadata := make(map[string]interface{})
+	if content, err := os.ReadFile(filepath.Join(dir, "metadata.json")); err == nil {
+		if err := json.Unmarshal(content, &metadata); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", filepath.Join(dir, "metadata.json"), err)
+		}
+		return metadata, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "metadata.rb"))
+	if err != nil {
+		return nil, fmt.Errorf("cookbook in %s has no metadata.json or metadata.rb: %w", dir, err)
+	}
+	parsed, err := chefc.NewMetaData(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Join(dir, "metadata.rb"), err)
+	}
+	encoded, err := json.Marshal(parsed)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(encoded, &metadata); err != nil {
+		return nil, err
+	}
+	// go-chef's CookbookMeta has no JSON names for these, and the server
+	// wants chef_versions and ohai_versions lists rather than strings.
+	delete(metadata, "ChefVersion")
+	delete(metadata, "OhaiVersion")
+	return metadata, nil
+}
+
+// buildCookbookManifest returns the cookbook version document for files,
+// in the segmented format of server API version 1.
+func buildCookbookManifest(name, version string, metadata map[string]interface{}, files []cookbookFile) map[string]interface{} {
+	manifest := map[string]interface{}{
+		"name":          name + "-" + version,
+		"cookbook_name": name,
+		"version":       version,
+		"chef_type":     "cookbook_version",
+		"json_class":    "Chef::CookbookVersion",
+		"frozen?":       false,
+		"metadata":      metadata,
+	}
+	for segment := range cookbookSegmentDirs {
+		manifest[segment] = []chefc.CookbookItem{}
+	}
+	manifest["root_files"] = []chefc.CookbookItem{}
+
+	for _, f := range files {
+		parts := strings.Split(f.path, "/")
+		segment := "root_files"
+		specificity := "default"
+		if len(parts) > 1 {
+			segment = parts[0]
+			if (segment == "files" || segment == "templates") && len(parts) > 2 {
+				specificity = parts[1]
+			}
+		}
+		manifest[segment] = append(manifest[segment].([]chefc.CookbookItem), chefc.CookbookItem{
+			Name:        path.Base(f.path),
+			Path:        f.path,
+			Checksum:    f.checksum,
+			Specificity: specificity,
+		})
+	}
+	return manifest
+}
+
+// uploadCookbook uploads the files the server doesn't have yet, a batch at
+// a time, then saves the cookbook version.
+func uploadCookbook(ctx context.Context, d *schema.ResourceData, client *chefClient) diag.Diagnostics {
+	if client.options != nil && client.options.ServerAPIVersion > 1 {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  "Cookbook upload needs server API version 1",
+				Detail:   "chef_cookbook sends cookbooks in the segmented format of server API version 1, which server_api_version overrides.",
+			},
+		}
+	}
+
+	name := d.Get("name").(string)
+	version := d.Get("version").(string)
+	dir := d.Get("path").(string)
+
+	metadata, err := readCookbookMetadata(dir)
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Error reading cookbook metadata",
+				Detail:        fmt.Sprint(err),
+				AttributePath: cty.GetAttrPath("path"),
+			},
+		}
+	}
+	files, err := readCookbookDir(dir)
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Error reading cookbook",
+				Detail:        fmt.Sprint(err),
+				AttributePath: cty.GetAttrPath("path"),
+			},
+		}
+	}
+
+	byChecksum := make(map[string]cookbookFile)
+	for _, f := range files {
+		byChecksum[f.checksum] = f
+	}
+	checksums := make([]string, 0, len(byChecksum))
+	for sum := range byChecksum {
+		checksums = append(checksums, sum)
+	}
+	sort.Strings(checksums)
+
+	for start := 0; start < len(checksums); start += cookbookUploadBatchSize {
+		end := start + cookbookUploadBatchSize
+		if end > len(checksums) {
+			end = len(checksums)
+		}
+		if err := uploadSandbox(ctx, client.Client, checksums[start:end], byChecksum, d.Get("concurrency").(int)); err != nil {
+			return chefErrToDiag("Error uploading cookbook files", err, cty.GetAttrPath("path"))
+		}
+	}
+
+	body, err := chefc.JSONReader(buildCookbookManifest(name, version, metadata, files))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	req, err := newRequestWithContext(ctx, client.Client, "PUT", "cookbooks/"+name+"/"+version, body)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if _, err := client.Do(req, nil); err != nil {
+		return chefErrToDiag("Error saving cookbook version", err, nil)
+	}
+	return nil
+}
+
+// uploadSandbox creates a sandbox for checksums, uploads the files the
+// server asks for and commits it.
+func uploadSandbox(ctx context.Context, client *chefc.Client, checksums []string, files map[string]cookbookFile, concurrency int) error {
+	sandbox, err := client.Sandboxes.Post(checksums)
+	if err != nil {
+		return err
+	}
+
+	var ops []bulkOperation
+	for _, sum := range checksums {
+		item, ok := sandbox.Checksums[sum]
+		if !ok || !item.Upload {
+			continue
+		}
+		f, url := files[sum], item.Url
+		ops = append(ops, bulkOperation{
+			ID:  f.path,
+			Run: func() error { return uploadCookbookFile(ctx, client, url, f) },
+		})
+	}
+	if err := runBulkConcurrent(ops, concurrency); err != nil {
+		return err
+	}
+
+	_, err = client.Sandboxes.Put(sandbox.ID)
+	return err
+}
+
+// fileChecksum returns the hex MD5 checksum of the named file, which is
+// how the server identifies cookbook files.
+func fileChecksum(name string) (string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// uploadCookbookFile PUTs a file to the URL the sandbox gave for it, which
+// is presigned and so isn't signed again here. The file is streamed from
+// disk, and reopened for each retry, rather than read into memory. A file
+// that changes while it is sent fails the server's Content-MD5 check.
+func uploadCookbookFile(ctx context.Context, client *chefc.Client, url string, f cookbookFile) error {
+	sum, err := hex.DecodeString(f.checksum)
+	if err != nil {
+		return err
+	}
+	if checksum, err := fileChecksum(f.fullPath); err != nil {
+		return err
+	} else if checksum != f.checksum {
+		return fmt.Errorf("%s changed while the cookbook was being uploaded", f.path)
+	}
+
+	file, err := os.Open(f.fullPath)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	req.GetBody = func() (io.ReadCloser, error) { return os.Open(f.fullPath) }
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/x-binary")
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum))
+
+	res, err := chefHTTPClient(client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return chefc.CheckResponse(res)
+}
@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// resourceChefCookbookArtifactGC deletes the cookbook artifacts that no
+// policy revision references, which Policyfile workflows otherwise
+// accumulate indefinitely. Cleanup is planned whenever such artifacts
+// exist, so every apply leaves the artifact store clean.
+func resourceChefCookbookArtifactGC() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateCookbookArtifactGC,
+		ReadContext:   ReadCookbookArtifactGC,
+		DeleteContext: DeleteCookbookArtifactGC,
+		CustomizeDiff: diffCookbookArtifactGC,
+
+		Schema: map[string]*schema.Schema{
+			"unreferenced": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Artifacts, as NAME/IDENTIFIER, that no policy revision references.",
+			},
+			"removed": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Artifacts, as NAME/IDENTIFIER, deleted by the last cleanup.",
+			},
+			"removed_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateCookbookArtifactGC(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId(resource.UniqueId())
+	return collectCookbookArtifacts(ctx, d, meta)
+}
+
+func ReadCookbookArtifactGC(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	unreferenced, err := unreferencedCookbookArtifacts(meta.(*chefClient).Client)
+	if err != nil {
+		return chefErrToDiag("Error finding unreferenced cookbook artifacts", err, nil)
+	}
+
+	d.Set("unreferenced", unreferenced)
+	return nil
+}
+
+func DeleteCookbookArtifactGC(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
+
+// diffCookbookArtifactGC plans a cleanup whenever there are unreferenced
+// artifacts to remove. Cleanup is planned as a replacement, since deleting
+// the resource itself does nothing and creating it runs the cleanup.
+func diffCookbookArtifactGC(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || len(d.Get("unreferenced").([]interface{})) == 0 {
+		return nil
+	}
+
+	if err := d.SetNew("unreferenced", []string{}); err != nil {
+		return err
+	}
+	if err := d.SetNewComputed("removed"); err != nil {
+		return err
+	}
+	if err := d.SetNewComputed("removed_count"); err != nil {
+		return err
+	}
+	return d.ForceNew("unreferenced")
+}
+
+// collectCookbookArtifacts deletes every artifact that is unreferenced at
+// the time of the apply. References are recomputed here rather than taken
+// from the plan, so that an artifact a policy started using since then is
+// never deleted.
+func collectCookbookArtifacts(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	unreferenced, err := unreferencedCookbookArtifacts(client.Client)
+	if err != nil {
+		return chefErrToDiag("Error finding unreferenced cookbook artifacts", err, nil)
+	}
+
+	removed := make([]string, 0, len(unreferenced))
+	var ops []bulkOperation
+	for _, artifact := range unreferenced {
+		artifact := artifact
+		ops = append(ops, bulkOperation{
+			ID: artifact,
+			Run: func() error {
+				err := deleteCookbookArtifact(ctx, client.Client, artifact)
+				if err == nil || isChefNotFound(err) {
+					removed = append(removed, artifact)
+					return nil
+				}
+				return err
+			},
+		})
+	}
+	err = runBulk(ops, false)
+
+	d.Set("removed", removed)
+	d.Set("removed_count", len(removed))
+	if err != nil {
+		return chefErrToDiag("Error deleting cookbook artifacts", err, nil)
+	}
+
+	return ReadCookbookArtifactGC(ctx, d, meta)
+}
+
+// unreferencedCookbookArtifacts returns, sorted as NAME/IDENTIFIER, every
+// cookbook artifact not locked by any revision of any policy.
+func unreferencedCookbookArtifacts(client *chefc.Client) ([]string, error) {
+	policies, err := client.Policies.List()
+	if err != nil {
+		return nil, fmt.Errorf("listing policies: %s", err)
+	}
+
+	referenced := make(map[string]bool)
+	for name, policy := range policies {
+		for revision := range policy.Revisions {
+			details, err := client.Policies.GetRevisionDetails(name, revision)
+			if err != nil {
+				return nil, fmt.Errorf("reading policy %s revision %s: %s", name, revision, err)
+			}
+			for cookbook, lock := range details.CookbookLocks {
+				referenced[cookbook+"/"+lock.Identifier] = true
+			}
+		}
+	}
+
+	artifacts, err := client.CookbookArtifacts.List()
+	if err != nil {
+		return nil, fmt.Errorf("listing cookbook artifacts: %s", err)
+	}
+
+	unreferenced := make([]string, 0)
+	for name, cba := range artifacts {
+		for _, version := range cba.CBAVersions {
+			if id := name + "/" + version.Identifier; !referenced[id] {
+				unreferenced = append(unreferenced, id)
+			}
+		}
+	}
+	sort.Strings(unreferenced)
+	return unreferenced, nil
+}
+
+func deleteCookbookArtifact(ctx context.Context, client *chefc.Client, artifact string) error {
+	req, err := newRequestWithContext(ctx, client, "DELETE", "cookbook_artifacts/"+artifact, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req, nil)
+	if res != nil {
+		defer res.Body.Close()
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestCreateCookbookArtifactGC(t *testing.T) {
+	deleted := make(map[string]bool)
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch path := strings.TrimPrefix(r.URL.Path, "/organizations/test/"); {
+		case path == "policies":
+			w.Write([]byte(`{"web":{"revisions":{"r1":{},"r2":{}}}}`))
+		case path == "policies/web/revisions/r1":
+			w.Write([]byte(`{"cookbook_locks":{"nginx":{"identifier":"aaa"},"apt":{"identifier":"ccc"}}}`))
+		case path == "policies/web/revisions/r2":
+			w.Write([]byte(`{"cookbook_locks":{"nginx":{"identifier":"bbb"}}}`))
+		case path == "cookbook_artifacts":
+			var versions string
+			if !deleted["nginx/old"] {
+				versions = `,{"identifier":"old"}`
+			}
+			w.Write([]byte(`{"nginx":{"versions":[{"identifier":"aaa"},{"identifier":"bbb"}` + versions + `]},"apt":{"versions":[{"identifier":"ccc"}]}}`))
+		case r.Method == "DELETE" && strings.HasPrefix(path, "cookbook_artifacts/"):
+			deleted[strings.TrimPrefix(path, "cookbook_artifacts/")] = true
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefCookbookArtifactGC().Schema, map[string]interface{}{})
+	if diags := CreateCookbookArtifactGC(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if len(deleted) != 1 || !deleted["nginx/old"] {
+		t.Fatalf("expected only the unreferenced artifact to be deleted, got %v", deleted)
+	}
+	if d.Get("removed_count").(int) != 1 || len(d.Get("unreferenced").([]interface{})) != 0 {
+		t.Fatalf("unexpected state removed_count=%v unreferenced=%v", d.Get("removed_count"), d.Get("unreferenced"))
+	}
+}
@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func resourceChefCookbookArtifactSharing() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateCookbookArtifactSharing,
+		UpdateContext: UpdateCookbookArtifactSharing,
+		ReadContext:   ReadCookbookArtifactSharing,
+		DeleteContext: DeleteCookbookArtifactSharing,
+		CustomizeDiff: diffCookbookArtifactSharing,
+
+		Schema: map[string]*schema.Schema{
+			"cookbook": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"identifier": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"policy_groups": {
+				Type:     schema.TypeSet,
+				Required: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"referenced_by": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"supported": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateCookbookArtifactSharing(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	id := d.Get("cookbook").(string)
+	if identifier := d.Get("identifier").(string); identifier != "" {
+		id += "/" + identifier
+	}
+	d.SetId(id)
+
+	return reconcileCookbookArtifactSharing(ctx, d, meta)
+}
+
+func UpdateCookbookArtifactSharing(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return reconcileCookbookArtifactSharing(ctx, d, meta)
+}
+
+func ReadCookbookArtifactSharing(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	referencedBy, supported, err := cookbookArtifactReferences(client, d.Get("cookbook").(string), d.Get("identifier").(string))
+	if err != nil {
+		return chefErrToDiag("Error reading cookbook artifact sharing", err, cty.GetAttrPath("cookbook"))
+	}
+
+	d.Set("referenced_by", referencedBy)
+	d.Set("supported", supported)
+	return nil
+}
+
+func DeleteCookbookArtifactSharing(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
+
+// diffCookbookArtifactSharing plans an update, and so the check that
+// reports them, whenever a policy group outside policy_groups has come to
+// pin the artifact since the last apply. The planned referenced_by drops
+// those groups, which is what resolving the error makes true.
+func diffCookbookArtifactSharing(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.Get("supported").(bool) || !d.NewValueKnown("policy_groups") {
+		return nil
+	}
+
+	allowed := d.Get("policy_groups").(*schema.Set)
+	referencedBy := d.Get("referenced_by").(*schema.Set)
+	planned := make([]interface{}, 0, referencedBy.Len())
+	for _, group := range referencedBy.List() {
+		if allowed.Contains(group) {
+			planned = append(planned, group)
+		}
+	}
+	if len(planned) == referencedBy.Len() {
+		return nil
+	}
+	return d.SetNew("referenced_by", planned)
+}
+
+// reconcileCookbookArtifactSharing checks the policy groups that currently
+// pin the cookbook artifact against the ones allowed to. Chef Server has no
+// way to bar a policy group from referencing an artifact, so disallowed
+// references are reported as an error for the owner of that policy group
+// to resolve rather than being removed here.
+func reconcileCookbookArtifactSharing(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := ReadCookbookArtifactSharing(ctx, d, meta); diags.HasError() {
+		return diags
+	}
+
+	if !d.Get("supported").(bool) {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Warning,
+				Summary:  "Cookbook artifact sharing is not supported",
+				Detail:   "The Chef server does not expose policy groups, so cookbook artifact sharing cannot be checked.",
+			},
+		}
+	}
+
+	allowed := d.Get("policy_groups").(*schema.Set)
+	var disallowed []string
+	for _, group := range d.Get("referenced_by").(*schema.Set).List() {
+		if !allowed.Contains(group) {
+			disallowed = append(disallowed, group.(string))
+		}
+	}
+	if len(disallowed) > 0 {
+		sort.Strings(disallowed)
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  "Cookbook artifact is referenced by policy groups it is not shared with",
+				Detail: fmt.Sprintf("%s is pinned by policy group(s) %s, which are not listed in policy_groups.",
+					d.Id(), strings.Join(disallowed, ", ")),
+				AttributePath: cty.GetAttrPath("policy_groups"),
+			},
+		}
+	}
+
+	return nil
+}
+
+// cookbookArtifactReferences returns the policy groups with a policy
+// revision that locks cookbook, optionally at a specific artifact
+// identifier. The second result is false if the server has no policy
+// group support at all.
+func cookbookArtifactReferences(client *chefClient, cookbook, identifier string) ([]interface{}, bool, error) {
+	groups, err := client.PolicyGroups.List()
+	if err != nil {
+		if isChefNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	referencedBy := make([]interface{}, 0)
+	for group, details := range groups {
+		for policy := range details.Policies {
+			revision, err := client.PolicyGroups.GetPolicy(group, policy)
+			if err != nil {
+				return nil, true, fmt.Errorf("reading policy %s in policy group %s: %s", policy, group, err)
+			}
+			if cookbookLockMatches(revision.CookbookLocks, cookbook, identifier) {
+				referencedBy = append(referencedBy, group)
+				break
+			}
+		}
+	}
+
+	return referencedBy, true, nil
+}
+
+func cookbookLockMatches(locks map[string]chefc.CookbookLock, cookbook, identifier string) bool {
+	lock, ok := locks[cookbook]
+	if !ok {
+		return false
+	}
+	return identifier == "" || lock.Identifier == identifier
+}
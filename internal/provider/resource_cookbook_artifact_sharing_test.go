@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testPolicyGroupServer(t *testing.T) *chefClient {
+	return testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/organizations/test/policy_groups":
+			w.Write([]byte(`{
+				"prod": {"policies": {"web": {"revision_id": "1"}}},
+				"dev": {"policies": {"web": {"revision_id": "2"}}}
+			}`))
+		case "/organizations/test/policy_groups/prod/policies/web":
+			w.Write([]byte(`{"cookbook_locks": {"nginx": {"identifier": "aaa"}}}`))
+		case "/organizations/test/policy_groups/dev/policies/web":
+			w.Write([]byte(`{"cookbook_locks": {"nginx": {"identifier": "bbb"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func TestCreateCookbookArtifactSharing(t *testing.T) {
+	c := testPolicyGroupServer(t)
+
+	d := schema.TestResourceDataRaw(t, resourceChefCookbookArtifactSharing().Schema, map[string]interface{}{
+		"cookbook":      "nginx",
+		"policy_groups": []interface{}{"prod", "dev"},
+	})
+	if diags := CreateCookbookArtifactSharing(context.Background(), d, c); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if got := d.Get("referenced_by").(*schema.Set).Len(); got != 2 {
+		t.Fatalf("expected 2 referencing policy groups, got %d", got)
+	}
+}
+
+func TestCreateCookbookArtifactSharing_disallowed(t *testing.T) {
+	c := testPolicyGroupServer(t)
+
+	d := schema.TestResourceDataRaw(t, resourceChefCookbookArtifactSharing().Schema, map[string]interface{}{
+		"cookbook":      "nginx",
+		"identifier":    "bbb",
+		"policy_groups": []interface{}{"prod"},
+	})
+	diags := CreateCookbookArtifactSharing(context.Background(), d, c)
+	if !diags.HasError() || !strings.Contains(diags[0].Detail, "dev") {
+		t.Fatalf("expected an error naming the dev policy group, got %v", diags)
+	}
+}
+
+func TestCreateCookbookArtifactSharing_unsupported(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefCookbookArtifactSharing().Schema, map[string]interface{}{
+		"cookbook":      "nginx",
+		"policy_groups": []interface{}{"prod"},
+	})
+	diags := CreateCookbookArtifactSharing(context.Background(), d, c)
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected a single warning, got %v", diags)
+	}
+}
+
+func TestDiffCookbookArtifactSharing_disallowed(t *testing.T) {
+	c := testPolicyGroupServer(t)
+	res := resourceChefCookbookArtifactSharing()
+
+	config := map[string]interface{}{
+		"cookbook":      "nginx",
+		"policy_groups": []interface{}{"prod"},
+	}
+	d := schema.TestResourceDataRaw(t, res.Schema, config)
+	d.SetId("nginx")
+	if diags := ReadCookbookArtifactSharing(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	diff, err := res.Diff(context.Background(), d.State(), terraform.NewResourceConfigRaw(config), c)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff == nil || diff.Attributes["referenced_by.#"] == nil {
+		t.Fatalf("expected an update to be planned for the dev policy group, got %#v", diff)
+	}
+	if got := diff.Attributes["referenced_by.#"].New; got != "1" {
+		t.Fatalf("expected dev to be planned out of referenced_by, got %q", got)
+	}
+
+	config["policy_groups"] = []interface{}{"prod", "dev"}
+	diff, err = res.Diff(context.Background(), d.State(), terraform.NewResourceConfigRaw(config), c)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff != nil && diff.Attributes["referenced_by.#"] != nil {
+		t.Fatalf("expected no change to referenced_by once dev is allowed, got %#v", diff.Attributes["referenced_by.#"])
+	}
+}
@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// resourceChefCookbookPromotion copies one cookbook's version constraint
+// from a source environment to a target environment, such as promoting
+// whatever staging runs of a cookbook to production.
+func resourceChefCookbookPromotion() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateCookbookPromotion,
+		UpdateContext: UpdateCookbookPromotion,
+		ReadContext:   ReadCookbookPromotion,
+		DeleteContext: DeleteCookbookPromotion,
+		CustomizeDiff: diffCookbookPromotion,
+
+		Schema: map[string]*schema.Schema{
+			"cookbook": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"source_environment": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"target_environment": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"constraint": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// environmentCookbookConstraint returns the named environment's constraint
+// for cookbook, failing if the environment doesn't constrain it.
+func environmentCookbookConstraint(client *chefc.Client, environment, cookbook string) (string, error) {
+	env, err := client.Environments.Get(environment)
+	if err != nil {
+		return "", err
+	}
+
+	constraint, ok := env.CookbookVersions[cookbook]
+	if !ok {
+		return "", fmt.Errorf("environment %s has no version constraint for cookbook %s, so there is nothing to promote", environment, cookbook)
+	}
+	return constraint, nil
+}
+
+func CreateCookbookPromotion(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := promoteCookbook(d, meta); diags != nil {
+		return diags
+	}
+
+	d.SetId(d.Get("target_environment").(string) + "/" + d.Get("cookbook").(string))
+	return ReadCookbookPromotion(ctx, d, meta)
+}
+
+func UpdateCookbookPromotion(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := promoteCookbook(d, meta); diags != nil {
+		return diags
+	}
+
+	return ReadCookbookPromotion(ctx, d, meta)
+}
+
+func ReadCookbookPromotion(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  "Invalid cookbook promotion ID",
+				Detail:   fmt.Sprintf("expected TARGET_ENVIRONMENT/COOKBOOK, got %q", d.Id()),
+			},
+		}
+	}
+	target, cookbook := parts[0], parts[1]
+
+	env, err := client.Environments.Get(target)
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading environment", err, cty.GetAttrPath("target_environment"))
+	}
+
+	d.Set("cookbook", cookbook)
+	d.Set("target_environment", env.Name)
+	d.Set("constraint", env.CookbookVersions[cookbook])
+	return nil
+}
+
+func DeleteCookbookPromotion(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	env, err := client.Environments.Get(d.Get("target_environment").(string))
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading environment", err, cty.GetAttrPath("target_environment"))
+	}
+
+	delete(env.CookbookVersions, d.Get("cookbook").(string))
+	if _, err := client.Environments.Put(env); err != nil {
+		return chefErrToDiag("Error updating environment", err, cty.GetAttrPath("target_environment"))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// diffCookbookPromotion plans an update whenever the target's constraint
+// no longer matches the source's, whichever of the two changed.
+func diffCookbookPromotion(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.NewValueKnown("source_environment") {
+		return nil
+	}
+
+	source, err := environmentCookbookConstraint(meta.(*chefClient).Client,
+		d.Get("source_environment").(string), d.Get("cookbook").(string))
+	if err != nil {
+		return err
+	}
+
+	if d.Get("constraint").(string) != source {
+		return d.SetNew("constraint", source)
+	}
+	return nil
+}
+
+func promoteCookbook(d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	cookbook := d.Get("cookbook").(string)
+
+	constraint, err := environmentCookbookConstraint(client.Client, d.Get("source_environment").(string), cookbook)
+	if err != nil {
+		return chefErrToDiag("Error reading source constraint", err, cty.GetAttrPath("source_environment"))
+	}
+
+	env, err := client.Environments.Get(d.Get("target_environment").(string))
+	if err != nil {
+		return chefErrToDiag("Error reading environment", err, cty.GetAttrPath("target_environment"))
+	}
+
+	if env.CookbookVersions == nil {
+		env.CookbookVersions = make(map[string]string)
+	}
+	env.CookbookVersions[cookbook] = constraint
+
+	if _, err := client.Environments.Put(env); err != nil {
+		return chefErrToDiag("Error updating environment", err, cty.GetAttrPath("target_environment"))
+	}
+
+	return nil
+}
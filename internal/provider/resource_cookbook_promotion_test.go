@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestCreateCookbookPromotion(t *testing.T) {
+	environments := map[string]*chefc.Environment{
+		"staging": {Name: "staging", CookbookVersions: map[string]string{"app": "= 1.4.2", "db": "~> 2.0"}},
+		"prod":    {Name: "prod", CookbookVersions: map[string]string{"app": "= 1.3.0"}},
+	}
+
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		name := strings.TrimPrefix(r.URL.Path, "/organizations/test/environments/")
+		env, ok := environments[name]
+		if !ok {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "PUT" {
+			json.NewDecoder(r.Body).Decode(env)
+		}
+		json.NewEncoder(w).Encode(env)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefCookbookPromotion().Schema, map[string]interface{}{
+		"cookbook":           "app",
+		"source_environment": "staging",
+		"target_environment": "prod",
+	})
+	if diags := CreateCookbookPromotion(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if got := environments["prod"].CookbookVersions; len(got) != 1 || got["app"] != "= 1.4.2" {
+		t.Fatalf("expected only app to be promoted to prod, got %v", got)
+	}
+	if d.Id() != "prod/app" || d.Get("constraint").(string) != "= 1.4.2" {
+		t.Fatalf("unexpected state id=%q constraint=%q", d.Id(), d.Get("constraint"))
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceChefCookbookPromotion().Schema, map[string]interface{}{
+		"cookbook":           "web",
+		"source_environment": "staging",
+		"target_environment": "prod",
+	})
+	diags := CreateCookbookPromotion(context.Background(), d, c)
+	if !diags.HasError() || !strings.Contains(diags[0].Detail, "no version constraint for cookbook web") {
+		t.Fatalf("expected an error for an unconstrained cookbook, got %v", diags)
+	}
+}
@@ -0,0 +1,260 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testCookbookServer serves just enough of the sandbox and cookbook APIs
+// to upload a cookbook, failing the upload of any checksum in failing.
+type testCookbookServer struct {
+	mu        sync.Mutex
+	committed map[string]bool
+	sandboxes map[string][]string
+	uploaded  map[string]int
+	failing   map[string]bool
+	manifest  []byte
+}
+
+func (s *testCookbookServer) handle(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/organizations/test/sandboxes":
+			var req struct {
+				Checksums map[string]interface{} `json:"checksums"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			id := fmt.Sprintf("sb%d", len(s.sandboxes))
+			items := make(map[string]interface{})
+			for sum := range req.Checksums {
+				s.sandboxes[id] = append(s.sandboxes[id], sum)
+				items[sum] = map[string]interface{}{
+					"url":          "http://" + r.Host + "/bookshelf/" + sum,
+					"needs_upload": !s.committed[sum],
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"sandbox_id": id, "checksums": items})
+		case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, "/bookshelf/"):
+			sum := strings.TrimPrefix(r.URL.Path, "/bookshelf/")
+			if r.Header.Get("Content-Type") != "application/x-binary" || r.Header.Get("Content-MD5") == "" {
+				t.Errorf("unexpected upload headers %v", r.Header)
+			}
+			if s.failing[sum] {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			s.uploaded[sum]++
+		case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, "/organizations/test/sandboxes/"):
+			id := strings.TrimPrefix(r.URL.Path, "/organizations/test/sandboxes/")
+			for _, sum := range s.sandboxes[id] {
+				if !s.committed[sum] && s.uploaded[sum] == 0 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+			}
+			for _, sum := range s.sandboxes[id] {
+				s.committed[sum] = true
+			}
+			w.Write([]byte(`{"guid": "` + id + `", "is_completed": true}`))
+		case r.Method == "PUT" && r.URL.Path == "/organizations/test/cookbooks/app/1.0.0":
+			s.manifest, _ = io.ReadAll(r.Body)
+			w.Write(s.manifest)
+		case r.Method == "GET" && r.URL.Path == "/organizations/test/cookbooks/app/1.0.0":
+			if s.manifest == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(s.manifest)
+		case r.Method == "DELETE" && r.URL.Path == "/organizations/test/cookbooks/app/1.0.0":
+			s.manifest = nil
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func testCookbookDir(t *testing.T, recipes int) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"metadata.json":                  `{"name": "app", "version": "1.0.0"}`,
+		"README.md":                      "# app\n",
+		"templates/default/app.conf.erb": "port <%= @port %>\n",
+		".git/HEAD":                      "ref: refs/heads/main\n",
+		"test/integration/default.rb":    "describe port(80)\n",
+	}
+	for i := 0; i < recipes; i++ {
+		files[fmt.Sprintf("recipes/r%02d.rb", i)] = fmt.Sprintf("log 'recipe %d'\n", i)
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	return dir
+}
+
+func TestCookbook(t *testing.T) {
+	server := &testCookbookServer{
+		committed: make(map[string]bool),
+		sandboxes: make(map[string][]string),
+		uploaded:  make(map[string]int),
+		failing:   make(map[string]bool),
+	}
+	c := testChefClient(t, server.handle(t))
+
+	// 60 recipes and 3 other files make two sandboxes.
+	dir := testCookbookDir(t, 60)
+	files, err := readCookbookDir(dir)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(files) != 63 {
+		t.Fatalf("expected hidden and test files to be skipped, got %d files", len(files))
+	}
+	sums := make([]string, 0, len(files))
+	for _, f := range files {
+		sums = append(sums, f.checksum)
+	}
+	last := sums[0]
+	for _, sum := range sums {
+		if sum > last {
+			last = sum
+		}
+	}
+
+	// The last batch fails, leaving the first committed.
+	server.failing[last] = true
+	d := schema.TestResourceDataRaw(t, resourceChefCookbook().Schema, map[string]interface{}{
+		"name":    "app",
+		"version": "1.0.0",
+		"path":    dir,
+	})
+	if diags := CreateCookbook(context.Background(), d, c); !diags.HasError() {
+		t.Fatal("expected the failed upload to be reported")
+	}
+	if len(server.committed) != cookbookUploadBatchSize {
+		t.Fatalf("expected the first batch to be committed, got %d files", len(server.committed))
+	}
+
+	// Retrying only repeats the batch that failed.
+	firstBatch := make(map[string]bool)
+	for sum := range server.committed {
+		firstBatch[sum] = true
+	}
+	delete(server.failing, last)
+	if diags := CreateCookbook(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	for _, sum := range sums {
+		if n := server.uploaded[sum]; n == 0 || (firstBatch[sum] && n != 1) {
+			t.Errorf("expected %s to be uploaded once after being committed, got %d uploads", sum, n)
+		}
+	}
+	if d.Id() != "app/1.0.0" {
+		t.Fatalf("unexpected ID %s", d.Id())
+	}
+
+	var manifest map[string]interface{}
+	json.Unmarshal(server.manifest, &manifest)
+	templates := manifest["templates"].([]interface{})
+	if len(templates) != 1 || templates[0].(map[string]interface{})["specificity"] != "default" {
+		t.Fatalf("unexpected templates %v", templates)
+	}
+	if n := len(manifest["recipes"].([]interface{})); n != 60 {
+		t.Fatalf("expected 60 recipes, got %d", n)
+	}
+	checksums := d.Get("checksums").(map[string]interface{})
+	if len(checksums) != 63 || checksums["README.md"] == "" {
+		t.Fatalf("unexpected checksums %v", checksums)
+	}
+
+	if diags := DeleteCookbook(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	d.SetId("app/1.0.0")
+	if diags := ReadCookbook(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected a deleted cookbook to be removed from state")
+	}
+}
+
+func TestUploadCookbookFile(t *testing.T) {
+	var uploaded []byte
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != 12 {
+			t.Errorf("expected the file's length to be sent, got %d", r.ContentLength)
+		}
+		uploaded, _ = io.ReadAll(r.Body)
+	})
+
+	name := filepath.Join(t.TempDir(), "default.rb")
+	if err := os.WriteFile(name, []byte("log 'hello'\n"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	checksum, err := fileChecksum(name)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	f := cookbookFile{path: "recipes/default.rb", fullPath: name, checksum: checksum}
+	url := c.BaseURL.String() + "bookshelf/" + checksum
+
+	if err := uploadCookbookFile(context.Background(), c.Client, url, f); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(uploaded) != "log 'hello'\n" {
+		t.Fatalf("unexpected upload %q", uploaded)
+	}
+
+	// A file changed since it was checksummed isn't sent.
+	uploaded = nil
+	if err := os.WriteFile(name, []byte("log 'howdy'\n"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	err = uploadCookbookFile(context.Background(), c.Client, url, f)
+	if err == nil || !strings.Contains(err.Error(), "recipes/default.rb changed") {
+		t.Fatalf("expected a changed file to fail, got %v", err)
+	}
+	if uploaded != nil {
+		t.Fatalf("expected nothing to be uploaded, got %q", uploaded)
+	}
+}
+
+func TestReadCookbookMetadata(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "metadata.rb"), []byte("name 'app'\nversion '2.1.0'\ndepends 'apt'\nchef_version '>= 16'\n"), 0644)
+
+	metadata, err := readCookbookMetadata(dir)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if metadata["name"] != "app" || metadata["version"] != "2.1.0" {
+		t.Fatalf("unexpected metadata %v", metadata)
+	}
+	if _, ok := metadata["ChefVersion"]; ok {
+		t.Fatal("expected go-chef's untagged fields to be dropped")
+	}
+
+	if _, err := readCookbookMetadata(t.TempDir()); err == nil {
+		t.Fatal("expected a cookbook without metadata to fail")
+	}
+}
@@ -1,6 +1,10 @@
 package provider
 
 import (
+	"context"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	chefc "github.com/go-chef/chef"
@@ -8,9 +12,9 @@ import (
 
 func resourceChefDataBag() *schema.Resource {
 	return &schema.Resource{
-		Create: CreateDataBag,
-		Read:   ReadDataBag,
-		Delete: DeleteDataBag,
+		CreateContext: CreateDataBag,
+		ReadContext:   ReadDataBag,
+		DeleteContext: DeleteDataBag,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -26,7 +30,7 @@ func resourceChefDataBag() *schema.Resource {
 	}
 }
 
-func CreateDataBag(d *schema.ResourceData, meta interface{}) error {
+func CreateDataBag(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*chefClient)
 
 	dataBag := &chefc.DataBag{
@@ -35,7 +39,7 @@ func CreateDataBag(d *schema.ResourceData, meta interface{}) error {
 
 	result, err := client.DataBags.Create(dataBag)
 	if err != nil {
-		return err
+		return chefErrToDiag("Error creating data bag", err, cty.GetAttrPath("name"))
 	}
 
 	d.SetId(dataBag.Name)
@@ -43,7 +47,7 @@ func CreateDataBag(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func ReadDataBag(d *schema.ResourceData, meta interface{}) error {
+func ReadDataBag(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*chefClient)
 
 	// The Chef API provides no API to read a data bag's metadata,
@@ -52,26 +56,24 @@ func ReadDataBag(d *schema.ResourceData, meta interface{}) error {
 
 	name := d.Id()
 
-	_, err := client.DataBags.ListItems(name)
-	if err != nil {
-		if errRes, ok := err.(*chefc.ErrorResponse); ok {
-			if errRes.Response.StatusCode == 404 {
-				d.SetId("")
-				return nil
-			}
+	if _, err := client.DataBags.ListItems(name); err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
 		}
+		return chefErrToDiag("Error reading data bag", err, cty.GetAttrPath("name"))
 	}
-	return err
+	return nil
 }
 
-func DeleteDataBag(d *schema.ResourceData, meta interface{}) error {
+func DeleteDataBag(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*chefClient)
 
 	name := d.Id()
 
-	_, err := client.DataBags.Delete(name)
-	if err == nil {
-		d.SetId("")
+	if _, err := client.DataBags.Delete(name); err != nil {
+		return chefErrToDiag("Error deleting data bag", err, cty.GetAttrPath("name"))
 	}
-	return err
+	d.SetId("")
+	return nil
 }
@@ -1,22 +1,25 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-
-	chefc "github.com/go-chef/chef"
 )
 
 func resourceChefDataBagItem() *schema.Resource {
 	return &schema.Resource{
-		Create: CreateDataBagItem,
-		Read:   ReadDataBagItem,
-		Delete: DeleteDataBagItem,
+		CreateContext: CreateDataBagItem,
+		UpdateContext: UpdateDataBagItem,
+		ReadContext:   ReadDataBagItem,
+		DeleteContext: DeleteDataBagItem,
+		CustomizeDiff: diffDataBagItem,
 		Importer: &schema.ResourceImporter{
-			State: DataBagItemImporter,
+			StateContext: DataBagItemImporter,
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -25,78 +28,187 @@ func resourceChefDataBagItem() *schema.Resource {
 				Required: true,
 				ForceNew: true,
 			},
+			"item_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The item's id. Must match the id attribute of content_json, from which it is taken when not set.",
+			},
 			"content_json": {
-				Type:      schema.TypeString,
-				Required:  true,
-				ForceNew:  true,
-				StateFunc: jsonStateFunc,
+				Type:             schema.TypeString,
+				Required:         true,
+				Sensitive:        true,
+				StateFunc:        jsonStateFunc,
+				DiffSuppressFunc: suppressEquivalentJSON,
+				Description:      "The item's content, including its id, as JSON. Sensitive, since for an encrypted item it holds the decrypted content.",
+			},
+			"encryption": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Encrypts every field of the item but id with a shared secret, in Chef's encrypted data bag format. Chef Server has no way to store or hand out the secret, so it is always given inline, for example from a variable or a secrets manager. content_json is kept decrypted in the Terraform state, so the state must be protected like the secret.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"secret": {
+							Type:      schema.TypeString,
+							Required:  true,
+							Sensitive: true,
+						},
+						"version": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      3,
+							Description:  "Encrypted data bag format version: 1, 2 or 3. Version 3 requires chef-client 12 or later.",
+							ValidateFunc: validateDataBagEncryptionVersion,
+						},
+					},
+				},
 			},
 		},
 	}
 }
 
-func CreateDataBagItem(d *schema.ResourceData, meta interface{}) error {
+func CreateDataBagItem(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*chefClient)
 
 	dataBagName := d.Get("data_bag_name").(string)
 	itemId, itemContent, err := prepareDataBagItemContent(d.Get("content_json").(string))
 	if err != nil {
-		return err
+		return chefErrToDiag("Error reading data bag item content", err, cty.GetAttrPath("content_json"))
+	}
+	if itemContent, err = encryptDataBagItemContent(d, itemContent); err != nil {
+		return chefErrToDiag("Error encrypting data bag item", err, cty.GetAttrPath("encryption"))
 	}
 
-	err = client.DataBags.CreateItem(dataBagName, itemContent)
-	if err != nil {
-		return err
+	if err = client.DataBags.CreateItem(dataBagName, itemContent); err != nil {
+		return chefErrToDiag("Error creating data bag item", err, cty.GetAttrPath("content_json"))
 	}
 
-	d.SetId(itemId)
+	d.SetId(dataBagName + "/" + itemId)
+	d.Set("item_id", itemId)
+
+	return nil
+}
+
+func UpdateDataBagItem(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	dataBagName, itemId := dataBagItemFromResourceData(d)
+	_, itemContent, err := prepareDataBagItemContent(d.Get("content_json").(string))
+	if err != nil {
+		return chefErrToDiag("Error reading data bag item content", err, cty.GetAttrPath("content_json"))
+	}
+	if itemContent, err = encryptDataBagItemContent(d, itemContent); err != nil {
+		return chefErrToDiag("Error encrypting data bag item", err, cty.GetAttrPath("encryption"))
+	}
 
+	if err = client.DataBags.UpdateItem(dataBagName, itemId, itemContent); err != nil {
+		return chefErrToDiag("Error updating data bag item", err, cty.GetAttrPath("content_json"))
+	}
 	return nil
 }
 
-func ReadDataBagItem(d *schema.ResourceData, meta interface{}) error {
+func ReadDataBagItem(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*chefClient)
 
 	// The Chef API provides no API to read a data bag's metadata,
 	// but we can try to read its items and use that as a proxy for
 	// whether it still exists.
 
-	itemId := d.Id()
-	dataBagName := d.Get("data_bag_name").(string)
+	dataBagName, itemId := dataBagItemFromResourceData(d)
 
 	value, err := client.DataBags.GetItem(dataBagName, itemId)
 	if err != nil {
-		if errRes, ok := err.(*chefc.ErrorResponse); ok {
-			if errRes.Response.StatusCode == 404 {
-				d.SetId("")
-				return nil
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading data bag item", err, cty.GetAttrPath("item_id"))
+	}
+
+	// Encrypted items are compared in plain text, since every encryption
+	// produces different ciphertext.
+	if secret, ok := d.GetOk("encryption.0.secret"); ok {
+		if item, ok := value.(map[string]interface{}); ok {
+			if value, err = decryptDataBagItem(item, secret.(string)); err != nil {
+				return chefErrToDiag(fmt.Sprintf("Error decrypting data bag item %s/%s", dataBagName, itemId), err, cty.GetAttrPath("encryption"))
 			}
-		} else {
-			return err
 		}
 	}
 
 	jsonContent, err := json.Marshal(value)
 	if err != nil {
-		return err
+		return chefErrToDiag("Error encoding data bag item content", err, cty.GetAttrPath("content_json"))
 	}
 
+	d.SetId(dataBagName + "/" + itemId)
+	d.Set("data_bag_name", dataBagName)
+	d.Set("item_id", itemId)
 	d.Set("content_json", string(jsonContent))
 
 	return nil
 }
 
-func DeleteDataBagItem(d *schema.ResourceData, meta interface{}) error {
+func DeleteDataBagItem(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*chefClient)
 
-	itemId := d.Id()
-	dataBagName := d.Get("data_bag_name").(string)
+	dataBagName, itemId := dataBagItemFromResourceData(d)
+
+	if err := client.DataBags.DeleteItem(dataBagName, itemId); err != nil {
+		return chefErrToDiag("Error deleting data bag item", err, cty.GetAttrPath("item_id"))
+	}
+	d.SetId("")
+	return nil
+}
+
+// dataBagItemFromResourceData returns the data bag and item an ID refers
+// to. IDs are data_bag_name/item_id, but state written before items were
+// identified that way holds just the item id.
+func dataBagItemFromResourceData(d *schema.ResourceData) (string, string) {
+	if parts := strings.SplitN(d.Id(), "/", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return d.Get("data_bag_name").(string), d.Id()
+}
+
+// diffDataBagItem checks the id inside content_json at plan time. A
+// configured item_id must match it, and a changed id means a new item.
+func diffDataBagItem(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("content_json") {
+		return nil
+	}
+	itemId, _, err := prepareDataBagItemContent(d.Get("content_json").(string))
+	if err != nil {
+		return fmt.Errorf("content_json: %s", err)
+	}
+
+	if config := d.GetRawConfig(); !config.IsNull() {
+		if v := config.GetAttr("item_id"); v.IsKnown() && !v.IsNull() && v.AsString() != itemId {
+			return fmt.Errorf("item_id %q does not match the id %q in content_json", v.AsString(), itemId)
+		}
+	}
+
+	if d.Get("item_id").(string) == itemId {
+		return nil
+	}
+	if err := d.SetNew("item_id", itemId); err != nil {
+		return err
+	}
+	if d.Id() == "" {
+		return nil
+	}
+	return d.ForceNew("item_id")
+}
 
-	err := client.DataBags.DeleteItem(dataBagName, itemId)
-	if err == nil {
-		d.SetId("")
+// encryptDataBagItemContent encrypts content when an encryption block is
+// configured and returns it unchanged otherwise.
+func encryptDataBagItemContent(d *schema.ResourceData, content interface{}) (interface{}, error) {
+	if _, ok := d.GetOk("encryption.0.secret"); !ok {
+		return content, nil
 	}
-	return err
+	return encryptDataBagItem(content.(map[string]interface{}),
+		d.Get("encryption.0.secret").(string), d.Get("encryption.0.version").(int))
 }
 
 func prepareDataBagItemContent(contentJson string) (string, interface{}, error) {
@@ -118,17 +230,17 @@ func prepareDataBagItemContent(contentJson string) (string, interface{}, error)
 	return itemId, value, nil
 }
 
-func DataBagItemImporter(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+func DataBagItemImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	id := d.Id()
 	parts := strings.Split(id, "/")
 	if len(parts) != 2 {
 		return nil, fmt.Errorf("unexpected format of ID (%s), expected databag_name/item_name", id)
 	}
 
-	d.SetId(parts[1])
 	d.Set("data_bag_name", parts[0])
-	if err := ReadDataBagItem(d, meta); err != nil {
-		return nil, err
+	d.Set("item_id", parts[1])
+	if diags := ReadDataBagItem(ctx, d, meta); diags.HasError() {
+		return nil, fmt.Errorf("%s: %s", diags[0].Summary, diags[0].Detail)
 	}
 
 	return []*schema.ResourceData{d}, nil
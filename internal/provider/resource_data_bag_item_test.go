@@ -1,13 +1,17 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"reflect"
 	"testing"
 
 	chefc "github.com/go-chef/chef"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -40,7 +44,7 @@ func testAccDataBagItemCheck(rn string, name *string) resource.TestCheckFunc {
 		}
 
 		client := testAccProvider.Meta().(*chefClient)
-		content, err := client.DataBags.GetItem("terraform-acc-test-bag-item-basic-"+testSuffix, rs.Primary.ID)
+		content, err := client.DataBags.GetItem("terraform-acc-test-bag-item-basic-"+testSuffix, rs.Primary.Attributes["item_id"])
 		if err != nil {
 			return fmt.Errorf("error getting data bag item: %s", err)
 		}
@@ -53,11 +57,11 @@ func testAccDataBagItemCheck(rn string, name *string) resource.TestCheckFunc {
 			return fmt.Errorf("wrong content: expected %#v, got %#v", expectedContent, content)
 		}
 
-		if expected := "terraform_acc_test"; rs.Primary.Attributes["id"] != expected {
+		if expected := "terraform-acc-test-bag-item-basic-" + testSuffix + "/terraform_acc_test"; rs.Primary.Attributes["id"] != expected {
 			return fmt.Errorf("wrong id; expected %#v, got %#v", expected, rs.Primary.Attributes["id"])
 		}
 
-		*name = rs.Primary.ID
+		*name = rs.Primary.Attributes["item_id"]
 
 		return nil
 	}
@@ -92,3 +96,70 @@ resource "chef_data_bag_item" "test" {
 EOT
 }
 `
+
+func TestDataBagItemLifecycle(t *testing.T) {
+	items := map[string]interface{}{}
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/organizations/test/data/config":
+			var item map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&item)
+			items[item["id"].(string)] = item
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("{}"))
+		case r.URL.Path == "/organizations/test/data/config/app":
+			if r.Method == "PUT" {
+				var item map[string]interface{}
+				json.NewDecoder(r.Body).Decode(&item)
+				items["app"] = item
+			}
+			json.NewEncoder(w).Encode(items["app"])
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefDataBagItem().Schema, map[string]interface{}{
+		"data_bag_name": "config",
+		"content_json":  `{"id":"app","port":80}`,
+	})
+	if diags := CreateDataBagItem(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "config/app" || d.Get("item_id").(string) != "app" {
+		t.Fatalf("unexpected state id=%q item_id=%q", d.Id(), d.Get("item_id"))
+	}
+
+	d.Set("content_json", `{"port":8080,"id":"app"}`)
+	if diags := UpdateDataBagItem(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if got := items["app"].(map[string]interface{})["port"]; got != float64(8080) {
+		t.Fatalf("expected the item to be updated in place, got port %v", got)
+	}
+
+	// State from before IDs included the data bag name.
+	d.SetId("app")
+	if diags := ReadDataBagItem(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "config/app" || d.Get("content_json").(string) != `{"id":"app","port":8080}` {
+		t.Fatalf("unexpected state id=%q content_json=%q", d.Id(), d.Get("content_json"))
+	}
+}
+
+func TestReadDataBagItem_error(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":["internal server error"]}`))
+	})
+
+	d := resourceChefDataBagItem().Data(nil)
+	d.SetId("config/app")
+	if diags := ReadDataBagItem(context.Background(), d, c); !diags.HasError() || d.Id() != "config/app" {
+		t.Fatalf("expected a 500 to be reported and the item kept in state, got id=%q %v", d.Id(), diags)
+	}
+}
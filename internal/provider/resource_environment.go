@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -17,12 +19,14 @@ func resourceChefEnvironment() *schema.Resource {
 		UpdateContext: UpdateEnvironment,
 		ReadContext:   ReadEnvironment,
 		DeleteContext: DeleteEnvironment,
+		CustomizeDiff: diffEnvironment,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateEnvironmentName,
 			},
 			"description": {
 				Type:     schema.TypeString,
@@ -30,16 +34,18 @@ func resourceChefEnvironment() *schema.Resource {
 				Default:  "Managed by Terraform",
 			},
 			"default_attributes_json": {
-				Type:      schema.TypeString,
-				Optional:  true,
-				Default:   "{}",
-				StateFunc: jsonStateFunc,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "{}",
+				StateFunc:        jsonStateFunc,
+				DiffSuppressFunc: suppressEquivalentJSON,
 			},
 			"override_attributes_json": {
-				Type:      schema.TypeString,
-				Optional:  true,
-				Default:   "{}",
-				StateFunc: jsonStateFunc,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "{}",
+				StateFunc:        jsonStateFunc,
+				DiffSuppressFunc: suppressEquivalentJSON,
 			},
 			"cookbook_constraints": {
 				Type:     schema.TypeMap,
@@ -47,11 +53,27 @@ func resourceChefEnvironment() *schema.Resource {
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
+				Description:  "Cookbook version constraints by cookbook name, such as `= 1.0.0`, `>= 2.1` or `~> 3.0`.",
+				ValidateFunc: validateCookbookConstraints,
+			},
+			"validate_available": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If set, plans fail when a cookbook constraint is not satisfied by any cookbook version uploaded to the Chef server.",
 			},
 			"json": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"last_modified": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 		},
 	}
 }
@@ -72,13 +94,7 @@ func CreateEnvironment(ctx context.Context, d *schema.ResourceData, meta interfa
 
 	_, err = client.Environments.Create(env)
 	if err != nil {
-		return diag.Diagnostics{
-			{
-				Severity: diag.Error,
-				Summary:  "Error creating environment",
-				Detail:   fmt.Sprint(err),
-			},
-		}
+		return chefErrToDiag("Error creating environment", err, nil)
 	}
 
 	return ReadEnvironment(ctx, d, meta)
@@ -98,15 +114,9 @@ func UpdateEnvironment(ctx context.Context, d *schema.ResourceData, meta interfa
 		}
 	}
 
-	_, err = client.Environments.Put(env)
+	err = client.conditionalPut(ctx, "environments/"+env.Name, env, validatorsFromResourceData(d))
 	if err != nil {
-		return diag.Diagnostics{
-			{
-				Severity: diag.Error,
-				Summary:  "Error updating environment",
-				Detail:   fmt.Sprint(err),
-			},
-		}
+		return chefErrToDiag("Error updating environment", err, nil)
 	}
 
 	return ReadEnvironment(ctx, d, meta)
@@ -115,27 +125,20 @@ func UpdateEnvironment(ctx context.Context, d *schema.ResourceData, meta interfa
 func ReadEnvironment(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*chefClient)
 
-	env, err := client.Environments.Get(d.Get("name").(string))
+	env := &chefc.Environment{}
+	validators, err := client.getWithValidators(ctx, "environments/"+d.Get("name").(string), env)
 	if err != nil {
-		if errRes, ok := err.(*chefc.ErrorResponse); ok {
-			if errRes.Response.StatusCode == 404 {
-				d.SetId("")
-				return nil
-			}
-		} else {
-			return diag.Diagnostics{
-				{
-					Severity: diag.Error,
-					Summary:  "Error reading environment",
-					Detail:   fmt.Sprint(err),
-				},
-			}
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
 		}
+		return chefErrToDiag("Error reading environment", err, nil)
 	}
 
 	d.SetId(env.Name)
 	d.Set("name", env.Name)
 	d.Set("description", env.Description)
+	validators.setResourceData(d)
 	envJson, err := json.Marshal(env)
 	if err != nil {
 		return diag.Diagnostics{
@@ -191,29 +194,110 @@ func DeleteEnvironment(ctx context.Context, d *schema.ResourceData, meta interfa
 
 	path := fmt.Sprintf("environments/%s", name)
 
-	httpReq, err := client.NewRequest("DELETE", path, nil)
+	httpReq, err := newRequestWithContext(ctx, client.Client, "DELETE", path, nil)
 	if err != nil {
-		return diag.Diagnostics{
-			{
-				Severity: diag.Error,
-				Summary:  "Error deleting environment",
-				Detail:   fmt.Sprint(err),
-			},
-		}
+		return chefErrToDiag("Error deleting environment", err, nil)
 	}
 
 	if _, err = client.Do(httpReq, nil); err == nil {
 		d.SetId("")
 	} else {
-		return diag.Diagnostics{
-			{
-				Severity: diag.Error,
-				Summary:  "Error deleting environment",
-				Detail:   fmt.Sprint(err),
-			},
+		return chefErrToDiag("Error deleting environment", err, nil)
+	}
+
+	return nil
+}
+
+func validateEnvironmentName(val interface{}, key string) (warns []string, errs []error) {
+	// Every organization has a _default environment, and the server refuses
+	// to create, modify or delete it.
+	if val.(string) == defaultEnvironment {
+		errs = append(errs, fmt.Errorf("%s cannot be _default: the _default environment is built in and cannot be managed", key))
+	}
+	return
+}
+
+func validateCookbookConstraints(val interface{}, key string) (warns []string, errs []error) {
+	constraints := val.(map[string]interface{})
+	names := make([]string, 0, len(constraints))
+	for name := range constraints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		constraint := constraints[name].(string)
+		if _, err := parseChefVersionConstraint(constraint); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s %q is not a valid version constraint: %s", key, name, constraint, err))
 		}
 	}
+	return
+}
+
+// diffEnvironment checks at plan time that every cookbook constraint can be
+// satisfied by an uploaded cookbook when validate_available is set, since an
+// environment pinned to a version that doesn't exist only fails once
+// chef-client runs against it.
+func diffEnvironment(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.Get("validate_available").(bool) || !d.NewValueKnown("cookbook_constraints") {
+		return nil
+	}
+
+	constraints := make(map[string]string)
+	for k, v := range d.Get("cookbook_constraints").(map[string]interface{}) {
+		constraints[k] = v.(string)
+	}
+	if len(constraints) == 0 {
+		return nil
+	}
 
+	universe, err := meta.(*chefClient).Universe.Get()
+	if err != nil {
+		return fmt.Errorf("reading the cookbook universe to validate cookbook_constraints: %s", err)
+	}
+
+	return unsatisfiableConstraints(constraints, universe)
+}
+
+func unsatisfiableConstraints(constraints map[string]string, universe chefc.Universe) error {
+	names := make([]string, 0, len(constraints))
+	for name := range constraints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		constraint, err := parseChefVersionConstraint(constraints[name])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s %q: %s", name, constraints[name], err))
+			continue
+		}
+
+		book, ok := universe.Books[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s %q: no versions of %s are uploaded", name, constraints[name], name))
+			continue
+		}
+
+		satisfied := false
+		available := make([]string, 0, len(book.Versions))
+		for version := range book.Versions {
+			available = append(available, version)
+			if v, _, err := parseChefVersion(version); err == nil && constraint.satisfiedBy(v) {
+				satisfied = true
+			}
+		}
+		if !satisfied {
+			sort.Strings(available)
+			problems = append(problems, fmt.Sprintf("%s %q: no uploaded version matches (available: %s)",
+				name, constraints[name], strings.Join(available, ", ")))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("cookbook_constraints cannot be satisfied:\n  %s", strings.Join(problems, "\n  "))
+	}
 	return nil
 }
 
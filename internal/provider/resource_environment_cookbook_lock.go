@@ -0,0 +1,240 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// resourceChefEnvironmentCookbookLock pins an environment's cookbook
+// versions to the ones a Policyfile lock resolved, for nodes that still
+// run from environments while others have moved to Policyfiles.
+func resourceChefEnvironmentCookbookLock() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateEnvironmentCookbookLock,
+		UpdateContext: UpdateEnvironmentCookbookLock,
+		ReadContext:   ReadEnvironmentCookbookLock,
+		DeleteContext: DeleteEnvironmentCookbookLock,
+		CustomizeDiff: diffEnvironmentCookbookLock,
+
+		Schema: map[string]*schema.Schema{
+			"environment_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"policyfile_lock_json": {
+				Type:             schema.TypeString,
+				Required:         true,
+				StateFunc:        jsonStateFunc,
+				DiffSuppressFunc: suppressEquivalentJSON,
+				ValidateFunc:     validatePolicyfileLock,
+			},
+			"cookbook_versions": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// policyfileLockConstraints returns the environment cookbook constraints
+// equivalent to a Policyfile lock: an exact pin for each locked cookbook.
+func policyfileLockConstraints(lockJson string) (map[string]string, error) {
+	var lock struct {
+		CookbookLocks map[string]chefc.CookbookLock `json:"cookbook_locks"`
+	}
+	if err := json.Unmarshal([]byte(lockJson), &lock); err != nil {
+		return nil, err
+	}
+	if len(lock.CookbookLocks) == 0 {
+		return nil, fmt.Errorf("the lock has no cookbook_locks")
+	}
+
+	constraints := make(map[string]string)
+	for name, cookbook := range lock.CookbookLocks {
+		if cookbook.Version == "" {
+			return nil, fmt.Errorf("cookbook %s has no version in the lock", name)
+		}
+		constraints[name] = "= " + cookbook.Version
+	}
+	return constraints, nil
+}
+
+func validatePolicyfileLock(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := policyfileLockConstraints(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%s is not a usable Policyfile lock: %s", key, err))
+	}
+	return
+}
+
+func CreateEnvironmentCookbookLock(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := writeEnvironmentCookbookLock(d, meta); diags != nil {
+		return diags
+	}
+
+	d.SetId(d.Get("environment_name").(string))
+	return ReadEnvironmentCookbookLock(ctx, d, meta)
+}
+
+func UpdateEnvironmentCookbookLock(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := writeEnvironmentCookbookLock(d, meta); diags != nil {
+		return diags
+	}
+
+	return ReadEnvironmentCookbookLock(ctx, d, meta)
+}
+
+func ReadEnvironmentCookbookLock(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	env, err := client.Environments.Get(d.Id())
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading environment", err, cty.GetAttrPath("environment_name"))
+	}
+
+	// Only the cookbooks from the lock are tracked, so that constraints on
+	// other cookbooks managed elsewhere don't show up as drift.
+	constraints, _ := policyfileLockConstraints(d.Get("policyfile_lock_json").(string))
+	live := make(map[string]interface{})
+	for name := range constraints {
+		if constraint, ok := env.CookbookVersions[name]; ok {
+			live[name] = constraint
+		}
+	}
+
+	d.Set("environment_name", env.Name)
+	d.Set("cookbook_versions", live)
+	return nil
+}
+
+func DeleteEnvironmentCookbookLock(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	env, err := client.Environments.Get(d.Id())
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading environment", err, cty.GetAttrPath("environment_name"))
+	}
+
+	for name := range d.Get("cookbook_versions").(map[string]interface{}) {
+		delete(env.CookbookVersions, name)
+	}
+	if _, err := client.Environments.Put(env); err != nil {
+		return chefErrToDiag("Error updating environment", err, cty.GetAttrPath("environment_name"))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// diffEnvironmentCookbookLock plans an update whenever the environment's
+// constraints no longer match the lock, whether the lock or the
+// environment changed.
+func diffEnvironmentCookbookLock(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	constraints, err := policyfileLockConstraints(d.Get("policyfile_lock_json").(string))
+	if err != nil {
+		// Left to validation, or to apply when the lock is not yet known.
+		return nil
+	}
+
+	desired := make(map[string]interface{})
+	for name, constraint := range constraints {
+		desired[name] = constraint
+	}
+	if !reflect.DeepEqual(d.Get("cookbook_versions").(map[string]interface{}), desired) {
+		return d.SetNew("cookbook_versions", desired)
+	}
+	return nil
+}
+
+func writeEnvironmentCookbookLock(d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	constraints, err := policyfileLockConstraints(d.Get("policyfile_lock_json").(string))
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Error parsing Policyfile lock",
+				Detail:        fmt.Sprint(err),
+				AttributePath: cty.GetAttrPath("policyfile_lock_json"),
+			},
+		}
+	}
+
+	// Environments resolve cookbooks from the classic cookbook store, so
+	// every locked version must have been uploaded there as well.
+	var missing []string
+	for name, constraint := range constraints {
+		version := strings.TrimPrefix(constraint, "= ")
+		if _, err := client.Cookbooks.GetVersion(name, version); err != nil {
+			if !isChefNotFound(err) {
+				return diag.Diagnostics{
+					{
+						Severity:      diag.Error,
+						Summary:       "Error reading cookbook",
+						Detail:        fmt.Sprintf("%s %s: %s", name, version, err),
+						AttributePath: cty.GetAttrPath("policyfile_lock_json"),
+					},
+				}
+			}
+			missing = append(missing, name+" "+version)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  "Locked cookbooks are not uploaded",
+				Detail: fmt.Sprintf("The Policyfile lock pins cookbook versions that are not on the Chef server: %s. "+
+					"Upload them with knife cookbook upload before pinning them in an environment.", strings.Join(missing, ", ")),
+				AttributePath: cty.GetAttrPath("policyfile_lock_json"),
+			},
+		}
+	}
+
+	env, err := client.Environments.Get(d.Get("environment_name").(string))
+	if err != nil {
+		return chefErrToDiag("Error reading environment", err, cty.GetAttrPath("environment_name"))
+	}
+
+	if env.CookbookVersions == nil {
+		env.CookbookVersions = make(map[string]string)
+	}
+	// Drop pins for cookbooks that were in the previous lock but no longer
+	// are, then apply the current lock's pins.
+	previous, _ := d.GetChange("cookbook_versions")
+	for name := range previous.(map[string]interface{}) {
+		delete(env.CookbookVersions, name)
+	}
+	for name, constraint := range constraints {
+		env.CookbookVersions[name] = constraint
+	}
+
+	if _, err := client.Environments.Put(env); err != nil {
+		return chefErrToDiag("Error updating environment", err, cty.GetAttrPath("environment_name"))
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+const testPolicyfileLock = `{
+	"name": "web",
+	"cookbook_locks": {
+		"nginx": {"version": "2.7.6", "identifier": "aaa"},
+		"apt": {"version": "7.4.0", "identifier": "bbb"}
+	}
+}`
+
+func TestPolicyfileLockConstraints(t *testing.T) {
+	constraints, err := policyfileLockConstraints(testPolicyfileLock)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if constraints["nginx"] != "= 2.7.6" || constraints["apt"] != "= 7.4.0" {
+		t.Fatalf("unexpected constraints %v", constraints)
+	}
+
+	if _, err := policyfileLockConstraints(`{"name": "web"}`); err == nil {
+		t.Fatal("expected an error for a lock without cookbook_locks")
+	}
+}
+
+func TestCreateEnvironmentCookbookLock(t *testing.T) {
+	var written chefc.Environment
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/organizations/test/cookbooks/"):
+			w.Write([]byte(`{"cookbook_name":"x"}`))
+		case r.Method == "GET" && r.URL.Path == "/organizations/test/environments/prod":
+			if written.Name != "" {
+				json.NewEncoder(w).Encode(written)
+				return
+			}
+			w.Write([]byte(`{"name":"prod","cookbook_versions":{"users":"~> 1.0","nginx":"= 1.0.0"}}`))
+		case r.Method == "PUT" && r.URL.Path == "/organizations/test/environments/prod":
+			json.NewDecoder(r.Body).Decode(&written)
+			json.NewEncoder(w).Encode(written)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefEnvironmentCookbookLock().Schema, map[string]interface{}{
+		"environment_name":     "prod",
+		"policyfile_lock_json": testPolicyfileLock,
+	})
+	if diags := CreateEnvironmentCookbookLock(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	want := map[string]string{"users": "~> 1.0", "nginx": "= 2.7.6", "apt": "= 7.4.0"}
+	for name, constraint := range want {
+		if written.CookbookVersions[name] != constraint {
+			t.Fatalf("unexpected cookbook_versions written: %v", written.CookbookVersions)
+		}
+	}
+	if got := d.Get("cookbook_versions").(map[string]interface{}); len(got) != 2 {
+		t.Fatalf("expected only the locked cookbooks to be tracked, got %v", got)
+	}
+}
+
+func TestCreateEnvironmentCookbookLock_missingCookbooks(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/organizations/test/cookbooks/apt/7.4.0" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"cookbook_name":"apt"}`))
+			return
+		}
+		if r.Method != "GET" || !strings.HasPrefix(r.URL.Path, "/organizations/test/cookbooks/") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefEnvironmentCookbookLock().Schema, map[string]interface{}{
+		"environment_name":     "prod",
+		"policyfile_lock_json": testPolicyfileLock,
+	})
+	diags := CreateEnvironmentCookbookLock(context.Background(), d, c)
+	if !diags.HasError() || !strings.Contains(diags[0].Detail, "nginx 2.7.6") {
+		t.Fatalf("expected an error naming nginx 2.7.6, got %v", diags)
+	}
+}
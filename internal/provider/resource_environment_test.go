@@ -117,3 +117,21 @@ EOT
   }
 }
 `
+
+func TestValidateEnvironment(t *testing.T) {
+	if _, errs := validateEnvironmentName("_default", "name"); len(errs) == 0 {
+		t.Error("expected the _default environment to be rejected")
+	}
+	if _, errs := validateEnvironmentName("production", "name"); len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+
+	valid := map[string]interface{}{"a": "= 1.0.0", "b": ">= 2.1", "c": "~> 3.0", "d": "1.2.3"}
+	if _, errs := validateCookbookConstraints(valid, "cookbook_constraints"); len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	invalid := map[string]interface{}{"a": "latest", "b": "=> 1.0", "c": "~> 3.0"}
+	if _, errs := validateCookbookConstraints(invalid, "cookbook_constraints"); len(errs) != 2 {
+		t.Errorf("expected two errors, got %v", errs)
+	}
+}
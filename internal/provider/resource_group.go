@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func resourceChefGroup() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateGroup,
+		UpdateContext: UpdateGroup,
+		ReadContext:   ReadGroup,
+		DeleteContext: DeleteGroup,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"actors": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Users and clients in the group, without distinguishing between them. Each name is added as a client if the organization has a client by that name, and as a user otherwise. Names listed here should not also be listed in `users` or `clients`.",
+			},
+			"users": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"clients": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"groups": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func CreateGroup(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+	name := d.Get("name").(string)
+
+	if _, err := c.Groups.Create(chefc.Group{Name: name, GroupName: name}); err != nil {
+		return chefErrToDiag("Error creating group", err, cty.GetAttrPath("name"))
+	}
+
+	d.SetId(name)
+	return UpdateGroup(ctx, d, meta)
+}
+
+func UpdateGroup(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	// The update replaces the whole membership, so it must not interleave
+	// with chef_group_member's read and write of the same group.
+	unlock := lockGroup(c.Client, d.Get("name").(string))
+	update, err := groupUpdateFromResourceData(c, d)
+	if err == nil {
+		_, err = c.Groups.Update(update)
+	}
+	unlock()
+	if err != nil {
+		return chefErrToDiag("Error updating group membership", err, cty.GetAttrPath("name"))
+	}
+
+	return ReadGroup(ctx, d, meta)
+}
+
+func ReadGroup(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	group, err := c.Groups.Get(d.Id())
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading group", err, cty.GetAttrPath("name"))
+	}
+
+	// The server reports every user and client as an actor. Only the
+	// actors already tracked in state are kept there, and those are left
+	// out of users and clients so either way of listing a member
+	// round-trips without a diff.
+	tracked := d.Get("actors").(*schema.Set)
+	actors := make([]string, 0)
+	for _, actor := range group.Actors {
+		if tracked.Contains(actor) {
+			actors = append(actors, actor)
+		}
+	}
+
+	d.Set("name", groupName(group, d.Id()))
+	d.Set("actors", actors)
+	d.Set("users", withoutStrings(group.Users, actors))
+	d.Set("clients", withoutStrings(group.Clients, actors))
+	d.Set("groups", withoutStrings(group.Groups, nil))
+	return nil
+}
+
+func DeleteGroup(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	if err := c.Groups.Delete(d.Id()); err != nil && !isChefNotFound(err) {
+		return chefErrToDiag("Error deleting group", err, cty.GetAttrPath("name"))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func groupName(group chefc.Group, fallback string) string {
+	if group.GroupName != "" {
+		return group.GroupName
+	}
+	if group.Name != "" {
+		return group.Name
+	}
+	return fallback
+}
+
+// withoutStrings returns values minus anything in exclude, never nil so
+// that an empty membership list is stored as an empty set.
+func withoutStrings(values, exclude []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !containsString(exclude, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// groupUpdateFromResourceData builds the complete membership to send,
+// sorting each configured actor into the users or clients it belongs with.
+func groupUpdateFromResourceData(c *chefClient, d *schema.ResourceData) (chefc.GroupUpdate, error) {
+	name := d.Get("name").(string)
+	users := d.Get("users").(*schema.Set)
+	clients := d.Get("clients").(*schema.Set)
+
+	update := chefc.GroupUpdate{Name: name, GroupName: name}
+	update.Actors.Users = sortedSetStrings(users)
+	update.Actors.Clients = sortedSetStrings(clients)
+	update.Actors.Groups = sortedSetStrings(d.Get("groups").(*schema.Set))
+
+	for _, actor := range sortedSetStrings(d.Get("actors").(*schema.Set)) {
+		if users.Contains(actor) || clients.Contains(actor) {
+			continue
+		}
+		_, err := c.Clients.Get(actor)
+		switch {
+		case err == nil:
+			update.Actors.Clients = append(update.Actors.Clients, actor)
+		case isChefNotFound(err):
+			update.Actors.Users = append(update.Actors.Users, actor)
+		default:
+			return update, fmt.Errorf("looking up actor %s: %s", actor, err)
+		}
+	}
+	return update, nil
+}
@@ -0,0 +1,232 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// groupMemberAttempts is how many times a membership change is sent before
+// giving up on a group that keeps being changed underneath it.
+const groupMemberAttempts = 5
+
+// groupMemberRetryDelay is the base backoff between those attempts.
+var groupMemberRetryDelay = time.Second
+
+// resourceChefGroupMember adds a single user, client or group to a group
+// without managing the rest of its membership, so that several teams can
+// each add themselves to a shared group.
+func resourceChefGroupMember() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateGroupMember,
+		ReadContext:   ReadGroupMember,
+		DeleteContext: DeleteGroupMember,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: GroupMemberImporter,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"group_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"member_type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "One of `user`, `client` or `group`.",
+				ValidateFunc: validation.StringInSlice([]string{"user", "client", "group"}, false),
+			},
+			"member_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func CreateGroupMember(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	group := d.Get("group_name").(string)
+	memberType := d.Get("member_type").(string)
+	member := d.Get("member_name").(string)
+
+	if err := setGroupMember(ctx, client.Client, group, memberType, member, true); err != nil {
+		return chefErrToDiag("Error adding group member", err, cty.GetAttrPath("group_name"))
+	}
+
+	d.SetId(strings.Join([]string{group, memberType, member}, "/"))
+	return ReadGroupMember(ctx, d, meta)
+}
+
+func ReadGroupMember(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	group, memberType, member := groupMemberFromResourceData(d)
+
+	g, err := client.Groups.Get(group)
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading group", err, cty.GetAttrPath("group_name"))
+	}
+
+	if !containsString(groupMembersOfType(g, memberType), member) {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("group_name", group)
+	d.Set("member_type", memberType)
+	d.Set("member_name", member)
+	return nil
+}
+
+func DeleteGroupMember(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	group, memberType, member := groupMemberFromResourceData(d)
+
+	if err := setGroupMember(ctx, client.Client, group, memberType, member, false); err != nil && !isChefNotFound(err) {
+		return chefErrToDiag("Error removing group member", err, cty.GetAttrPath("group_name"))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func GroupMemberImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	id := d.Id()
+	parts := strings.Split(id, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected group_name/member_type/member_name", id)
+	}
+	if parts[1] != "user" && parts[1] != "client" && parts[1] != "group" {
+		return nil, fmt.Errorf("unexpected member type %q in ID (%s), expected user, client or group", parts[1], id)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// groupMemberFromResourceData returns the group, member type and member an
+// ID refers to. IDs are group_name/member_type/member_name.
+func groupMemberFromResourceData(d *schema.ResourceData) (string, string, string) {
+	if parts := strings.SplitN(d.Id(), "/", 3); len(parts) == 3 {
+		return parts[0], parts[1], parts[2]
+	}
+	return d.Get("group_name").(string), d.Get("member_type").(string), d.Get("member_name").(string)
+}
+
+// groupMembersOfType returns the group's members of one type.
+func groupMembersOfType(g chefc.Group, memberType string) []string {
+	switch memberType {
+	case "client":
+		return g.Clients
+	case "group":
+		return g.Groups
+	default:
+		return g.Users
+	}
+}
+
+// groupLocks holds a lock per group, so that the provider's own changes to
+// a group's membership are made one at a time. Every resource that reads a
+// group and writes it back, or replaces its membership, takes the lock.
+var groupLocks = struct {
+	sync.Mutex
+	locks map[string]*sync.Mutex
+}{locks: make(map[string]*sync.Mutex)}
+
+// lockGroup locks the group on the server client talks to and returns the
+// function that unlocks it.
+func lockGroup(client *chefc.Client, group string) func() {
+	return lockGroupURL(client.BaseURL.String() + "groups/" + group)
+}
+
+// lockOrgGroup locks a group in the named organization, reached through the
+// server-level client as orgDo does.
+func lockOrgGroup(c *chefClient, org, group string) func() {
+	return lockGroupURL(c.Global.BaseURL.String() + "organizations/" + org + "/groups/" + group)
+}
+
+func lockGroupURL(key string) func() {
+	groupLocks.Lock()
+	lock, ok := groupLocks.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		groupLocks.locks[key] = lock
+	}
+	groupLocks.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// setGroupMember adds member to, or removes it from, the group while
+// leaving every other member as it is.
+//
+// The server replaces a group's whole membership on each update, and the
+// last writer wins. Changes made by this provider hold the group's lock
+// from the read until the change is seen to have stuck, so they can't
+// overwrite each other. Writers outside the provider can still overwrite
+// a change between its read and write; each attempt therefore re-reads
+// the group, and a read showing the change was lost starts another one.
+func setGroupMember(ctx context.Context, client *chefc.Client, group, memberType, member string, present bool) error {
+	defer lockGroup(client, group)()
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(backoffDelay(groupMemberRetryDelay, attempt-1))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		}
+
+		g, err := client.Groups.Get(group)
+		if err != nil {
+			return err
+		}
+		if containsString(groupMembersOfType(g, memberType), member) == present {
+			return nil
+		}
+		if attempt == groupMemberAttempts {
+			return fmt.Errorf("group %s was changed by another writer on each of %d attempts to update it", group, groupMemberAttempts)
+		}
+
+		update := chefc.GroupUpdate{Name: groupName(g, group), GroupName: groupName(g, group)}
+		update.Actors.Users = withoutStrings(g.Users, nil)
+		update.Actors.Clients = withoutStrings(g.Clients, nil)
+		update.Actors.Groups = withoutStrings(g.Groups, nil)
+
+		members := &update.Actors.Users
+		switch memberType {
+		case "client":
+			members = &update.Actors.Clients
+		case "group":
+			members = &update.Actors.Groups
+		}
+		if present {
+			*members = append(*members, member)
+		} else {
+			*members = withoutStrings(*members, []string{member})
+		}
+
+		if _, err := client.Groups.Update(update); err != nil {
+			return err
+		}
+	}
+}
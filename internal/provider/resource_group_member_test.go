@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestGroupMember(t *testing.T) {
+	groupMemberRetryDelay = 0
+	defer func() { groupMemberRetryDelay = time.Second }()
+
+	var mu sync.Mutex
+	group := chefc.Group{Name: "shared", Users: []string{"alice"}, Groups: []string{"admins"}}
+
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/organizations/test/groups/shared" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "PUT" {
+			var update chefc.GroupUpdate
+			json.NewDecoder(r.Body).Decode(&update)
+			group.Users, group.Clients, group.Groups = update.Actors.Users, update.Actors.Clients, update.Actors.Groups
+		}
+		json.NewEncoder(w).Encode(group)
+	})
+
+	// Both members are added at once. The server keeps whichever update
+	// arrives last, so each addition has to survive the other's write.
+	var wg sync.WaitGroup
+	for _, member := range []struct{ kind, name string }{{"user", "bob"}, {"client", "ci"}} {
+		member := member
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d := schema.TestResourceDataRaw(t, resourceChefGroupMember().Schema, map[string]interface{}{
+				"group_name":  "shared",
+				"member_type": member.kind,
+				"member_name": member.name,
+			})
+			if diags := CreateGroupMember(context.Background(), d, c); diags.HasError() {
+				t.Errorf("%s: err: %v", member.name, diags)
+				return
+			}
+			if want := "shared/" + member.kind + "/" + member.name; d.Id() != want {
+				t.Errorf("expected ID %s, got %s", want, d.Id())
+			}
+		}()
+	}
+	wg.Wait()
+
+	users := append([]string{}, group.Users...)
+	sort.Strings(users)
+	if got := fmt.Sprint(users, group.Clients, group.Groups); got != "[alice bob] [ci] [admins]" {
+		t.Fatalf("unexpected membership %s", got)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceChefGroupMember().Schema, map[string]interface{}{})
+	d.SetId("shared/user/bob")
+	if diags := DeleteGroupMember(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if got := fmt.Sprint(group.Users, group.Clients, group.Groups); got != "[alice] [ci] [admins]" {
+		t.Fatalf("expected only bob to be removed, got %s", got)
+	}
+}
+
+func TestSetGroupMember_overwritten(t *testing.T) {
+	groupMemberRetryDelay = 0
+	defer func() { groupMemberRetryDelay = time.Second }()
+
+	var puts int
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "PUT" {
+			puts++
+		}
+		// Another writer always replaces the membership, so the member
+		// never sticks.
+		json.NewEncoder(w).Encode(chefc.Group{Name: "shared", Users: []string{"alice"}})
+	})
+
+	err := setGroupMember(context.Background(), c.Client, "shared", "user", "bob", true)
+	if err == nil {
+		t.Fatal("expected an error when every update is overwritten")
+	}
+	if puts != groupMemberAttempts {
+		t.Fatalf("expected %d updates, got %d", groupMemberAttempts, puts)
+	}
+}
+
+func TestSetGroupMember_concurrent(t *testing.T) {
+	var mu sync.Mutex
+	var puts int
+	group := chefc.Group{Name: "shared"}
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "PUT" {
+			var update chefc.GroupUpdate
+			json.NewDecoder(r.Body).Decode(&update)
+			mu.Lock()
+			puts++
+			group.Users = update.Actors.Users
+			mu.Unlock()
+		}
+		mu.Lock()
+		current := group
+		mu.Unlock()
+		// Give concurrent changes time to read the same membership.
+		time.Sleep(10 * time.Millisecond)
+		json.NewEncoder(w).Encode(current)
+	})
+
+	members := []string{"alice", "bob", "carol", "dave"}
+	var wg sync.WaitGroup
+	for _, member := range members {
+		member := member
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := setGroupMember(context.Background(), c.Client, "shared", "user", member, true); err != nil {
+				t.Errorf("%s: %s", member, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(group.Users) != len(members) {
+		t.Fatalf("expected every member to be added, got %v", group.Users)
+	}
+	if puts != len(members) {
+		t.Fatalf("expected one update per member, got %d", puts)
+	}
+}
+
+func TestLockOrgGroup_sharesLockGroup(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	global, err := chefc.NewClient(&chefc.Config{
+		Name:    c.Auth.ClientName,
+		Key:     testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {}).Key,
+		BaseURL: strings.TrimSuffix(c.BaseURL.String(), "organizations/test/"),
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c.Global = global
+
+	unlock := lockOrgGroup(c, "test", "admins")
+	locked := make(chan struct{})
+	go func() {
+		defer lockGroup(c.Client, "admins")()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("expected the organization's group to share the provider's lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	<-locked
+}
+
+func TestGroupMemberImporter(t *testing.T) {
+	for id, ok := range map[string]bool{
+		"shared/user/bob":  true,
+		"shared/group/ops": true,
+		"shared/robot/bob": false,
+		"shared/bob":       false,
+		"/user/bob":        false,
+	} {
+		d := schema.TestResourceDataRaw(t, resourceChefGroupMember().Schema, map[string]interface{}{})
+		d.SetId(id)
+		if _, err := GroupMemberImporter(context.Background(), d, nil); (err == nil) != ok {
+			t.Errorf("%s: unexpected result %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// resourceChefGroupMembers keeps a group's user and client members exactly
+// matching lists that usually come from an identity provider export.
+// Members listed in ignore_members are never removed.
+func resourceChefGroupMembers() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateGroupMembers,
+		UpdateContext: UpdateGroupMembers,
+		ReadContext:   ReadGroupMembers,
+		DeleteContext: DeleteGroupMembers,
+
+		Schema: map[string]*schema.Schema{
+			"group": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"users": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"clients": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"ignore_members": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Users or clients, such as break-glass accounts, that are never removed from the group, even when missing from users or clients.",
+			},
+		},
+	}
+}
+
+func CreateGroupMembers(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := syncGroupMembers(d, meta); diags != nil {
+		return diags
+	}
+
+	d.SetId(d.Get("group").(string))
+	return ReadGroupMembers(ctx, d, meta)
+}
+
+func UpdateGroupMembers(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := syncGroupMembers(d, meta); diags != nil {
+		return diags
+	}
+
+	return ReadGroupMembers(ctx, d, meta)
+}
+
+func ReadGroupMembers(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	group, err := client.Groups.Get(d.Id())
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading group", err, cty.GetAttrPath("group"))
+	}
+
+	ignored := d.Get("ignore_members").(*schema.Set)
+	d.Set("group", group.Name)
+	d.Set("users", withoutIgnoredMembers(group.Users, ignored))
+	d.Set("clients", withoutIgnoredMembers(group.Clients, ignored))
+	return nil
+}
+
+func DeleteGroupMembers(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Members are left in the group, so that removing the sync can't lock
+	// everyone out of whatever the group grants.
+	d.SetId("")
+	return nil
+}
+
+// withoutIgnoredMembers returns the sorted members not in ignored.
+func withoutIgnoredMembers(members []string, ignored *schema.Set) []string {
+	result := make([]string, 0, len(members))
+	for _, m := range members {
+		if !ignored.Contains(m) {
+			result = append(result, m)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// desiredGroupMembers returns the declared members plus any ignored ones
+// the group currently has, sorted.
+func desiredGroupMembers(declared *schema.Set, current []string, ignored *schema.Set) []string {
+	result := sortedSetStrings(declared)
+	for _, m := range current {
+		if ignored.Contains(m) && !declared.Contains(m) {
+			result = append(result, m)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// syncGroupMembers updates the group's users and clients to the declared
+// lists, leaving nested groups and ignored members untouched, and only
+// sends an update when membership differs.
+func syncGroupMembers(d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	name := d.Get("group").(string)
+	defer lockGroup(client.Client, name)()
+
+	group, err := client.Groups.Get(name)
+	if err != nil {
+		return chefErrToDiag("Error reading group", err, cty.GetAttrPath("group"))
+	}
+
+	ignored := d.Get("ignore_members").(*schema.Set)
+	users := desiredGroupMembers(d.Get("users").(*schema.Set), group.Users, ignored)
+	clients := desiredGroupMembers(d.Get("clients").(*schema.Set), group.Clients, ignored)
+
+	currentUsers := append([]string{}, group.Users...)
+	currentClients := append([]string{}, group.Clients...)
+	sort.Strings(currentUsers)
+	sort.Strings(currentClients)
+	if reflect.DeepEqual(users, currentUsers) && reflect.DeepEqual(clients, currentClients) {
+		return nil
+	}
+
+	update := chefc.GroupUpdate{Name: name, GroupName: name}
+	update.Actors.Users = users
+	update.Actors.Clients = clients
+	update.Actors.Groups = append([]string{}, group.Groups...)
+	if _, err := client.Groups.Update(update); err != nil {
+		return chefErrToDiag("Error updating group membership", err, cty.GetAttrPath("group"))
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestCreateGroupMembers(t *testing.T) {
+	group := chefc.Group{
+		Name:    "ops",
+		Users:   []string{"alice", "mallory", "breakglass"},
+		Clients: []string{"ci"},
+		Groups:  []string{"admins"},
+	}
+	var puts int
+
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/organizations/test/groups/ops" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "PUT" {
+			puts++
+			var update chefc.GroupUpdate
+			json.NewDecoder(r.Body).Decode(&update)
+			group.Users, group.Clients, group.Groups = update.Actors.Users, update.Actors.Clients, update.Actors.Groups
+		}
+		json.NewEncoder(w).Encode(group)
+	})
+
+	config := map[string]interface{}{
+		"group":          "ops",
+		"users":          []interface{}{"alice", "bob"},
+		"clients":        []interface{}{"ci"},
+		"ignore_members": []interface{}{"breakglass"},
+	}
+	d := schema.TestResourceDataRaw(t, resourceChefGroupMembers().Schema, config)
+	if diags := CreateGroupMembers(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if got := fmt.Sprint(group.Users, group.Clients, group.Groups); got != "[alice bob breakglass] [ci] [admins]" {
+		t.Fatalf("unexpected membership %s", got)
+	}
+	if got := sortedSetStrings(d.Get("users").(*schema.Set)); fmt.Sprint(got) != "[alice bob]" {
+		t.Fatalf("expected ignored members to be left out of state, got %v", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceChefGroupMembers().Schema, config)
+	if diags := CreateGroupMembers(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if puts != 1 {
+		t.Fatalf("expected no update when membership already matches, got %d updates", puts)
+	}
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestGroupLifecycle(t *testing.T) {
+	var group *chefc.Group
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/organizations/test/groups":
+			group = &chefc.Group{Name: "ops", GroupName: "ops"}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"uri":"https://chef/organizations/test/groups/ops"}`))
+		case r.URL.Path == "/organizations/test/clients/ci":
+			json.NewEncoder(w).Encode(chefc.ApiClient{Name: "ci"})
+		case r.URL.Path == "/organizations/test/clients/alice":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":["not found"]}`))
+		case r.URL.Path == "/organizations/test/groups/ops":
+			if group == nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":["not found"]}`))
+				return
+			}
+			switch r.Method {
+			case "PUT":
+				var update chefc.GroupUpdate
+				json.NewDecoder(r.Body).Decode(&update)
+				group.Users, group.Clients, group.Groups = update.Actors.Users, update.Actors.Clients, update.Actors.Groups
+				group.Actors = append(append([]string{}, group.Users...), group.Clients...)
+				sort.Strings(group.Actors)
+				json.NewEncoder(w).Encode(update)
+				return
+			case "DELETE":
+				defer func() { group = nil }()
+			}
+			json.NewEncoder(w).Encode(group)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefGroup().Schema, map[string]interface{}{
+		"name":   "ops",
+		"actors": []interface{}{"alice", "ci"},
+		"users":  []interface{}{"bob"},
+		"groups": []interface{}{"admins"},
+	})
+	if diags := CreateGroup(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if got := fmt.Sprint(group.Users, group.Clients, group.Groups); got != "[bob alice] [ci] [admins]" {
+		t.Fatalf("unexpected membership %s", got)
+	}
+	state := fmt.Sprint(
+		sortedSetStrings(d.Get("actors").(*schema.Set)),
+		sortedSetStrings(d.Get("users").(*schema.Set)),
+		sortedSetStrings(d.Get("clients").(*schema.Set)),
+		sortedSetStrings(d.Get("groups").(*schema.Set)),
+	)
+	if state != "[alice ci] [bob] [] [admins]" {
+		t.Fatalf("expected configured membership to round-trip, got %s", state)
+	}
+
+	if diags := DeleteGroup(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	d.SetId("ops")
+	if diags := ReadGroup(context.Background(), d, c); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected a deleted group to be removed from state, got id=%q %v", d.Id(), diags)
+	}
+}
@@ -0,0 +1,344 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func resourceChefKeyRotation() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateKeyRotation,
+		UpdateContext: UpdateKeyRotation,
+		ReadContext:   ReadKeyRotation,
+		DeleteContext: DeleteKeyRotation,
+		CustomizeDiff: diffKeyRotation,
+
+		Schema: map[string]*schema.Schema{
+			"actor_type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateKeyActorType,
+			},
+			"actor": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"rotation_interval": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateRotationInterval,
+			},
+			"key_name_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  "rotated",
+			},
+			"key_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"public_key": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"private_key_pem": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"last_rotated": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"next_rotation": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// keyActor is the user or client whose keys a rotation manages.
+type keyActor struct {
+	Type    string
+	Name    string
+	client  *chefc.Client
+	options *clientOptions
+}
+
+func keyActorFromResourceData(d *schema.ResourceData, meta interface{}) keyActor {
+	c := meta.(*chefClient)
+
+	actor := keyActor{
+		Type:   d.Get("actor_type").(string),
+		Name:   d.Get("actor").(string),
+		client: c.Client,
+
+		options: c.options,
+	}
+	if actor.Type == "user" {
+		actor.client = c.Global
+	}
+	return actor
+}
+
+func (a keyActor) path() string {
+	return fmt.Sprintf("%ss/%s", a.Type, a.Name)
+}
+
+func (a keyActor) addKey(ctx context.Context, key chefc.AccessKey) error {
+	return addAccessKey(ctx, a.client, a.path(), key)
+}
+
+func (a keyActor) deleteKey(ctx context.Context, name string) error {
+	return deleteAccessKey(ctx, a.client, a.path()+"/keys/"+name)
+}
+
+// verify authenticates as the actor with privateKey by reading the actor
+// itself, proving the server accepts the new key before the old one goes.
+// The request goes through a client of the actor's own, so that every
+// signature on it, including one re-signed for server_api_version, is made
+// with privateKey, in the authentication version the provider uses.
+func (a keyActor) verify(ctx context.Context, privateKey string) error {
+	opts := a.options
+	if opts == nil {
+		opts = &clientOptions{}
+	}
+	config := opts.Connection
+	config.Name = a.Name
+	config.Key = privateKey
+	config.BaseURL = a.client.BaseURL.String()
+	config.AuthenticationVersion = a.client.Auth.AuthenticationVersion
+
+	client, err := opts.newClient(config)
+	if err != nil {
+		return err
+	}
+
+	req, err := newRequestWithContext(ctx, client, "GET", a.path(), nil)
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(req, nil)
+	if res != nil {
+		defer res.Body.Close()
+	}
+	return err
+}
+
+func CreateKeyRotation(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	diags := rotateKey(ctx, d, meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	actor := keyActorFromResourceData(d, meta)
+	d.SetId(actor.Type + "/" + actor.Name)
+	return append(diags, ReadKeyRotation(ctx, d, meta)...)
+}
+
+func UpdateKeyRotation(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if keyRotationDue(d.Get("last_rotated").(string), d.Get("rotation_interval").(string), time.Now()) {
+		if diags = rotateKey(ctx, d, meta); diags.HasError() {
+			return diags
+		}
+	}
+
+	return append(diags, ReadKeyRotation(ctx, d, meta)...)
+}
+
+func ReadKeyRotation(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	actor := keyActorFromResourceData(d, meta)
+
+	key, err := getAccessKey(ctx, actor.client, actor.path()+"/keys/"+d.Get("key_name").(string))
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading rotated key", err, cty.GetAttrPath("key_name"))
+	}
+	d.Set("public_key", key.PublicKey)
+
+	interval, _ := parseRotationInterval(d.Get("rotation_interval").(string))
+	if lastRotated, err := time.Parse(time.RFC3339, d.Get("last_rotated").(string)); err == nil {
+		d.Set("next_rotation", lastRotated.Add(interval).Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+func DeleteKeyRotation(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	actor := keyActorFromResourceData(d, meta)
+
+	if err := actor.deleteKey(ctx, d.Get("key_name").(string)); err != nil && !isChefNotFound(err) {
+		return chefErrToDiag("Error deleting rotated key", err, cty.GetAttrPath("key_name"))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// diffKeyRotation plans a rotation once the interval since the last one has
+// elapsed, so the rotation shows up in the plan rather than only at apply.
+func diffKeyRotation(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	if keyRotationDue(d.Get("last_rotated").(string), d.Get("rotation_interval").(string), time.Now()) {
+		for _, key := range []string{"key_name", "public_key", "private_key_pem", "last_rotated", "next_rotation"} {
+			if err := d.SetNewComputed(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func keyRotationDue(lastRotated, interval string, now time.Time) bool {
+	last, err := time.Parse(time.RFC3339, lastRotated)
+	if err != nil {
+		return true
+	}
+	d, err := parseRotationInterval(interval)
+	if err != nil {
+		return false
+	}
+	return !now.Before(last.Add(d))
+}
+
+// rotateKey adds a freshly generated key, checks that the server accepts it
+// and only then removes the key it replaces, so that the actor is never left
+// without a working key.
+func rotateKey(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	actor := keyActorFromResourceData(d, meta)
+	now := time.Now().UTC()
+
+	privateKey, publicKey, err := generateKeyPair()
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  "Error generating key pair",
+				Detail:   fmt.Sprint(err),
+			},
+		}
+	}
+
+	oldName := d.Get("key_name").(string)
+	newName := d.Get("key_name_prefix").(string) + "-" + now.Format("20060102T150405Z")
+
+	err = actor.addKey(ctx, chefc.AccessKey{
+		Name:           newName,
+		PublicKey:      publicKey,
+		ExpirationDate: "infinity",
+	})
+	if err != nil {
+		return chefErrToDiag("Error adding rotated key", err, cty.GetAttrPath("actor"))
+	}
+
+	if err := actor.verify(ctx, privateKey); err != nil {
+		// Leave the old key in place and take the unverified one back out.
+		if derr := actor.deleteKey(ctx, newName); derr != nil {
+			err = fmt.Errorf("%s; additionally, removing the new key %s failed: %s", err, newName, derr)
+		}
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Error verifying rotated key",
+				Detail:        fmt.Sprintf("Authenticating as %s with the new key failed, so the current key was kept: %s", actor.path(), err),
+				AttributePath: cty.GetAttrPath("actor"),
+			},
+		}
+	}
+
+	d.Set("key_name", newName)
+	d.Set("public_key", publicKey)
+	d.Set("private_key_pem", privateKey)
+	d.Set("last_rotated", now.Format(time.RFC3339))
+
+	if oldName != "" && oldName != newName {
+		if err := actor.deleteKey(ctx, oldName); err != nil && !isChefNotFound(err) {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Warning,
+					Summary:       "Error removing the previous key",
+					Detail:        fmt.Sprintf("The new key %s is in place, but %s could not be removed and should be deleted by hand: %s", newName, oldName, err),
+					AttributePath: cty.GetAttrPath("key_name"),
+				},
+			}
+		}
+	}
+
+	return nil
+}
+
+// generateKeyPair returns a new RSA private key and its public key, both
+// PEM encoded in the formats Chef Server uses.
+func generateKeyPair() (string, string, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", "", err
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return string(privatePEM), string(publicPEM), nil
+}
+
+// parseRotationInterval accepts Go duration strings and, since rotation
+// periods are usually measured in days, a whole number of days such as 90d.
+func parseRotationInterval(s string) (time.Duration, error) {
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid number of days %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err == nil && d <= 0 {
+		err = fmt.Errorf("interval must be positive")
+	}
+	return d, err
+}
+
+func validateRotationInterval(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := parseRotationInterval(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%s must be a number of days such as \"90d\" or a duration string such as \"720h\": %s", key, err))
+	}
+	return
+}
+
+func validateKeyActorType(val interface{}, key string) (warns []string, errs []error) {
+	switch actorType := val.(string); actorType {
+	case "user", "client":
+	default:
+		errs = append(errs, fmt.Errorf("%s must be one of \"user\" or \"client\", got %q", key, actorType))
+	}
+	return
+}
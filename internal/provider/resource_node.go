@@ -30,28 +30,38 @@ func resourceChefNode() *schema.Resource {
 				Default:  "_default",
 			},
 			"automatic_attributes_json": {
-				Type:      schema.TypeString,
-				Optional:  true,
-				Default:   "{}",
-				StateFunc: jsonStateFunc,
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Attributes reported by the node's own chef-client runs.",
 			},
 			"normal_attributes_json": {
-				Type:      schema.TypeString,
-				Optional:  true,
-				Default:   "{}",
-				StateFunc: jsonStateFunc,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "{}",
+				StateFunc:        jsonStateFunc,
+				DiffSuppressFunc: suppressEquivalentJSON,
 			},
 			"default_attributes_json": {
-				Type:      schema.TypeString,
-				Optional:  true,
-				Default:   "{}",
-				StateFunc: jsonStateFunc,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "{}",
+				StateFunc:        jsonStateFunc,
+				DiffSuppressFunc: suppressEquivalentJSON,
 			},
 			"override_attributes_json": {
-				Type:      schema.TypeString,
-				Optional:  true,
-				Default:   "{}",
-				StateFunc: jsonStateFunc,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "{}",
+				StateFunc:        jsonStateFunc,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"last_modified": {
+				Type:     schema.TypeString,
+				Computed: true,
 			},
 			"run_list": {
 				Type:     schema.TypeList,
@@ -81,13 +91,7 @@ func CreateNode(ctx context.Context, d *schema.ResourceData, meta interface{}) d
 
 	_, err = client.Nodes.Post(*node)
 	if err != nil {
-		return diag.Diagnostics{
-			{
-				Severity: diag.Error,
-				Summary:  "Error creating node",
-				Detail:   fmt.Sprint(err),
-			},
-		}
+		return chefErrToDiag("Error creating node", err, nil)
 	}
 
 	return ReadNode(ctx, d, meta)
@@ -107,15 +111,24 @@ func UpdateNode(ctx context.Context, d *schema.ResourceData, meta interface{}) d
 		}
 	}
 
-	_, err = client.Nodes.Put(*node)
+	// Automatic attributes belong to chef-client, which may have saved new
+	// ones since the last refresh. A node PUT replaces them wholesale, so
+	// the live node's are read just before it and sent back. Chef Server
+	// sends no ETag or Last-Modified for nodes, so the PUT is unconditional:
+	// a chef-client save landing between the read and the PUT is
+	// overwritten, and its automatic attributes are lost until the next
+	// run saves them again. The PUT is only made conditional on servers
+	// that do send validators.
+	var live chefc.Node
+	validators, err := client.getWithValidators(ctx, "nodes/"+node.Name, &live)
 	if err != nil {
-		return diag.Diagnostics{
-			{
-				Severity: diag.Error,
-				Summary:  "Error updating node",
-				Detail:   fmt.Sprint(err),
-			},
-		}
+		return chefErrToDiag("Error reading node", err, nil)
+	}
+	node.AutomaticAttributes = live.AutomaticAttributes
+
+	err = client.conditionalPut(ctx, "nodes/"+node.Name, node, validators)
+	if err != nil {
+		return chefErrToDiag("Error updating node", err, nil)
 	}
 
 	return ReadNode(ctx, d, meta)
@@ -124,27 +137,20 @@ func UpdateNode(ctx context.Context, d *schema.ResourceData, meta interface{}) d
 func ReadNode(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*chefClient)
 
-	node, err := client.Nodes.Get(d.Get("name").(string))
+	var node chefc.Node
+	validators, err := client.getWithValidators(ctx, "nodes/"+d.Get("name").(string), &node)
 	if err != nil {
-		if errRes, ok := err.(*chefc.ErrorResponse); ok {
-			if errRes.Response.StatusCode == 404 {
-				d.SetId("")
-				return nil
-			}
-		} else {
-			return diag.Diagnostics{
-				{
-					Severity: diag.Error,
-					Summary:  "Error reading node",
-					Detail:   fmt.Sprint(err),
-				},
-			}
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
 		}
+		return chefErrToDiag("Error reading node", err, nil)
 	}
 
 	d.SetId(node.Name)
 	d.Set("name", node.Name)
 	d.Set("environment_name", node.Environment)
+	validators.setResourceData(d)
 
 	automaticAttrJson, err := json.Marshal(node.AutomaticAttributes)
 	if err != nil {
@@ -208,13 +214,7 @@ func DeleteNode(ctx context.Context, d *schema.ResourceData, meta interface{}) d
 
 	name := d.Id()
 	if err := client.Nodes.Delete(name); err != nil {
-		return diag.Diagnostics{
-			{
-				Severity: diag.Error,
-				Summary:  "Error deleting node",
-				Detail:   fmt.Sprint(err),
-			},
-		}
+		return chefErrToDiag("Error deleting node", err, nil)
 	}
 
 	d.SetId("")
@@ -231,17 +231,7 @@ func nodeFromResourceData(d *schema.ResourceData) (*chefc.Node, error) {
 		JsonClass:   "Chef::Node",
 	}
 
-	var err error
-
-	err = json.Unmarshal(
-		[]byte(d.Get("automatic_attributes_json").(string)),
-		&node.AutomaticAttributes,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("automatic_attributes_json: %s", err)
-	}
-
-	err = json.Unmarshal(
+	err := json.Unmarshal(
 		[]byte(d.Get("normal_attributes_json").(string)),
 		&node.NormalAttributes,
 	)
@@ -0,0 +1,226 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// resourceChefNodeRegistration registers a node the way bootstrapping does:
+// an API client and a node object sharing one name, managed as a unit.
+func resourceChefNodeRegistration() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateNodeRegistration,
+		UpdateContext: UpdateNodeRegistration,
+		ReadContext:   ReadNodeRegistration,
+		DeleteContext: DeleteNodeRegistration,
+		CustomizeDiff: diffNodeRegistration,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"environment_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "_default",
+			},
+			"run_list": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:      schema.TypeString,
+					StateFunc: runListEntryStateFunc,
+				},
+			},
+			"private_key_pem": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func CreateNodeRegistration(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+	name := d.Get("name").(string)
+
+	result, err := c.Clients.Create(chefc.ApiNewClient{
+		Name:      name,
+		CreateKey: true,
+	})
+	if err != nil {
+		return chefErrToDiag("Error creating client", err, cty.GetAttrPath("name"))
+	}
+
+	node := chefc.NewNode(name)
+	node.Environment = d.Get("environment_name").(string)
+	node.RunList = registrationRunList(d)
+	if _, err := c.Nodes.Post(node); err != nil {
+		// Don't leave a client behind that nothing in state refers to,
+		// otherwise the next apply fails because the name is taken.
+		if derr := c.Clients.Delete(name); derr != nil {
+			err = fmt.Errorf("%s; additionally, removing the client created for it failed: %s", err, derr)
+		}
+		return chefErrToDiag("Error creating node", err, cty.GetAttrPath("name"))
+	}
+
+	d.SetId(name)
+	d.Set("private_key_pem", result.ChefKey.PrivateKey)
+
+	if err := grantNodeUpdate(c, name); err != nil {
+		return chefErrToDiag("Error granting the client update on its node", err, cty.GetAttrPath("name"))
+	}
+	return ReadNodeRegistration(ctx, d, meta)
+}
+
+func UpdateNodeRegistration(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+	name := d.Id()
+
+	// Either half may have gone missing since it was registered; only that
+	// half is recreated, since the other would be refused as a duplicate.
+	if _, err := c.Clients.Get(name); isChefNotFound(err) {
+		result, err := c.Clients.Create(chefc.ApiNewClient{
+			Name:      name,
+			CreateKey: true,
+		})
+		if err != nil {
+			return chefErrToDiag("Error recreating client", err, nil)
+		}
+		d.Set("private_key_pem", result.ChefKey.PrivateKey)
+	} else if err != nil {
+		return chefErrToDiag("Error reading client", err, nil)
+	}
+
+	// Only the environment and run list are managed here; attributes belong
+	// to chef-client on the node and are written back as they were read.
+	node, err := c.Nodes.Get(name)
+	if isChefNotFound(err) {
+		node = chefc.NewNode(name)
+		node.Environment = d.Get("environment_name").(string)
+		node.RunList = registrationRunList(d)
+		if _, err := c.Nodes.Post(node); err != nil {
+			return chefErrToDiag("Error recreating node", err, nil)
+		}
+		if err := grantNodeUpdate(c, name); err != nil {
+			return chefErrToDiag("Error granting the client update on its node", err, nil)
+		}
+		return ReadNodeRegistration(ctx, d, meta)
+	}
+	if err != nil {
+		return chefErrToDiag("Error reading node", err, nil)
+	}
+
+	node.Environment = d.Get("environment_name").(string)
+	node.RunList = registrationRunList(d)
+	if _, err := c.Nodes.Put(node); err != nil {
+		return chefErrToDiag("Error updating node", err, nil)
+	}
+
+	return ReadNodeRegistration(ctx, d, meta)
+}
+
+func ReadNodeRegistration(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	_, clientErr := c.Clients.Get(d.Id())
+	if clientErr != nil && !isChefNotFound(clientErr) {
+		return chefErrToDiag("Error reading client", clientErr, nil)
+	}
+	node, nodeErr := c.Nodes.Get(d.Id())
+	if nodeErr != nil && !isChefNotFound(nodeErr) {
+		return chefErrToDiag("Error reading node", nodeErr, nil)
+	}
+
+	if clientErr != nil && nodeErr != nil {
+		d.SetId("")
+		return nil
+	}
+
+	// When only one half has gone away, the registration stays in state
+	// and the next update recreates the missing half: a missing client's
+	// key went with it, and a missing node no longer has the configured
+	// environment.
+	if clientErr != nil {
+		d.Set("private_key_pem", "")
+	}
+	if nodeErr != nil {
+		d.Set("environment_name", "")
+		d.Set("run_list", nil)
+		return nil
+	}
+
+	d.Set("name", node.Name)
+	d.Set("environment_name", node.Environment)
+
+	runListI := make([]interface{}, len(node.RunList))
+	for i, v := range node.RunList {
+		runListI[i] = v
+	}
+	d.Set("run_list", runListI)
+
+	return nil
+}
+
+// diffNodeRegistration plans a new key when the client, and with it the
+// key, has gone away.
+func diffNodeRegistration(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" && d.Get("private_key_pem").(string) == "" {
+		return d.SetNewComputed("private_key_pem")
+	}
+	return nil
+}
+
+func DeleteNodeRegistration(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+	name := d.Id()
+
+	if err := c.Nodes.Delete(name); err != nil && !isChefNotFound(err) {
+		return chefErrToDiag("Error deleting node", err, nil)
+	}
+
+	if err := c.Clients.Delete(name); err != nil && !isChefNotFound(err) {
+		return chefErrToDiag("Error deleting client", err, nil)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// grantNodeUpdate adds the node's own client to the actors allowed to
+// update it. A node created by chef-client is owned by its client, but one
+// registered here is created by the provider's client instead, and
+// chef-client can't save the node at the end of a run without this.
+func grantNodeUpdate(c *chefClient, name string) error {
+	acl, err := c.ACLs.Get("nodes", name)
+	if err != nil {
+		return err
+	}
+
+	items := acl["update"]
+	if containsString(items.Actors, name) {
+		return nil
+	}
+	// Empty lists must be sent as [] rather than null.
+	actors := append(append(chefc.ACLitem{}, items.Actors...), name)
+	groups := append(chefc.ACLitem{}, items.Groups...)
+	return c.ACLs.Put("nodes", name, "update", chefc.NewACL("update", actors, groups))
+}
+
+func registrationRunList(d *schema.ResourceData) []string {
+	runListI := d.Get("run_list").([]interface{})
+	runList := make([]string, len(runListI))
+	for i, v := range runListI {
+		runList[i] = v.(string)
+	}
+	return runList
+}
@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestCreateNodeRegistration(t *testing.T) {
+	var granted chefc.ACL
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /organizations/test/nodes/web1/_acl":
+			w.Write([]byte(`{"update":{"actors":["pivotal"],"groups":["admins"]}}`))
+		case "PUT /organizations/test/nodes/web1/_acl/update":
+			json.NewDecoder(r.Body).Decode(&granted)
+		case "POST /organizations/test/clients":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"uri":"clients/web1","chef_key":{"name":"default","private_key":"PRIVATE"}}`))
+		case "POST /organizations/test/nodes":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"uri":"nodes/web1"}`))
+		case "GET /organizations/test/clients/web1":
+			w.Write([]byte(`{"name":"web1"}`))
+		case "GET /organizations/test/nodes/web1":
+			w.Write([]byte(`{"name":"web1","chef_environment":"prod","run_list":["recipe[web]"]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefNodeRegistration().Schema, map[string]interface{}{
+		"name":             "web1",
+		"environment_name": "prod",
+		"run_list":         []interface{}{"web"},
+	})
+	if diags := CreateNodeRegistration(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if d.Id() != "web1" {
+		t.Fatalf("unexpected id %q", d.Id())
+	}
+	if got := d.Get("private_key_pem").(string); got != "PRIVATE" {
+		t.Fatalf("private key was not captured, got %q", got)
+	}
+	if got := d.Get("environment_name").(string); got != "prod" {
+		t.Fatalf("unexpected environment %q", got)
+	}
+	if update := granted["update"]; !reflect.DeepEqual([]string(update.Actors), []string{"pivotal", "web1"}) || len(update.Groups) != 1 {
+		t.Fatalf("expected the client to be granted update on its node, got %#v", granted)
+	}
+}
+
+func TestCreateNodeRegistration_rollback(t *testing.T) {
+	deleted := false
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /organizations/test/clients":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"uri":"clients/web1","chef_key":{"name":"default","private_key":"PRIVATE"}}`))
+		case "POST /organizations/test/nodes":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error":["Node already exists"]}`))
+		case "DELETE /organizations/test/clients/web1":
+			deleted = true
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefNodeRegistration().Schema, map[string]interface{}{
+		"name": "web1",
+	})
+	if diags := CreateNodeRegistration(context.Background(), d, c); !diags.HasError() {
+		t.Fatal("expected an error when the node cannot be created")
+	}
+	if !deleted {
+		t.Fatal("expected the client to be removed after the node failed")
+	}
+	if d.Id() != "" {
+		t.Fatalf("expected no id, got %q", d.Id())
+	}
+}
+
+func TestNodeRegistration_recreatesMissingHalf(t *testing.T) {
+	for _, missing := range []string{"client", "node"} {
+		t.Run(missing, func(t *testing.T) {
+			var created []string
+			c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.Method + " " + r.URL.Path {
+				case "GET /organizations/test/clients/web1":
+					if missing == "client" && len(created) == 0 {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					w.Write([]byte(`{"name":"web1"}`))
+				case "GET /organizations/test/nodes/web1":
+					if missing == "node" && len(created) == 0 {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					w.Write([]byte(`{"name":"web1","chef_environment":"prod","run_list":["recipe[web]"]}`))
+				case "PUT /organizations/test/nodes/web1":
+					w.Write([]byte(`{}`))
+				case "POST /organizations/test/clients":
+					created = append(created, "client")
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{"uri":"clients/web1","chef_key":{"name":"default","private_key":"NEW"}}`))
+				case "POST /organizations/test/nodes":
+					created = append(created, "node")
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{"uri":"nodes/web1"}`))
+				case "GET /organizations/test/nodes/web1/_acl":
+					w.Write([]byte(`{"update":{"actors":[],"groups":[]}}`))
+				case "PUT /organizations/test/nodes/web1/_acl/update":
+					w.Write([]byte(`{}`))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+
+			d := schema.TestResourceDataRaw(t, resourceChefNodeRegistration().Schema, map[string]interface{}{
+				"name":             "web1",
+				"environment_name": "prod",
+				"run_list":         []interface{}{"web"},
+			})
+			d.SetId("web1")
+			d.Set("private_key_pem", "OLD")
+
+			if diags := ReadNodeRegistration(context.Background(), d, c); diags.HasError() {
+				t.Fatalf("err: %v", diags)
+			}
+			if d.Id() != "web1" {
+				t.Fatal("expected the registration to stay in state while one half remains")
+			}
+
+			if diags := UpdateNodeRegistration(context.Background(), d, c); diags.HasError() {
+				t.Fatalf("err: %v", diags)
+			}
+			if len(created) != 1 || created[0] != missing {
+				t.Fatalf("expected only the %s to be recreated, created %v", missing, created)
+			}
+			if key := d.Get("private_key_pem").(string); (missing == "client") != (key == "NEW") {
+				t.Fatalf("unexpected private key %q", key)
+			}
+		})
+	}
+}
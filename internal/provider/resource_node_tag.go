@@ -0,0 +1,336 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// resourceChefNodeTag keeps a tag applied to the nodes matching a search
+// query. Only nodes the resource tagged itself, as recorded in nodes, are
+// ever untagged; the tag is left alone on any other node.
+func resourceChefNodeTag() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateNodeTag,
+		UpdateContext: UpdateNodeTag,
+		ReadContext:   ReadNodeTag,
+		DeleteContext: DeleteNodeTag,
+		CustomizeDiff: diffNodeTag,
+
+		Schema: map[string]*schema.Schema{
+			"tag": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"query": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"concurrency": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  4,
+			},
+			"nodes": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Nodes the resource tagged and keeps tagged. Matching nodes that already had the tag are left out, and only the nodes listed are untagged when they stop matching the query or the resource is destroyed.",
+			},
+		},
+	}
+}
+
+func CreateNodeTag(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId(d.Get("tag").(string))
+	return reconcileNodeTag(ctx, d, meta)
+}
+
+func UpdateNodeTag(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return reconcileNodeTag(ctx, d, meta)
+}
+
+func ReadNodeTag(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	tagged, err := searchNodeNames(client.Client, "tags:"+escapeSearchValue(d.Id()))
+	if err != nil {
+		return chefErrToDiag("Error searching for tagged nodes", err, cty.GetAttrPath("tag"))
+	}
+
+	// Nodes that lost the tag drop out, so that the next plan tags them
+	// again if they still match.
+	isTagged := make(map[string]bool)
+	for _, name := range tagged {
+		isTagged[name] = true
+	}
+	nodes := make([]string, 0)
+	for _, name := range sortedSetStrings(d.Get("nodes").(*schema.Set)) {
+		if isTagged[name] {
+			nodes = append(nodes, name)
+		}
+	}
+
+	d.Set("nodes", nodes)
+	return nil
+}
+
+func DeleteNodeTag(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	tag := d.Id()
+
+	var ops []bulkOperation
+	for _, name := range sortedSetStrings(d.Get("nodes").(*schema.Set)) {
+		name := name
+		ops = append(ops, bulkOperation{
+			ID:  "node/" + name,
+			Run: func() error { return setNodeTag(client.Client, name, tag, false) },
+		})
+	}
+
+	if err := runBulkConcurrent(ops, d.Get("concurrency").(int)); err != nil {
+		return chefErrToDiag("Error removing tag from nodes", err, nil)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// diffNodeTag plans an update whenever the nodes the resource would keep
+// tagged differ from the ones it owns now.
+func diffNodeTag(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.NewValueKnown("query") {
+		return nil
+	}
+	client := meta.(*chefClient)
+
+	matching, err := searchNodeNames(client.Client, d.Get("query").(string))
+	if err != nil {
+		return fmt.Errorf("searching for nodes matching query: %s", err)
+	}
+	tagged, err := searchNodeNames(client.Client, "tags:"+escapeSearchValue(d.Id()))
+	if err != nil {
+		return fmt.Errorf("searching for tagged nodes: %s", err)
+	}
+
+	current := sortedSetStrings(d.Get("nodes").(*schema.Set))
+	planned := ownedNodeTags(matching, tagged, current)
+	if !reflect.DeepEqual(current, planned) {
+		return d.SetNew("nodes", planned)
+	}
+	return nil
+}
+
+// ownedNodeTags returns the matching nodes the resource owns the tag on
+// once it has been applied: those it owned already, and those that don't
+// have the tag yet and so will be tagged by it. A matching node that was
+// tagged by something else is left out, so that it is never untagged.
+func ownedNodeTags(matching, tagged, owned []string) []string {
+	isTagged := make(map[string]bool)
+	for _, name := range tagged {
+		isTagged[name] = true
+	}
+	isOwned := make(map[string]bool)
+	for _, name := range owned {
+		isOwned[name] = true
+	}
+
+	nodes := make([]string, 0, len(matching))
+	for _, name := range matching {
+		if isOwned[name] || !isTagged[name] {
+			nodes = append(nodes, name)
+		}
+	}
+	return nodes
+}
+
+// reconcileNodeTag tags every node matching the query and untags the
+// nodes it tagged before that no longer match.
+func reconcileNodeTag(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	tag := d.Id()
+
+	matching, err := searchNodeNames(client.Client, d.Get("query").(string))
+	if err != nil {
+		return chefErrToDiag("Error searching for nodes", err, cty.GetAttrPath("query"))
+	}
+
+	tagged, err := searchNodeNames(client.Client, "tags:"+escapeSearchValue(tag))
+	if err != nil {
+		return chefErrToDiag("Error searching for tagged nodes", err, cty.GetAttrPath("tag"))
+	}
+
+	old, _ := d.GetChange("nodes")
+	previous := sortedSetStrings(old.(*schema.Set))
+	owned := ownedNodeTags(matching, tagged, previous)
+
+	isMatching := make(map[string]bool)
+	for _, name := range matching {
+		isMatching[name] = true
+	}
+	isTagged := make(map[string]bool)
+	for _, name := range tagged {
+		isTagged[name] = true
+	}
+
+	var ops []bulkOperation
+	for _, name := range owned {
+		if name := name; !isTagged[name] {
+			ops = append(ops, bulkOperation{
+				ID:  "node/" + name,
+				Run: func() error { return setNodeTag(client.Client, name, tag, true) },
+			})
+		}
+	}
+	for _, name := range previous {
+		if name := name; !isMatching[name] {
+			ops = append(ops, bulkOperation{
+				ID:  "node/" + name,
+				Run: func() error { return setNodeTag(client.Client, name, tag, false) },
+			})
+		}
+	}
+
+	err = runBulkConcurrent(ops, d.Get("concurrency").(int))
+
+	// Record the nodes the resource owns the tag on now: those it owned
+	// or tagged, less any it failed to tag, plus any it could not untag.
+	failed := make(map[string]bool)
+	if bulkErr, ok := err.(*bulkError); ok {
+		for _, id := range bulkErr.Failed {
+			failed[strings.TrimPrefix(id, "node/")] = true
+		}
+	}
+	nodes := make([]string, 0, len(owned))
+	for _, name := range owned {
+		if !failed[name] {
+			nodes = append(nodes, name)
+		}
+	}
+	for _, name := range previous {
+		if !isMatching[name] && failed[name] {
+			nodes = append(nodes, name)
+		}
+	}
+	d.Set("nodes", nodes)
+
+	if err != nil {
+		return chefErrToDiag("Error updating node tags", err, nil)
+	}
+
+	return nil
+}
+
+// escapeSearchValue escapes the characters Chef's search query syntax
+// treats specially, so that value can be used as a term that matches only
+// itself.
+func escapeSearchValue(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if strings.ContainsRune(`+-&|!(){}[]^"~*?:\/ `, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// searchNodeNames returns the names of all nodes matching query, paging
+// through the results and fetching only the name of each node.
+func searchNodeNames(client *chefc.Client, query string) ([]string, error) {
+	q, err := client.Search.NewQuery("node", query)
+	if err != nil {
+		return nil, err
+	}
+	// go-chef puts the query into the URL as it is.
+	q.Query = url.QueryEscape(query)
+
+	params := map[string]interface{}{"name": []string{"name"}}
+	names := make([]string, 0)
+	for {
+		res, err := q.DoPartial(client, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range res.Rows {
+			row, _ := r.(map[string]interface{})
+			data, _ := row["data"].(map[string]interface{})
+			if name, ok := data["name"].(string); ok {
+				names = append(names, name)
+			}
+		}
+
+		q.Start += len(res.Rows)
+		if len(res.Rows) == 0 || q.Start >= res.Total {
+			break
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// setNodeTag adds tag to or removes it from a node's tags.
+func setNodeTag(client *chefc.Client, name, tag string, present bool) error {
+	if present {
+		return updateNodeTags(client, name, []string{tag}, nil)
+	}
+	return updateNodeTags(client, name, nil, []string{tag})
+}
+
+// updateNodeTags adds and removes tags from a node's tags, which
+// chef-client keeps in the normal attributes, saving the node only if
+// that changes anything.
+func updateNodeTags(client *chefc.Client, name string, add, remove []string) error {
+	node, err := client.Nodes.Get(name)
+	if err != nil {
+		return err
+	}
+
+	removing := make(map[string]bool)
+	for _, tag := range remove {
+		removing[tag] = true
+	}
+
+	changed := false
+	tags := make([]interface{}, 0)
+	present := make(map[interface{}]bool)
+	existing, _ := node.NormalAttributes["tags"].([]interface{})
+	for _, t := range existing {
+		if s, ok := t.(string); ok && removing[s] {
+			changed = true
+			continue
+		}
+		present[t] = true
+		tags = append(tags, t)
+	}
+	for _, tag := range add {
+		if !present[tag] {
+			present[tag] = true
+			tags = append(tags, tag)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	if node.NormalAttributes == nil {
+		node.NormalAttributes = make(map[string]interface{})
+	}
+	node.NormalAttributes["tags"] = tags
+
+	_, err = client.Nodes.Put(node)
+	return err
+}
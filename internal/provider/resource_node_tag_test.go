@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestCreateNodeTag(t *testing.T) {
+	var mu sync.Mutex
+	nodes := map[string][]interface{}{
+		"web1": {},
+		"web2": {"region-east"},
+		"db1":  {"region-east", "db"},
+	}
+
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/organizations/test/search/node":
+			var names []string
+			switch q := r.URL.Query().Get("q"); q {
+			case "ec2_region:us-east-1":
+				names = []string{"web1", "web2"}
+			case "name:web2":
+				names = []string{"web2"}
+			case `tags:region\-east`:
+				for name, tags := range nodes {
+					for _, tag := range tags {
+						if tag == "region-east" {
+							names = append(names, name)
+						}
+					}
+				}
+			default:
+				t.Errorf("unexpected query %q", q)
+			}
+			rows := make([]interface{}, len(names))
+			for i, name := range names {
+				rows[i] = map[string]interface{}{"data": map[string]interface{}{"name": name}}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"total": len(rows), "start": 0, "rows": rows})
+		case strings.HasPrefix(r.URL.Path, "/organizations/test/nodes/"):
+			name := strings.TrimPrefix(r.URL.Path, "/organizations/test/nodes/")
+			if r.Method == "PUT" {
+				var node chefc.Node
+				json.NewDecoder(r.Body).Decode(&node)
+				nodes[name] = node.NormalAttributes["tags"].([]interface{})
+			}
+			fmt.Fprintf(w, `{"name":%q,"normal":{"tags":%s}}`, name, mustJSON(nodes[name]))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefNodeTag().Schema, map[string]interface{}{
+		"tag":   "region-east",
+		"query": "ec2_region:us-east-1",
+	})
+	if diags := CreateNodeTag(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if got := nodes["web1"]; len(got) != 1 || got[0] != "region-east" {
+		t.Fatalf("expected web1 to be tagged, got %v", got)
+	}
+	if got := nodes["db1"]; len(got) != 2 {
+		t.Fatalf("expected the tag to be left alone on db1, which the resource didn't tag, got %v", got)
+	}
+	if got := sortedSetStrings(d.Get("nodes").(*schema.Set)); len(got) != 1 || got[0] != "web1" {
+		t.Fatalf("expected only web1, which the resource tagged, to be recorded, got %v", got)
+	}
+
+	d = resourceChefNodeTag().Data(d.State())
+	d.Set("query", "name:web2")
+	if diags := UpdateNodeTag(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if got := nodes["web1"]; len(got) != 0 {
+		t.Fatalf("expected web1 to be untagged once it stopped matching, got %v", got)
+	}
+	if got := nodes["db1"]; len(got) != 2 {
+		t.Fatalf("expected the tag to be left alone on db1, got %v", got)
+	}
+	if got := d.Get("nodes").(*schema.Set); got.Len() != 0 {
+		t.Fatalf("expected web2, which was tagged already, not to be recorded, got %v", got.List())
+	}
+
+	// Once the tag is removed elsewhere, the resource tags web2 itself and
+	// owns it from then on.
+	nodes["web2"] = []interface{}{}
+	if diags := UpdateNodeTag(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if got := sortedSetStrings(d.Get("nodes").(*schema.Set)); len(got) != 1 || got[0] != "web2" {
+		t.Fatalf("unexpected nodes %v", got)
+	}
+	d = resourceChefNodeTag().Data(d.State())
+	if diags := UpdateNodeTag(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if got := sortedSetStrings(d.Get("nodes").(*schema.Set)); len(got) != 1 || got[0] != "web2" {
+		t.Fatalf("expected web2 to stay owned, got %v", got)
+	}
+
+	// A node that lost the tag drops out of state.
+	nodes["web2"] = []interface{}{}
+	if diags := ReadNodeTag(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if got := d.Get("nodes").(*schema.Set); got.Len() != 0 {
+		t.Fatalf("expected no tagged nodes, got %v", got.List())
+	}
+}
+
+func TestOwnedNodeTags(t *testing.T) {
+	matching := []string{"db1", "web1", "web2"}
+	tagged := []string{"db1", "web2", "web3"}
+	owned := []string{"web2", "web3"}
+
+	got := ownedNodeTags(matching, tagged, owned)
+	if !reflect.DeepEqual(got, []string{"web1", "web2"}) {
+		t.Fatalf("expected the untagged and already owned matching nodes, got %v", got)
+	}
+}
+
+func TestEscapeSearchValue(t *testing.T) {
+	if got, expected := escapeSearchValue(`web ops:a/b*"c"`), `web\ ops\:a\/b\*\"c\"`; got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}
+
+func mustJSON(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceChefNodeTags applies a set of tags to an explicit list of nodes.
+// Only the declared tags are managed; any other tags the nodes carry are
+// left alone.
+func resourceChefNodeTags() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateNodeTags,
+		UpdateContext: UpdateNodeTags,
+		ReadContext:   ReadNodeTags,
+		DeleteContext: DeleteNodeTags,
+		CustomizeDiff: diffNodeTags,
+
+		Schema: map[string]*schema.Schema{
+			"nodes": {
+				Type:     schema.TypeSet,
+				Required: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"tags": {
+				Type:     schema.TypeSet,
+				Required: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"concurrency": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  4,
+			},
+			"untagged_nodes": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Listed nodes that are missing one or more of the tags.",
+			},
+		},
+	}
+}
+
+func CreateNodeTags(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId(resource.UniqueId())
+	return reconcileNodeTags(ctx, d, meta)
+}
+
+func UpdateNodeTags(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return reconcileNodeTags(ctx, d, meta)
+}
+
+func ReadNodeTags(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	tags := sortedSetStrings(d.Get("tags").(*schema.Set))
+
+	untagged := make([]string, 0)
+	for _, name := range sortedSetStrings(d.Get("nodes").(*schema.Set)) {
+		node, err := client.Nodes.Get(name)
+		if err != nil {
+			if isChefNotFound(err) {
+				untagged = append(untagged, name)
+				continue
+			}
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Error reading node",
+					Detail:        fmt.Sprintf("%s: %s", name, err),
+					AttributePath: cty.GetAttrPath("nodes"),
+				},
+			}
+		}
+
+		present := make(map[interface{}]bool)
+		existing, _ := node.NormalAttributes["tags"].([]interface{})
+		for _, t := range existing {
+			present[t] = true
+		}
+		for _, tag := range tags {
+			if !present[tag] {
+				untagged = append(untagged, name)
+				break
+			}
+		}
+	}
+
+	d.Set("untagged_nodes", untagged)
+	return nil
+}
+
+func DeleteNodeTags(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	tags := sortedSetStrings(d.Get("tags").(*schema.Set))
+
+	var ops []bulkOperation
+	for _, name := range sortedSetStrings(d.Get("nodes").(*schema.Set)) {
+		name := name
+		ops = append(ops, bulkOperation{
+			ID: "node/" + name,
+			Run: func() error {
+				if err := updateNodeTags(client.Client, name, nil, tags); err != nil && !isChefNotFound(err) {
+					return err
+				}
+				return nil
+			},
+		})
+	}
+
+	if err := runBulkConcurrent(ops, d.Get("concurrency").(int)); err != nil {
+		return chefErrToDiag("Error removing tags from nodes", err, nil)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// diffNodeTags plans an update whenever a listed node has lost one of the
+// tags since they were applied.
+func diffNodeTags(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" && d.Get("untagged_nodes").(*schema.Set).Len() > 0 {
+		return d.SetNew("untagged_nodes", []string{})
+	}
+	return nil
+}
+
+// reconcileNodeTags tags every listed node, and removes tags that are no
+// longer declared from listed nodes and all tags from nodes that are no
+// longer listed.
+func reconcileNodeTags(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	oldNodes, newNodes := d.GetChange("nodes")
+	oldTags, newTags := d.GetChange("tags")
+	tags := sortedSetStrings(newTags.(*schema.Set))
+	dropped := sortedSetStrings(oldTags.(*schema.Set).Difference(newTags.(*schema.Set)))
+
+	var ops []bulkOperation
+	for _, name := range sortedSetStrings(newNodes.(*schema.Set)) {
+		name := name
+		ops = append(ops, bulkOperation{
+			ID:  "node/" + name,
+			Run: func() error { return updateNodeTags(client.Client, name, tags, dropped) },
+		})
+	}
+	removed := sortedSetStrings(oldTags.(*schema.Set))
+	for _, name := range sortedSetStrings(oldNodes.(*schema.Set).Difference(newNodes.(*schema.Set))) {
+		name := name
+		ops = append(ops, bulkOperation{
+			ID: "node/" + name,
+			Run: func() error {
+				if err := updateNodeTags(client.Client, name, nil, removed); err != nil && !isChefNotFound(err) {
+					return err
+				}
+				return nil
+			},
+		})
+	}
+
+	if err := runBulkConcurrent(ops, d.Get("concurrency").(int)); err != nil {
+		return chefErrToDiag("Error updating node tags", err, nil)
+	}
+
+	return ReadNodeTags(ctx, d, meta)
+}
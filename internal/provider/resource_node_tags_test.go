@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestCreateNodeTags(t *testing.T) {
+	var mu sync.Mutex
+	nodes := map[string][]interface{}{
+		"web1": {"keep"},
+		"web2": {"pci"},
+		"db1":  {},
+	}
+
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		name := strings.TrimPrefix(r.URL.Path, "/organizations/test/nodes/")
+		if _, ok := nodes[name]; !ok {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "PUT" {
+			var node chefc.Node
+			json.NewDecoder(r.Body).Decode(&node)
+			nodes[name] = node.NormalAttributes["tags"].([]interface{})
+		}
+		fmt.Fprintf(w, `{"name":%q,"normal":{"tags":%s}}`, name, mustJSON(nodes[name]))
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefNodeTags().Schema, map[string]interface{}{
+		"nodes": []interface{}{"web1", "web2"},
+		"tags":  []interface{}{"pci", "frontend"},
+	})
+	if diags := CreateNodeTags(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if got := fmt.Sprint(nodes["web1"]); got != "[keep frontend pci]" {
+		t.Fatalf("expected web1 to gain both tags and keep its own, got %s", got)
+	}
+	if got := fmt.Sprint(nodes["web2"]); got != "[pci frontend]" {
+		t.Fatalf("expected web2 to gain only the missing tag, got %s", got)
+	}
+	if len(nodes["db1"]) != 0 {
+		t.Fatalf("expected unlisted db1 to be untouched, got %v", nodes["db1"])
+	}
+	if d.Get("untagged_nodes").(*schema.Set).Len() != 0 {
+		t.Fatalf("expected no untagged nodes, got %v", d.Get("untagged_nodes"))
+	}
+
+	if diags := DeleteNodeTags(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if got := fmt.Sprint(nodes["web1"], nodes["web2"]); got != "[keep] []" {
+		t.Fatalf("expected only the managed tags to be removed, got %s", got)
+	}
+}
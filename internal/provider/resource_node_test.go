@@ -1,13 +1,17 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"reflect"
 	"testing"
 
 	chefc "github.com/go-chef/chef"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -44,8 +48,8 @@ func TestAccNode_basic(t *testing.T) {
 						expectedAttributes := map[string]interface{}{
 							"terraform_acc_test": true,
 						}
-						if !reflect.DeepEqual(node.AutomaticAttributes, expectedAttributes) {
-							return fmt.Errorf("wrong automatic attributes; expected %#v, got %#v", expectedAttributes, node.AutomaticAttributes)
+						if len(node.AutomaticAttributes) != 0 {
+							return fmt.Errorf("expected no automatic attributes, got %#v", node.AutomaticAttributes)
 						}
 						if !reflect.DeepEqual(node.NormalAttributes, expectedAttributes) {
 							return fmt.Errorf("wrong normal attributes; expected %#v, got %#v", expectedAttributes, node.NormalAttributes)
@@ -113,11 +117,6 @@ resource "chef_environment" "test" {
 resource "chef_node" "test" {
   name = "terraform-acc-test-basic-{{.}}"
   environment_name = chef_environment.test.id
-  automatic_attributes_json = <<EOT
-{
-     "terraform_acc_test": true
-}
-EOT
   normal_attributes_json = <<EOT
 {
      "terraform_acc_test": true
@@ -136,3 +135,85 @@ EOT
   run_list = ["terraform@1.0.0", "recipe[consul]", "role[foo]"]
 }
 `
+
+func TestUpdateNodeKeepsAutomaticAttributes(t *testing.T) {
+	node := chefc.Node{
+		Name:                "web1",
+		Environment:         "_default",
+		AutomaticAttributes: map[string]interface{}{"platform": "ubuntu"},
+	}
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/organizations/test/nodes/web1" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "PUT" {
+			node = chefc.Node{}
+			json.NewDecoder(r.Body).Decode(&node)
+		}
+		json.NewEncoder(w).Encode(node)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefNode().Schema, map[string]interface{}{
+		"name":                   "web1",
+		"normal_attributes_json": `{"role":"web"}`,
+	})
+	d.SetId("web1")
+	if diags := ReadNode(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	d.Set("normal_attributes_json", `{"role":"web"}`)
+	if diags := UpdateNode(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if !reflect.DeepEqual(node.AutomaticAttributes, map[string]interface{}{"platform": "ubuntu"}) {
+		t.Fatalf("expected automatic attributes to be left as chef-client reported them, got %#v", node.AutomaticAttributes)
+	}
+	if !reflect.DeepEqual(node.NormalAttributes, map[string]interface{}{"role": "web"}) {
+		t.Fatalf("expected normal attributes to be updated, got %#v", node.NormalAttributes)
+	}
+}
+
+func TestUpdateNodeKeepsAutomaticAttributes_savedSinceRead(t *testing.T) {
+	node := chefc.Node{
+		Name:                "web1",
+		Environment:         "_default",
+		AutomaticAttributes: map[string]interface{}{"platform": "ubuntu", "uptime": "1 day"},
+	}
+	var ifMatch string
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", fmt.Sprintf(`"%v"`, node.AutomaticAttributes["uptime"]))
+		if r.Method == "PUT" {
+			ifMatch = r.Header.Get("If-Match")
+			node = chefc.Node{}
+			json.NewDecoder(r.Body).Decode(&node)
+		}
+		json.NewEncoder(w).Encode(node)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefNode().Schema, map[string]interface{}{
+		"name":                   "web1",
+		"normal_attributes_json": `{"role":"web"}`,
+	})
+	d.SetId("web1")
+	if diags := ReadNode(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	// chef-client saves the node between the refresh and the update.
+	node.AutomaticAttributes = map[string]interface{}{"platform": "ubuntu", "uptime": "2 days"}
+
+	if diags := UpdateNode(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if node.AutomaticAttributes["uptime"] != "2 days" {
+		t.Fatalf("expected the automatic attributes chef-client saved to be kept, got %#v", node.AutomaticAttributes)
+	}
+	if ifMatch != `"2 days"` {
+		t.Fatalf("expected the PUT to be guarded by the live node's ETag, got %q", ifMatch)
+	}
+}
@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func resourceChefOrganizationMember() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateOrganizationMember,
+		ReadContext:   ReadOrganizationMember,
+		DeleteContext: DeleteOrganizationMember,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"username": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"invite": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				ForceNew:    true,
+				Description: "If set, the user is invited to the organization and becomes a member once they accept. Otherwise the user is added directly, which requires the provider to authenticate as a server administrator.",
+			},
+			"pending": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the user has been invited but has not accepted yet.",
+			},
+		},
+	}
+}
+
+func CreateOrganizationMember(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+	username := d.Get("username").(string)
+
+	// A user who is already a member, or already invited, is adopted rather
+	// than failing on the conflict.
+	member, inviteID, err := organizationMembership(c.Client, username)
+	if err != nil {
+		return chefErrToDiag("Error reading organization membership", err, cty.GetAttrPath("username"))
+	}
+
+	invite := d.Get("invite").(bool)
+	switch {
+	case member, invite && inviteID != "":
+	case invite:
+		_, err = c.Associations.Invite(chefc.Request{User: username})
+	default:
+		err = c.Associations.Add(chefc.AddNow{Username: username})
+	}
+	if err != nil {
+		return chefErrToDiag("Error adding user to organization", err, cty.GetAttrPath("username"))
+	}
+
+	d.SetId(username)
+	return ReadOrganizationMember(ctx, d, meta)
+}
+
+func ReadOrganizationMember(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	member, inviteID, err := organizationMembership(c.Client, d.Id())
+	if err != nil {
+		return chefErrToDiag("Error reading organization membership", err, cty.GetAttrPath("username"))
+	}
+	if !member && inviteID == "" {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("username", d.Id())
+	d.Set("pending", !member)
+	return nil
+}
+
+func DeleteOrganizationMember(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	member, inviteID, err := organizationMembership(c.Client, d.Id())
+	if err == nil {
+		if member {
+			_, err = c.Associations.Delete(d.Id())
+		} else if inviteID != "" {
+			_, err = c.Associations.DeleteInvite(inviteID)
+		}
+	}
+	if err != nil && !isChefNotFound(err) {
+		return chefErrToDiag("Error removing user from organization", err, cty.GetAttrPath("username"))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// organizationMembership reports whether username is a member of the
+// client's organization and, if they are not, the ID of any invitation
+// still waiting for them to accept.
+func organizationMembership(client *chefc.Client, username string) (member bool, inviteID string, err error) {
+	if _, err := client.Associations.Get(username); err == nil {
+		return true, "", nil
+	} else if !isChefNotFound(err) {
+		return false, "", err
+	}
+
+	invites, err := client.Associations.ListInvites()
+	if err != nil {
+		return false, "", err
+	}
+	for _, invite := range invites {
+		if invite.UserName == username {
+			return false, invite.Id, nil
+		}
+	}
+	return false, "", nil
+}
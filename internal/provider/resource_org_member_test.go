@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestOrganizationMemberLifecycle(t *testing.T) {
+	members := map[string]bool{"carol": true}
+	invites := map[string]string{}
+	var adds int
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/organizations/test/users":
+			var add chefc.AddNow
+			json.NewDecoder(r.Body).Decode(&add)
+			members[add.Username] = true
+			adds++
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		case strings.HasPrefix(r.URL.Path, "/organizations/test/users/"):
+			name := strings.TrimPrefix(r.URL.Path, "/organizations/test/users/")
+			if !members[name] {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":["not found"]}`))
+				return
+			}
+			if r.Method == "DELETE" {
+				delete(members, name)
+			}
+			json.NewEncoder(w).Encode(chefc.OrgUser{Username: name})
+		case r.Method == "POST" && r.URL.Path == "/organizations/test/association_requests":
+			var req chefc.Request
+			json.NewDecoder(r.Body).Decode(&req)
+			invites[req.User] = "invite-" + req.User
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"uri":"https://chef/organizations/test/association_requests/invite-` + req.User + `"}`))
+		case r.Method == "GET" && r.URL.Path == "/organizations/test/association_requests":
+			list := []chefc.Invite{}
+			for user, id := range invites {
+				list = append(list, chefc.Invite{Id: id, UserName: user})
+			}
+			json.NewEncoder(w).Encode(list)
+		case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/organizations/test/association_requests/"):
+			id := strings.TrimPrefix(r.URL.Path, "/organizations/test/association_requests/")
+			for user, inviteID := range invites {
+				if inviteID == id {
+					delete(invites, user)
+				}
+			}
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	member := func(raw map[string]interface{}) *schema.ResourceData {
+		d := schema.TestResourceDataRaw(t, resourceChefOrganizationMember().Schema, raw)
+		if diags := CreateOrganizationMember(context.Background(), d, c); diags.HasError() {
+			t.Fatalf("err: %v", diags)
+		}
+		return d
+	}
+
+	d := member(map[string]interface{}{"username": "alice"})
+	if !members["alice"] || d.Id() != "alice" || d.Get("pending").(bool) {
+		t.Fatalf("expected alice to be added directly, got id=%q pending=%v", d.Id(), d.Get("pending"))
+	}
+	if diags := DeleteOrganizationMember(context.Background(), d, c); diags.HasError() || members["alice"] {
+		t.Fatalf("expected alice to be disassociated, got %v", diags)
+	}
+
+	d = member(map[string]interface{}{"username": "carol"})
+	if adds != 1 || d.Id() != "carol" {
+		t.Fatalf("expected the existing member to be adopted without adding again, got %d adds", adds)
+	}
+
+	d = member(map[string]interface{}{"username": "bob", "invite": true})
+	if invites["bob"] == "" || members["bob"] || !d.Get("pending").(bool) {
+		t.Fatalf("expected bob to have a pending invitation, got pending=%v", d.Get("pending"))
+	}
+	member(map[string]interface{}{"username": "bob", "invite": true})
+	if len(invites) != 1 {
+		t.Fatalf("expected the existing invitation to be adopted, got %v", invites)
+	}
+
+	// Accepting the invitation makes the user a member.
+	delete(invites, "bob")
+	members["bob"] = true
+	if diags := ReadOrganizationMember(context.Background(), d, c); diags.HasError() || d.Get("pending").(bool) {
+		t.Fatalf("expected the accepted invitation to no longer be pending, got %v", diags)
+	}
+
+	members["bob"] = false
+	if diags := ReadOrganizationMember(context.Background(), d, c); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected a removed member to be removed from state, got id=%q %v", d.Id(), diags)
+	}
+
+	d = member(map[string]interface{}{"username": "dave", "invite": true})
+	if diags := DeleteOrganizationMember(context.Background(), d, c); diags.HasError() || invites["dave"] != "" {
+		t.Fatalf("expected the pending invitation to be rescinded, got %v", diags)
+	}
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func resourceChefOrganization() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateOrganization,
+		UpdateContext: UpdateOrganization,
+		ReadContext:   ReadOrganization,
+		DeleteContext: DeleteOrganization,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"full_name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"private_key": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Private key of the organization's validator client. The server only returns it when the organization is created, so it is empty for imported organizations.",
+			},
+		},
+	}
+}
+
+func CreateOrganization(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	org := chefc.Organization{
+		Name:     d.Get("name").(string),
+		FullName: d.Get("full_name").(string),
+	}
+	result, err := c.Global.Organizations.Create(org)
+	if err != nil {
+		return chefErrToDiag("Error creating organization", err, cty.GetAttrPath("name"))
+	}
+
+	d.SetId(org.Name)
+	d.Set("private_key", result.PrivateKey)
+	return ReadOrganization(ctx, d, meta)
+}
+
+func UpdateOrganization(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	org := chefc.Organization{
+		Name:     d.Id(),
+		FullName: d.Get("full_name").(string),
+	}
+	if _, err := c.Global.Organizations.Update(org); err != nil {
+		return chefErrToDiag("Error updating organization", err, cty.GetAttrPath("full_name"))
+	}
+
+	return ReadOrganization(ctx, d, meta)
+}
+
+func ReadOrganization(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	org, err := c.Global.Organizations.Get(d.Id())
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading organization", err, cty.GetAttrPath("name"))
+	}
+
+	d.Set("name", d.Id())
+	d.Set("full_name", org.FullName)
+	return nil
+}
+
+func DeleteOrganization(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	if err := c.Global.Organizations.Delete(d.Id()); err != nil && !isChefNotFound(err) {
+		return chefErrToDiag("Error deleting organization", err, cty.GetAttrPath("name"))
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,315 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+var aclPermissions = []string{"create", "read", "update", "delete", "grant"}
+
+// resourceChefOrganizationDefaults applies a baseline of groups, container
+// ACLs and cookbook cleanup to an organization, typically right after it is
+// created.
+func resourceChefOrganizationDefaults() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateOrganizationDefaults,
+		UpdateContext: UpdateOrganizationDefaults,
+		ReadContext:   ReadOrganizationDefaults,
+		DeleteContext: DeleteOrganizationDefaults,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"organization": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"group": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"users": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"clients": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"groups": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"container_acl": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"container": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"permission": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateACLPermission,
+						},
+						"actors": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"groups": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"remove_cookbooks": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func validateACLPermission(val interface{}, key string) (warns []string, errs []error) {
+	permission := val.(string)
+	for _, p := range aclPermissions {
+		if permission == p {
+			return
+		}
+	}
+	errs = append(errs, fmt.Errorf("%s must be one of %v, got %q", key, aclPermissions, permission))
+	return
+}
+
+func CreateOrganizationDefaults(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	org := d.Get("organization").(string)
+
+	// A new organization's groups and containers are created in the
+	// background after the organization itself, so wait for them before
+	// applying anything on top.
+	err := resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		var group chefc.Group
+		if err := client.orgDo(ctx, org, "GET", "groups/admins", nil, &group); err != nil {
+			if isChefNotFound(err) {
+				return resource.RetryableError(err)
+			}
+			return resource.NonRetryableError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return chefErrToDiag("Error waiting for organization to be ready", err, cty.GetAttrPath("organization"))
+	}
+
+	if diags := applyOrganizationDefaults(ctx, d, meta); diags != nil {
+		return diags
+	}
+
+	d.SetId(org)
+	return ReadOrganizationDefaults(ctx, d, meta)
+}
+
+func UpdateOrganizationDefaults(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := applyOrganizationDefaults(ctx, d, meta); diags != nil {
+		return diags
+	}
+
+	return ReadOrganizationDefaults(ctx, d, meta)
+}
+
+func ReadOrganizationDefaults(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	org := d.Id()
+
+	groups := make([]interface{}, 0)
+	for _, v := range d.Get("group").(*schema.Set).List() {
+		name := v.(map[string]interface{})["name"].(string)
+
+		var group chefc.Group
+		if err := client.orgDo(ctx, org, "GET", "groups/"+name, nil, &group); err != nil {
+			if isChefNotFound(err) {
+				continue
+			}
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Error reading group",
+					Detail:        fmt.Sprintf("%s: %s", name, err),
+					AttributePath: cty.GetAttrPath("group"),
+				},
+			}
+		}
+
+		groups = append(groups, map[string]interface{}{
+			"name":    name,
+			"users":   group.Users,
+			"clients": group.Clients,
+			"groups":  group.Groups,
+		})
+	}
+	d.Set("group", groups)
+
+	acls := make([]interface{}, 0)
+	for _, v := range d.Get("container_acl").(*schema.Set).List() {
+		m := v.(map[string]interface{})
+		container := m["container"].(string)
+		permission := m["permission"].(string)
+
+		var acl chefc.ACL
+		if err := client.orgDo(ctx, org, "GET", "containers/"+container+"/_acl", nil, &acl); err != nil {
+			if isChefNotFound(err) {
+				continue
+			}
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Error reading container ACL",
+					Detail:        fmt.Sprintf("%s: %s", container, err),
+					AttributePath: cty.GetAttrPath("container_acl"),
+				},
+			}
+		}
+
+		acls = append(acls, map[string]interface{}{
+			"container":  container,
+			"permission": permission,
+			"actors":     []string(acl[permission].Actors),
+			"groups":     []string(acl[permission].Groups),
+		})
+	}
+	d.Set("container_acl", acls)
+
+	return nil
+}
+
+func DeleteOrganizationDefaults(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// The baseline is left in place: removing groups or resetting ACLs that
+	// the organization has come to rely on would do more harm than good.
+	d.SetId("")
+	return nil
+}
+
+func applyOrganizationDefaults(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	org := d.Get("organization").(string)
+
+	for _, v := range d.Get("group").(*schema.Set).List() {
+		m := v.(map[string]interface{})
+		name := m["name"].(string)
+
+		unlock := lockOrgGroup(client, org, name)
+		var existing chefc.Group
+		err := client.orgDo(ctx, org, "GET", "groups/"+name, nil, &existing)
+		if isChefNotFound(err) {
+			err = client.orgDo(ctx, org, "POST", "groups", chefc.Group{Name: name, GroupName: name}, nil)
+		}
+		if err == nil {
+			update := chefc.GroupUpdate{Name: name, GroupName: name}
+			update.Actors.Users = sortedSetStrings(m["users"].(*schema.Set))
+			update.Actors.Clients = sortedSetStrings(m["clients"].(*schema.Set))
+			update.Actors.Groups = sortedSetStrings(m["groups"].(*schema.Set))
+			err = client.orgDo(ctx, org, "PUT", "groups/"+name, update, nil)
+		}
+		unlock()
+		if err != nil {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Error applying default group",
+					Detail:        fmt.Sprintf("%s: %s", name, err),
+					AttributePath: cty.GetAttrPath("group"),
+				},
+			}
+		}
+	}
+
+	for _, v := range d.Get("container_acl").(*schema.Set).List() {
+		m := v.(map[string]interface{})
+		container := m["container"].(string)
+		permission := m["permission"].(string)
+
+		acl := chefc.NewACL(permission,
+			sortedSetStrings(m["actors"].(*schema.Set)),
+			sortedSetStrings(m["groups"].(*schema.Set)))
+		if err := client.orgDo(ctx, org, "PUT", "containers/"+container+"/_acl/"+permission, acl, nil); err != nil {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Error applying default container ACL",
+					Detail:        fmt.Sprintf("%s %s: %s", container, permission, err),
+					AttributePath: cty.GetAttrPath("container_acl"),
+				},
+			}
+		}
+	}
+
+	for _, name := range sortedSetStrings(d.Get("remove_cookbooks").(*schema.Set)) {
+		if err := removeOrgCookbook(ctx, client, org, name); err != nil {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Error removing cookbook",
+					Detail:        fmt.Sprintf("%s: %s", name, err),
+					AttributePath: cty.GetAttrPath("remove_cookbooks"),
+				},
+			}
+		}
+	}
+
+	return nil
+}
+
+// removeOrgCookbook deletes every version of a cookbook from an
+// organization, doing nothing if the cookbook isn't there.
+func removeOrgCookbook(ctx context.Context, client *chefClient, org, name string) error {
+	var cookbooks map[string]chefc.CookbookVersions
+	if err := client.orgDo(ctx, org, "GET", "cookbooks/"+name+"?num_versions=all", nil, &cookbooks); err != nil {
+		if isChefNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	var versions []string
+	for _, v := range cookbooks[name].Versions {
+		versions = append(versions, v.Version)
+	}
+	sort.Strings(versions)
+
+	for _, version := range versions {
+		if err := client.orgDo(ctx, org, "DELETE", "cookbooks/"+name+"/"+version, nil, nil); err != nil && !isChefNotFound(err) {
+			return fmt.Errorf("deleting version %s: %s", version, err)
+		}
+	}
+	return nil
+}
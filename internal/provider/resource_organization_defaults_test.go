@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestCreateOrganizationDefaults(t *testing.T) {
+	groups := map[string]chefc.Group{}
+	acls := map[string]chefc.ACL{}
+	var deleted []string
+	ready := false
+
+	config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/organizations/neworg/")
+		switch {
+		case path == "groups/admins":
+			// The first poll arrives before the organization has finished
+			// being set up.
+			if !ready {
+				ready = true
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":["not found"]}`))
+				return
+			}
+			w.Write([]byte(`{"name":"admins"}`))
+		case path == "groups" && r.Method == "POST":
+			var g chefc.Group
+			json.NewDecoder(r.Body).Decode(&g)
+			groups[g.Name] = chefc.Group{Name: g.Name}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("{}"))
+		case strings.HasPrefix(path, "groups/"):
+			name := strings.TrimPrefix(path, "groups/")
+			g, ok := groups[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":["not found"]}`))
+				return
+			}
+			if r.Method == "PUT" {
+				var u chefc.GroupUpdate
+				json.NewDecoder(r.Body).Decode(&u)
+				g.Users, g.Clients, g.Groups = u.Actors.Users, u.Actors.Clients, u.Actors.Groups
+				groups[name] = g
+			}
+			json.NewEncoder(w).Encode(g)
+		case strings.HasPrefix(path, "containers/nodes/_acl"):
+			if r.Method == "PUT" {
+				var acl chefc.ACL
+				json.NewDecoder(r.Body).Decode(&acl)
+				for perm, items := range acl {
+					acls["nodes"] = chefc.ACL{perm: items}
+				}
+			}
+			json.NewEncoder(w).Encode(acls["nodes"])
+		case path == "cookbooks/starter":
+			fmt.Fprint(w, `{"starter":{"versions":[{"version":"1.0.0"},{"version":"1.1.0"}]}}`)
+		case strings.HasPrefix(path, "cookbooks/starter/") && r.Method == "DELETE":
+			deleted = append(deleted, strings.TrimPrefix(path, "cookbooks/starter/"))
+			w.Write([]byte("{}"))
+		case path == "cookbooks/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":["not found"]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "organizations/test/")
+	global, err := chefc.NewClient(&config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c := &chefClient{global, global, &clientOptions{}, ""}
+
+	d := schema.TestResourceDataRaw(t, resourceChefOrganizationDefaults().Schema, map[string]interface{}{
+		"organization": "neworg",
+		"group": []interface{}{
+			map[string]interface{}{
+				"name":  "operators",
+				"users": []interface{}{"alice", "bob"},
+			},
+		},
+		"container_acl": []interface{}{
+			map[string]interface{}{
+				"container":  "nodes",
+				"permission": "update",
+				"groups":     []interface{}{"admins", "operators"},
+			},
+		},
+		"remove_cookbooks": []interface{}{"starter", "missing"},
+	})
+	if diags := CreateOrganizationDefaults(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if d.Id() != "neworg" {
+		t.Fatalf("expected id neworg, got %q", d.Id())
+	}
+	if got := groups["operators"].Users; len(got) != 2 || got[0] != "alice" || got[1] != "bob" {
+		t.Fatalf("expected operators group members to be applied, got %v", got)
+	}
+	if got := acls["nodes"]["update"].Groups; len(got) != 2 || got[0] != "admins" || got[1] != "operators" {
+		t.Fatalf("expected nodes update ACL to be applied, got %v", got)
+	}
+	if len(deleted) != 2 || deleted[0] != "1.0.0" || deleted[1] != "1.1.0" {
+		t.Fatalf("expected all starter versions to be deleted, got %v", deleted)
+	}
+}
+
+func TestValidateACLPermission(t *testing.T) {
+	if _, errs := validateACLPermission("grant", "permission"); len(errs) != 0 {
+		t.Fatalf("expected grant to be valid, got %v", errs)
+	}
+	if _, errs := validateACLPermission("write", "permission"); len(errs) == 0 {
+		t.Fatal("expected write to be rejected")
+	}
+}
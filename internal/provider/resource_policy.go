@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceChefPolicy uploads a compiled Policyfile lock as a revision of a
+// policy. Revisions can't change once uploaded, so every change to the lock
+// is a new revision.
+func resourceChefPolicy() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreatePolicy,
+		ReadContext:   ReadPolicy,
+		DeleteContext: DeletePolicy,
+		CustomizeDiff: diffPolicy,
+		Importer: &schema.ResourceImporter{
+			StateContext: PolicyImporter,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"revision_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The revision's id. Must match the revision_id in policy_lock_json, from which it is taken when not set.",
+			},
+			"policy_lock_json": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				Description:      "The compiled Policyfile lock, as written to Policyfile.lock.json by `chef install`.",
+				StateFunc:        jsonStateFunc,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+		},
+	}
+}
+
+func CreatePolicy(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	name := d.Get("name").(string)
+	_, revisionId, err := parsePolicyLock(d.Get("policy_lock_json").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	body := strings.NewReader(d.Get("policy_lock_json").(string))
+	req, err := newRequestWithContext(ctx, client.Client, "POST", "policies/"+name+"/revisions", body)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if _, err := client.Do(req, nil); err != nil {
+		return chefErrToDiag("Error uploading policy revision", err, cty.GetAttrPath("policy_lock_json"))
+	}
+
+	d.SetId(name + "/" + revisionId)
+	d.Set("revision_id", revisionId)
+
+	return ReadPolicy(ctx, d, meta)
+}
+
+func ReadPolicy(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	name, revisionId := policyFromResourceData(d)
+
+	req, err := newRequestWithContext(ctx, client.Client, "GET", "policies/"+name+"/revisions/"+revisionId, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var lock json.RawMessage
+	if _, err := client.Do(req, &lock); err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading policy revision", err, nil)
+	}
+
+	d.Set("name", name)
+	d.Set("revision_id", revisionId)
+	// A revision's content can't change, so the lock is only read back
+	// when there is none yet, as after an import.
+	if d.Get("policy_lock_json").(string) == "" {
+		d.Set("policy_lock_json", jsonStateFunc(string(lock)))
+	}
+
+	return nil
+}
+
+func DeletePolicy(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	name, revisionId := policyFromResourceData(d)
+	if _, err := client.Policies.DeleteRevision(name, revisionId); err != nil && !isChefNotFound(err) {
+		return chefErrToDiag("Error deleting policy revision", err, nil)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func PolicyImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	id := d.Id()
+	if parts := strings.Split(id, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected policy_name/revision_id", id)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// policyFromResourceData returns the policy and revision an ID refers to.
+// IDs are name/revision_id.
+func policyFromResourceData(d *schema.ResourceData) (string, string) {
+	if parts := strings.SplitN(d.Id(), "/", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return d.Get("name").(string), d.Get("revision_id").(string)
+}
+
+// diffPolicy checks the lock at plan time. Its name must be the policy's,
+// and a configured revision_id must match the one it was compiled with.
+func diffPolicy(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("policy_lock_json") {
+		return nil
+	}
+	lockName, revisionId, err := parsePolicyLock(d.Get("policy_lock_json").(string))
+	if err != nil {
+		return err
+	}
+
+	if d.NewValueKnown("name") && lockName != d.Get("name").(string) {
+		return fmt.Errorf("policy_lock_json is for policy %q, not %q", lockName, d.Get("name"))
+	}
+	if config := d.GetRawConfig(); !config.IsNull() {
+		if v := config.GetAttr("revision_id"); v.IsKnown() && !v.IsNull() && v.AsString() != revisionId {
+			return fmt.Errorf("revision_id %q does not match the revision_id %q in policy_lock_json", v.AsString(), revisionId)
+		}
+	}
+
+	if d.Get("revision_id").(string) == revisionId {
+		return nil
+	}
+	return d.SetNew("revision_id", revisionId)
+}
+
+// parsePolicyLock returns the policy name and revision id a Policyfile lock
+// was compiled with.
+func parsePolicyLock(lockJson string) (string, string, error) {
+	var lock struct {
+		Name       string `json:"name"`
+		RevisionId string `json:"revision_id"`
+	}
+	if err := json.Unmarshal([]byte(lockJson), &lock); err != nil {
+		return "", "", fmt.Errorf("policy_lock_json: %s", err)
+	}
+	if lock.Name == "" || lock.RevisionId == "" {
+		return "", "", fmt.Errorf("policy_lock_json must have name and revision_id attributes, as written by chef install")
+	}
+	return lock.Name, lock.RevisionId, nil
+}
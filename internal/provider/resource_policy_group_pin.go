@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// resourceChefPolicyGroupPin pins a policy group, such as staging or
+// production, to one revision of a policy.
+func resourceChefPolicyGroupPin() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreatePolicyGroupPin,
+		UpdateContext: UpdatePolicyGroupPin,
+		ReadContext:   ReadPolicyGroupPin,
+		DeleteContext: DeletePolicyGroupPin,
+		Importer: &schema.ResourceImporter{
+			StateContext: PolicyGroupPinImporter,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"policy_group": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"policy_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"revision_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The revision to pin, which must already be uploaded, for example with chef_policy.",
+			},
+		},
+	}
+}
+
+func CreatePolicyGroupPin(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	group := d.Get("policy_group").(string)
+	name := d.Get("policy_name").(string)
+
+	if diags := pinPolicyRevision(ctx, meta.(*chefClient).Client, group, name, d.Get("revision_id").(string)); diags != nil {
+		return diags
+	}
+
+	d.SetId(group + "/" + name)
+	return ReadPolicyGroupPin(ctx, d, meta)
+}
+
+func UpdatePolicyGroupPin(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	group, name := policyGroupPinFromResourceData(d)
+
+	if diags := pinPolicyRevision(ctx, meta.(*chefClient).Client, group, name, d.Get("revision_id").(string)); diags != nil {
+		return diags
+	}
+	return ReadPolicyGroupPin(ctx, d, meta)
+}
+
+func ReadPolicyGroupPin(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	group, name := policyGroupPinFromResourceData(d)
+	pinned, err := client.PolicyGroups.GetPolicy(group, name)
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading pinned policy revision", err, nil)
+	}
+
+	d.Set("policy_group", group)
+	d.Set("policy_name", name)
+	d.Set("revision_id", pinned.RevisionID)
+
+	return nil
+}
+
+func DeletePolicyGroupPin(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	group, name := policyGroupPinFromResourceData(d)
+	if _, err := client.PolicyGroups.DeletePolicy(group, name); err != nil && !isChefNotFound(err) {
+		return chefErrToDiag("Error unpinning policy", err, nil)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func PolicyGroupPinImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	id := d.Id()
+	if parts := strings.Split(id, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected policy_group/policy_name", id)
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// policyGroupPinFromResourceData returns the group and policy an ID refers
+// to. IDs are policy_group/policy_name.
+func policyGroupPinFromResourceData(d *schema.ResourceData) (string, string) {
+	if parts := strings.SplitN(d.Id(), "/", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return d.Get("policy_group").(string), d.Get("policy_name").(string)
+}
+
+// pinPolicyRevision points a policy group at an uploaded revision. The
+// server wants the whole lock document rather than just its revision id,
+// so the revision is fetched and sent back as is.
+func pinPolicyRevision(ctx context.Context, client *chefc.Client, group, name, revisionId string) diag.Diagnostics {
+	req, err := newRequestWithContext(ctx, client, "GET", "policies/"+name+"/revisions/"+revisionId, nil)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var lock json.RawMessage
+	if _, err := client.Do(req, &lock); err != nil {
+		return chefErrToDiag("Error reading policy revision", err, cty.GetAttrPath("revision_id"))
+	}
+
+	req, err = newRequestWithContext(ctx, client, "PUT", "policy_groups/"+group+"/policies/"+name, strings.NewReader(string(lock)))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if _, err := client.Do(req, nil); err != nil {
+		return chefErrToDiag("Error pinning policy revision", err, cty.GetAttrPath("policy_group"))
+	}
+	return nil
+}
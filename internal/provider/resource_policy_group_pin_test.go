@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestPolicyGroupPin(t *testing.T) {
+	revisions := map[string]string{
+		"r1": `{"revision_id": "r1", "name": "base"}`,
+		"r2": `{"revision_id": "r2", "name": "base"}`,
+	}
+	pinned := ""
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /organizations/test/policies/base/revisions/r1":
+			w.Write([]byte(revisions["r1"]))
+		case "GET /organizations/test/policies/base/revisions/r2":
+			w.Write([]byte(revisions["r2"]))
+		case "PUT /organizations/test/policy_groups/production/policies/base":
+			body, _ := io.ReadAll(r.Body)
+			var lock struct {
+				RevisionId string `json:"revision_id"`
+			}
+			if err := json.Unmarshal(body, &lock); err != nil || revisions[lock.RevisionId] == "" {
+				t.Errorf("expected the whole lock document, got %s", body)
+			}
+			pinned = lock.RevisionId
+			w.Write(body)
+		case "GET /organizations/test/policy_groups/production/policies/base":
+			if pinned == "" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(revisions[pinned]))
+		case "DELETE /organizations/test/policy_groups/production/policies/base":
+			w.Write([]byte(revisions[pinned]))
+			pinned = ""
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefPolicyGroupPin().Schema, map[string]interface{}{
+		"policy_group": "production",
+		"policy_name":  "base",
+		"revision_id":  "r1",
+	})
+	if diags := CreatePolicyGroupPin(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "production/base" || pinned != "r1" {
+		t.Fatalf("unexpected ID %s, pinned %s", d.Id(), pinned)
+	}
+
+	// A manual promotion shows up as drift.
+	pinned = "r2"
+	if diags := ReadPolicyGroupPin(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Get("revision_id") != "r2" {
+		t.Fatalf("expected revision r2 to be read back, got %s", d.Get("revision_id"))
+	}
+
+	d.Set("revision_id", "r1")
+	if diags := UpdatePolicyGroupPin(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if pinned != "r1" {
+		t.Fatalf("expected r1 to be pinned again, got %s", pinned)
+	}
+
+	if diags := DeletePolicyGroupPin(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	d.SetId("production/base")
+	if diags := ReadPolicyGroupPin(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected an unpinned policy to be removed from state")
+	}
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const testPolicyLock = `{"revision_id": "f2bd0d4a", "name": "base", "run_list": ["recipe[base::default]"]}`
+
+func TestPolicy(t *testing.T) {
+	var uploaded string
+	deleted := false
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /organizations/test/policies/base/revisions":
+			body, _ := io.ReadAll(r.Body)
+			uploaded = string(body)
+			w.WriteHeader(http.StatusCreated)
+			w.Write(body)
+		case "GET /organizations/test/policies/base/revisions/f2bd0d4a":
+			if uploaded == "" || deleted {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(uploaded))
+		case "DELETE /organizations/test/policies/base/revisions/f2bd0d4a":
+			deleted = true
+			w.Write([]byte(uploaded))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefPolicy().Schema, map[string]interface{}{
+		"name":             "base",
+		"policy_lock_json": testPolicyLock,
+	})
+	if diags := CreatePolicy(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "base/f2bd0d4a" || d.Get("revision_id") != "f2bd0d4a" {
+		t.Fatalf("unexpected ID %s, revision %s", d.Id(), d.Get("revision_id"))
+	}
+	if uploaded != testPolicyLock {
+		t.Fatalf("expected the lock to be uploaded as is, got %s", uploaded)
+	}
+
+	imported := resourceChefPolicy().TestResourceData()
+	imported.SetId("base/f2bd0d4a")
+	if diags := ReadPolicy(context.Background(), imported, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if imported.Get("name") != "base" || imported.Get("policy_lock_json") != jsonStateFunc(testPolicyLock) {
+		t.Fatalf("unexpected imported policy %s: %s", imported.Get("name"), imported.Get("policy_lock_json"))
+	}
+
+	if diags := DeletePolicy(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	d.SetId("base/f2bd0d4a")
+	if diags := ReadPolicy(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected a deleted revision to be removed from state")
+	}
+}
+
+func TestParsePolicyLock(t *testing.T) {
+	name, revision, err := parsePolicyLock(testPolicyLock)
+	if err != nil || name != "base" || revision != "f2bd0d4a" {
+		t.Fatalf("unexpected result %q, %q, %v", name, revision, err)
+	}
+	for _, lock := range []string{`{"name": "base"}`, `{"revision_id": "f2bd0d4a"}`, `not json`} {
+		if _, _, err := parsePolicyLock(lock); err == nil {
+			t.Errorf("expected %s to be rejected", lock)
+		}
+	}
+}
@@ -0,0 +1,616 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+const (
+	replicationOverwrite = "overwrite"
+	replicationSkip      = "skip"
+	replicationFail      = "fail"
+)
+
+func resourceChefReplication() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateReplication,
+		UpdateContext: UpdateReplication,
+		ReadContext:   ReadReplication,
+		DeleteContext: DeleteReplication,
+		CustomizeDiff: diffReplication,
+
+		Schema: map[string]*schema.Schema{
+			"source": {
+				Type:     schema.TypeList,
+				Required: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"server_url": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateServerURL,
+						},
+						"client_name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"key_material": {
+							Type:      schema.TypeString,
+							Required:  true,
+							Sensitive: true,
+						},
+						"allow_unverified_ssl": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"roles": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"environments": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"data_bags": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"rewrite": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"stop_on_error": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"overwrite_policy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      replicationOverwrite,
+				ValidateFunc: validateReplicationPolicy,
+			},
+			"copied": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"skipped": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"drifted": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Objects whose copy on the destination server is missing or no longer matches the source, or that were deleted from the source.",
+			},
+		},
+	}
+}
+
+// chefReplication copies objects from a source Chef server into the
+// server the provider is configured against.
+type chefReplication struct {
+	Source  *chefc.Client
+	Dest    *chefc.Client
+	Rewrite map[string]string
+	Policy  string
+	Copied  []string
+	Skipped []string
+}
+
+func CreateReplication(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// The ID is set first so that, when some objects fail, the ones that
+	// were copied are still recorded in state.
+	d.SetId(d.Get("source.0.server_url").(string))
+	if diags := replicate(d, meta); diags != nil {
+		if len(d.Get("copied").([]interface{})) == 0 {
+			d.SetId("")
+		}
+		return diags
+	}
+
+	return ReadReplication(ctx, d, meta)
+}
+
+func UpdateReplication(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := replicate(d, meta); diags != nil {
+		return diags
+	}
+
+	return ReadReplication(ctx, d, meta)
+}
+
+func ReadReplication(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	r, diags := newReplication(d, meta)
+	if diags != nil {
+		return diags
+	}
+
+	// Objects left alone under the skip policy only drift by going missing,
+	// since their content was never the source's.
+	skipped := make(map[string]bool)
+	for _, id := range d.Get("skipped").([]interface{}) {
+		skipped[id.(string)] = true
+	}
+
+	// An object deleted from the source drifts too, rather than failing the
+	// read: the destination's copy no longer matches anything. Destination
+	// reads report a missing copy as nil, so a not-found error here always
+	// comes from the source.
+	drifted := make([]string, 0)
+	check := func(id string, want, got interface{}, missing bool) error {
+		if missing {
+			drifted = append(drifted, id)
+			return nil
+		}
+		if skipped[id] {
+			return nil
+		}
+		same, err := sameJSON(want, got)
+		if err != nil {
+			return fmt.Errorf("comparing %s: %s", id, err)
+		}
+		if !same {
+			drifted = append(drifted, id)
+		}
+		return nil
+	}
+
+	for _, name := range sortedSetStrings(d.Get("roles").(*schema.Set)) {
+		want, got, err := r.role(name)
+		if isChefNotFound(err) {
+			drifted = append(drifted, "role/"+name)
+			continue
+		}
+		if err == nil {
+			err = check("role/"+name, want, got, got == nil)
+		}
+		if err != nil {
+			return chefErrToDiag("Error reading replicated Chef objects", err, cty.GetAttrPath("roles"))
+		}
+	}
+	for _, name := range sortedSetStrings(d.Get("environments").(*schema.Set)) {
+		want, got, err := r.environment(name)
+		if isChefNotFound(err) {
+			drifted = append(drifted, "environment/"+name)
+			continue
+		}
+		if err == nil {
+			err = check("environment/"+name, want, got, got == nil)
+		}
+		if err != nil {
+			return chefErrToDiag("Error reading replicated Chef objects", err, cty.GetAttrPath("environments"))
+		}
+	}
+	for _, name := range sortedSetStrings(d.Get("data_bags").(*schema.Set)) {
+		itemIds, err := r.dataBagItemIds(name)
+		if isChefNotFound(err) {
+			drifted = append(drifted, "data_bag/"+name)
+			continue
+		}
+		if err != nil {
+			return chefErrToDiag("Error reading replicated Chef objects", err, cty.GetAttrPath("data_bags"))
+		}
+		for _, itemId := range itemIds {
+			want, got, err := r.dataBagItem(name, itemId)
+			if isChefNotFound(err) {
+				drifted = append(drifted, "data_bag/"+name+"/"+itemId)
+				continue
+			}
+			if err == nil {
+				err = check("data_bag/"+name+"/"+itemId, want, got, got == nil)
+			}
+			if err != nil {
+				return chefErrToDiag("Error reading replicated Chef objects", err, cty.GetAttrPath("data_bags"))
+			}
+		}
+	}
+
+	d.Set("drifted", drifted)
+	return nil
+}
+
+func DeleteReplication(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Objects that were replicated are intentionally left in place on the
+	// destination server.
+	d.SetId("")
+	return nil
+}
+
+// diffReplication plans to replicate again whenever an object has drifted
+// on the destination since the last apply.
+func diffReplication(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || len(d.Get("drifted").([]interface{})) == 0 {
+		return nil
+	}
+	return d.SetNew("drifted", []string{})
+}
+
+func validateReplicationPolicy(val interface{}, key string) (warns []string, errs []error) {
+	switch policy := val.(string); policy {
+	case replicationOverwrite, replicationSkip, replicationFail:
+	default:
+		errs = append(errs, fmt.Errorf("%s must be one of %q, %q or %q, got %q",
+			key, replicationOverwrite, replicationSkip, replicationFail, policy))
+	}
+	return
+}
+
+// newReplication returns a replication from the configured source server
+// into the server the provider is configured against.
+func newReplication(d *schema.ResourceData, meta interface{}) (*chefReplication, diag.Diagnostics) {
+	client := meta.(*chefClient)
+
+	source, err := replicationSourceClient(d, client.options)
+	if err != nil {
+		return nil, diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Error creating source Chef Client",
+				Detail:        fmt.Sprint(err),
+				AttributePath: cty.GetAttrPath("source"),
+			},
+		}
+	}
+
+	r := &chefReplication{
+		Source:  source,
+		Dest:    client.Client,
+		Rewrite: make(map[string]string),
+		Policy:  d.Get("overwrite_policy").(string),
+	}
+	for k, v := range d.Get("rewrite").(map[string]interface{}) {
+		r.Rewrite[k] = v.(string)
+	}
+	return r, nil
+}
+
+func replicate(d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	r, diags := newReplication(d, meta)
+	if diags != nil {
+		return diags
+	}
+
+	steps := []struct {
+		attr   string
+		prefix string
+		copy   func(string) error
+	}{
+		{"roles", "role/", r.copyRole},
+		{"environments", "environment/", r.copyEnvironment},
+		{"data_bags", "data_bag/", r.copyDataBag},
+	}
+	var ops []bulkOperation
+	for _, step := range steps {
+		run := step.copy
+		for _, name := range sortedSetStrings(d.Get(step.attr).(*schema.Set)) {
+			name := name
+			ops = append(ops, bulkOperation{
+				ID:  step.prefix + name,
+				Run: func() error { return run(name) },
+			})
+		}
+	}
+
+	err := runBulk(ops, d.Get("stop_on_error").(bool))
+
+	// Record what was copied even when some objects failed, since those
+	// writes have already happened on the destination.
+	d.Set("copied", r.Copied)
+	d.Set("skipped", r.Skipped)
+
+	if err != nil {
+		return chefErrToDiag("Error replicating Chef objects", err, nil)
+	}
+	return nil
+}
+
+func replicationSourceClient(d *schema.ResourceData, opts *clientOptions) (*chefc.Client, error) {
+	config := chefc.Config{
+		Name:    d.Get("source.0.client_name").(string),
+		BaseURL: d.Get("source.0.server_url").(string),
+		Key:     normalizePEM(d.Get("source.0.key_material").(string)),
+		SkipSSL: d.Get("source.0.allow_unverified_ssl").(bool),
+		Timeout: 10,
+	}
+	return opts.newClient(config)
+}
+
+// transform copies an object into out, applying the configured rewrites
+// to every string in it, map keys included, so that references such as
+// organization names can be adjusted for the destination server. Rewrites
+// are applied in order of the text they replace.
+func (r *chefReplication) transform(in interface{}, out interface{}) error {
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return err
+	}
+
+	from := make([]string, 0, len(r.Rewrite))
+	for k := range r.Rewrite {
+		from = append(from, k)
+	}
+	sort.Strings(from)
+
+	if raw, err = json.Marshal(r.rewrite(value, from)); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+func (r *chefReplication) rewrite(value interface{}, from []string) interface{} {
+	switch v := value.(type) {
+	case string:
+		for _, f := range from {
+			v = strings.ReplaceAll(v, f, r.Rewrite[f])
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = r.rewrite(v[i], from)
+		}
+		return v
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			out[r.rewrite(k, from).(string)] = r.rewrite(e, from)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// conflict decides what to do with an object that already exists on the
+// destination, returning true when it should be overwritten.
+func (r *chefReplication) conflict(id string) (bool, error) {
+	switch r.Policy {
+	case replicationSkip:
+		r.Skipped = append(r.Skipped, id)
+		return false, nil
+	case replicationFail:
+		return false, fmt.Errorf("%s already exists on the destination server", id)
+	default:
+		return true, nil
+	}
+}
+
+// role returns the source's role as it should be on the destination, and
+// the destination's copy, which is nil when there is none.
+func (r *chefReplication) role(name string) (*chefc.Role, *chefc.Role, error) {
+	id := "role/" + name
+
+	src, err := r.Source.Roles.Get(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading %s from source: %w", id, err)
+	}
+	want := &chefc.Role{}
+	if err := r.transform(src, want); err != nil {
+		return nil, nil, fmt.Errorf("transforming %s: %s", id, err)
+	}
+
+	got, err := r.Dest.Roles.Get(want.Name)
+	if err != nil {
+		if isChefNotFound(err) {
+			return want, nil, nil
+		}
+		return nil, nil, fmt.Errorf("reading %s from destination: %w", id, err)
+	}
+	return want, got, nil
+}
+
+func (r *chefReplication) copyRole(name string) error {
+	id := "role/" + name
+
+	role, existing, err := r.role(name)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		if ok, err := r.conflict(id); !ok {
+			return err
+		}
+		_, err = r.Dest.Roles.Put(role)
+	} else {
+		_, err = r.Dest.Roles.Create(role)
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s to destination: %w", id, err)
+	}
+
+	r.Copied = append(r.Copied, id)
+	return nil
+}
+
+// environment returns the source's environment as it should be on the
+// destination, and the destination's copy, which is nil when there is none.
+func (r *chefReplication) environment(name string) (*chefc.Environment, *chefc.Environment, error) {
+	id := "environment/" + name
+
+	src, err := r.Source.Environments.Get(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading %s from source: %w", id, err)
+	}
+	want := &chefc.Environment{}
+	if err := r.transform(src, want); err != nil {
+		return nil, nil, fmt.Errorf("transforming %s: %s", id, err)
+	}
+
+	got, err := r.Dest.Environments.Get(want.Name)
+	if err != nil {
+		if isChefNotFound(err) {
+			return want, nil, nil
+		}
+		return nil, nil, fmt.Errorf("reading %s from destination: %w", id, err)
+	}
+	return want, got, nil
+}
+
+func (r *chefReplication) copyEnvironment(name string) error {
+	id := "environment/" + name
+
+	env, existing, err := r.environment(name)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		if ok, err := r.conflict(id); !ok {
+			return err
+		}
+		_, err = r.Dest.Environments.Put(env)
+	} else {
+		_, err = r.Dest.Environments.Create(env)
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s to destination: %w", id, err)
+	}
+
+	r.Copied = append(r.Copied, id)
+	return nil
+}
+
+// dataBagItemIds returns the ids of the items in the source's data bag, in
+// order.
+func (r *chefReplication) dataBagItemIds(name string) ([]string, error) {
+	items, err := r.Source.DataBags.ListItems(name)
+	if err != nil {
+		return nil, fmt.Errorf("reading data_bag/%s from source: %w", name, err)
+	}
+
+	itemIds := make([]string, 0, len(*items))
+	for itemId := range *items {
+		itemIds = append(itemIds, itemId)
+	}
+	sort.Strings(itemIds)
+	return itemIds, nil
+}
+
+// dataBagItem returns the source's data bag item as it should be on the
+// destination, and the destination's copy, which is nil when there is none.
+// The copy is looked up by the item's id after rewriting, which may differ
+// from itemId on the source.
+func (r *chefReplication) dataBagItem(name, itemId string) (map[string]interface{}, interface{}, error) {
+	id := "data_bag/" + name + "/" + itemId
+
+	src, err := r.Source.DataBags.GetItem(name, itemId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading %s from source: %w", id, err)
+	}
+	var want map[string]interface{}
+	if err := r.transform(src, &want); err != nil {
+		return nil, nil, fmt.Errorf("transforming %s: %s", id, err)
+	}
+
+	got, err := r.Dest.DataBags.GetItem(name, replicatedItemId(want, itemId))
+	if err != nil {
+		if isChefNotFound(err) {
+			return want, nil, nil
+		}
+		return nil, nil, fmt.Errorf("reading %s from destination: %w", id, err)
+	}
+	return want, got, nil
+}
+
+func (r *chefReplication) copyDataBag(name string) error {
+	itemIds, err := r.dataBagItemIds(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.Dest.DataBags.ListItems(name); isChefNotFound(err) {
+		if _, err := r.Dest.DataBags.Create(&chefc.DataBag{Name: name}); err != nil {
+			return fmt.Errorf("creating data_bag/%s on destination: %w", name, err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("reading data_bag/%s from destination: %w", name, err)
+	}
+
+	for _, itemId := range itemIds {
+		id := "data_bag/" + name + "/" + itemId
+
+		item, existing, err := r.dataBagItem(name, itemId)
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			ok, cerr := r.conflict(id)
+			if !ok {
+				if cerr != nil {
+					return cerr
+				}
+				continue
+			}
+			err = r.Dest.DataBags.UpdateItem(name, replicatedItemId(item, itemId), item)
+		} else {
+			err = r.Dest.DataBags.CreateItem(name, item)
+		}
+		if err != nil {
+			return fmt.Errorf("writing %s to destination: %w", id, err)
+		}
+
+		r.Copied = append(r.Copied, id)
+	}
+
+	return nil
+}
+
+// replicatedItemId returns the id of a data bag item after rewriting,
+// falling back to its id on the source when the item has none.
+func replicatedItemId(item map[string]interface{}, itemId string) string {
+	if id, ok := item["id"].(string); ok && id != "" {
+		return id
+	}
+	return itemId
+}
+
+// sameJSON reports whether a and b encode to the same JSON value.
+func sameJSON(a, b interface{}) (bool, error) {
+	var values [2]interface{}
+	for i, v := range []interface{}{a, b} {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return false, err
+		}
+		if err := json.Unmarshal(raw, &values[i]); err != nil {
+			return false, err
+		}
+	}
+	return reflect.DeepEqual(values[0], values[1]), nil
+}
+
+func sortedSetStrings(set *schema.Set) []string {
+	values := make([]string, 0, set.Len())
+	for _, v := range set.List() {
+		values = append(values, v.(string))
+	}
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,246 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	chefc "github.com/go-chef/chef"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestReplicationTransform(t *testing.T) {
+	r := &chefReplication{
+		Rewrite: map[string]string{
+			"/organizations/prod/": "/organizations/staging/",
+			"R&D":                  `"Research"`,
+			"prod_":                "staging_",
+		},
+	}
+
+	src := &chefc.Role{
+		Name: "web",
+		DefaultAttributes: map[string]interface{}{
+			"chef_url": "https://chef.example.com/organizations/prod/",
+			"team":     "R&D",
+			"prod_db":  []interface{}{"prod_primary", float64(5432)},
+		},
+	}
+
+	role := &chefc.Role{}
+	if err := r.transform(src, role); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"chef_url":   "https://chef.example.com/organizations/staging/",
+		"team":       `"Research"`,
+		"staging_db": []interface{}{"staging_primary", float64(5432)},
+	}
+	if !reflect.DeepEqual(role.DefaultAttributes, expected) {
+		t.Fatalf("wrong attributes; expected %#v, got %#v", expected, role.DefaultAttributes)
+	}
+}
+
+func TestReplicationConflict(t *testing.T) {
+	cases := []struct {
+		policy    string
+		overwrite bool
+		err       bool
+	}{
+		{replicationOverwrite, true, false},
+		{replicationSkip, false, false},
+		{replicationFail, false, true},
+	}
+
+	for _, c := range cases {
+		r := &chefReplication{Policy: c.policy}
+		overwrite, err := r.conflict("role/web")
+		if overwrite != c.overwrite {
+			t.Errorf("%s: expected overwrite %v, got %v", c.policy, c.overwrite, overwrite)
+		}
+		if (err != nil) != c.err {
+			t.Errorf("%s: unexpected error state: %v", c.policy, err)
+		}
+	}
+}
+
+// testReplicationData returns the data of a replication from a source
+// server serving handler into the test client's server.
+func testReplicationData(t *testing.T, handler http.HandlerFunc, raw map[string]interface{}) *schema.ResourceData {
+	t.Helper()
+
+	source := testChefConfig(t, handler)
+	raw["source"] = []interface{}{map[string]interface{}{
+		"server_url":   source.BaseURL,
+		"client_name":  source.Name,
+		"key_material": source.Key,
+	}}
+	return schema.TestResourceDataRaw(t, resourceChefReplication().Schema, raw)
+}
+
+func TestReadReplication_drift(t *testing.T) {
+	source := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/organizations/test/roles/web":
+			json.NewEncoder(w).Encode(chefc.Role{Name: "web", RunList: chefc.RunList{"recipe[nginx]"}})
+		case "/organizations/test/roles/db":
+			json.NewEncoder(w).Encode(chefc.Role{Name: "db", RunList: chefc.RunList{"recipe[postgresql]"}})
+		case "/organizations/test/environments/prod":
+			json.NewEncoder(w).Encode(chefc.Environment{Name: "prod"})
+		default:
+			t.Errorf("unexpected source request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/organizations/test/roles/web":
+			json.NewEncoder(w).Encode(chefc.Role{Name: "web", RunList: chefc.RunList{"recipe[apache2]"}})
+		case "/organizations/test/roles/db":
+			json.NewEncoder(w).Encode(chefc.Role{Name: "db", RunList: chefc.RunList{"recipe[postgresql]"}})
+		case "/organizations/test/environments/prod":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":["not found"]}`))
+		default:
+			t.Errorf("unexpected destination request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := testReplicationData(t, source, map[string]interface{}{
+		"roles":        []interface{}{"web", "db"},
+		"environments": []interface{}{"prod"},
+	})
+	d.SetId("source")
+	if diags := ReadReplication(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	expected := []interface{}{"role/web", "environment/prod"}
+	if got := d.Get("drifted").([]interface{}); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v to have drifted, got %v", expected, got)
+	}
+}
+
+func TestCreateReplication_partialFailure(t *testing.T) {
+	source := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		name := strings.TrimPrefix(r.URL.Path, "/organizations/test/roles/")
+		json.NewEncoder(w).Encode(chefc.Role{Name: name})
+	}
+	var writes []string
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/organizations/test/roles/db":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":["missing read permission"]}`))
+		case r.Method == "GET":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":["not found"]}`))
+		case r.Method == "POST" && r.URL.Path == "/organizations/test/roles":
+			var role chefc.Role
+			json.NewDecoder(r.Body).Decode(&role)
+			writes = append(writes, role.Name)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected destination request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := testReplicationData(t, source, map[string]interface{}{
+		"roles": []interface{}{"web", "db"},
+	})
+	diags := CreateReplication(context.Background(), d, c)
+	if !diags.HasError() {
+		t.Fatal("expected the unreadable role to fail")
+	}
+	if !strings.Contains(diags[0].Detail, "reading role/db from destination") {
+		t.Fatalf("expected the failure to be reported as a read, got %q", diags[0].Detail)
+	}
+	if !reflect.DeepEqual(writes, []string{"web"}) {
+		t.Fatalf("expected only web to be written, got %v", writes)
+	}
+	if d.Id() == "" || !reflect.DeepEqual(d.Get("copied").([]interface{}), []interface{}{"role/web"}) {
+		t.Fatalf("expected the copied role to be kept in state, got id=%q copied=%v", d.Id(), d.Get("copied"))
+	}
+}
+
+func TestCopyDataBag_rewrittenId(t *testing.T) {
+	source := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/organizations/test/data/apps":
+			w.Write([]byte(`{"prod_web":"https://source/data/apps/prod_web"}`))
+		case "/organizations/test/data/apps/prod_web":
+			w.Write([]byte(`{"id":"prod_web","port":80}`))
+		default:
+			t.Errorf("unexpected source request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+	var put string
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /organizations/test/data/apps":
+			w.Write([]byte(`{}`))
+		case "GET /organizations/test/data/apps/staging_web":
+			w.Write([]byte(`{"id":"staging_web","port":8080}`))
+		case "PUT /organizations/test/data/apps/staging_web":
+			put = r.URL.Path
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected destination request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	d := testReplicationData(t, source, map[string]interface{}{
+		"data_bags": []interface{}{"apps"},
+		"rewrite":   map[string]interface{}{"prod_": "staging_"},
+	})
+	r, diags := newReplication(d, c)
+	if diags != nil {
+		t.Fatalf("err: %v", diags)
+	}
+	if err := r.copyDataBag("apps"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if put == "" {
+		t.Fatal("expected the item to be updated under its rewritten id")
+	}
+}
+
+func TestReadReplication_sourceDeleted(t *testing.T) {
+	source := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":["not found"]}`))
+	}
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected destination request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	d := testReplicationData(t, source, map[string]interface{}{
+		"roles":     []interface{}{"web"},
+		"data_bags": []interface{}{"apps"},
+	})
+	d.SetId("source")
+	if diags := ReadReplication(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("expected a deleted source object to be drift, got %v", diags)
+	}
+	expected := []interface{}{"role/web", "data_bag/apps"}
+	if got := d.Get("drifted").([]interface{}); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v to have drifted, got %v", expected, got)
+	}
+}
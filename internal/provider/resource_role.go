@@ -31,16 +31,26 @@ func resourceChefRole() *schema.Resource {
 				Default:  "Managed by Terraform",
 			},
 			"default_attributes_json": {
-				Type:      schema.TypeString,
-				Optional:  true,
-				Default:   "{}",
-				StateFunc: jsonStateFunc,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "{}",
+				StateFunc:        jsonStateFunc,
+				DiffSuppressFunc: suppressEquivalentJSON,
 			},
 			"override_attributes_json": {
-				Type:      schema.TypeString,
-				Optional:  true,
-				Default:   "{}",
-				StateFunc: jsonStateFunc,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "{}",
+				StateFunc:        jsonStateFunc,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"last_modified": {
+				Type:     schema.TypeString,
+				Computed: true,
 			},
 			"run_list": {
 				Type:     schema.TypeList,
@@ -71,26 +81,12 @@ func CreateRole(ctx context.Context, d *schema.ResourceData, meta interface{}) d
 
 	_, err = client.Roles.Create(role)
 	if err != nil {
-		return diag.Diagnostics{
-			{
-				Severity:      diag.Error,
-				Summary:       "Error creating Chef Role",
-				Detail:        fmt.Sprint(err),
-				AttributePath: cty.GetAttrPath("name"),
-			},
-		}
+		return chefErrToDiag("Error creating Chef Role", err, cty.GetAttrPath("name"))
 	}
 
 	d.SetId(role.Name)
 	if err = ReadRole(d, meta); err != nil {
-		return diag.Diagnostics{
-			{
-				Severity:      diag.Error,
-				Summary:       "Error reading Chef Role",
-				Detail:        fmt.Sprint(err),
-				AttributePath: cty.GetAttrPath("name"),
-			},
-		}
+		return chefErrToDiag("Error reading Chef Role", err, cty.GetAttrPath("name"))
 	}
 	return nil
 }
@@ -103,7 +99,7 @@ func UpdateRole(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	_, err = client.Roles.Put(role)
+	err = client.conditionalPut(context.Background(), "roles/"+role.Name, role, validatorsFromResourceData(d))
 	if err != nil {
 		return err
 	}
@@ -117,20 +113,19 @@ func ReadRole(d *schema.ResourceData, meta interface{}) error {
 
 	name := d.Id()
 
-	role, err := client.Roles.Get(name)
+	role := &chefc.Role{}
+	validators, err := client.getWithValidators(context.Background(), "roles/"+name, role)
 	if err != nil {
-		if errRes, ok := err.(*chefc.ErrorResponse); ok {
-			if errRes.Response.StatusCode == 404 {
-				d.SetId("")
-				return nil
-			}
-		} else {
-			return err
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
 		}
+		return err
 	}
 
 	d.Set("name", role.Name)
 	d.Set("description", role.Description)
+	validators.setResourceData(d)
 
 	defaultAttrJson, err := json.Marshal(role.DefaultAttributes)
 	if err != nil {
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"fmt"
+	"net/http"
 	"reflect"
 	"testing"
 
@@ -119,3 +120,17 @@ EOT
   run_list = ["terraform@1.0.0", "recipe[consul]", "role[foo]"]
 }
 `
+
+func TestReadRole_error(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":["missing read permission"]}`))
+	})
+
+	d := resourceChefRole().Data(nil)
+	d.SetId("web")
+	if err := ReadRole(d, c); err == nil || d.Id() != "web" {
+		t.Fatalf("expected a 403 to be reported and the role kept in state, got id=%q %v", d.Id(), err)
+	}
+}
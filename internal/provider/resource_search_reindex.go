@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// resourceChefSearchReindex pushes a single object through the search
+// indexer again. Chef Server has no API for rebuilding a whole index; that
+// is done with `chef-server-ctl reindex` on the server itself.
+func resourceChefSearchReindex() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateSearchReindex,
+		ReadContext:   ReadSearchReindex,
+		DeleteContext: DeleteSearchReindex,
+
+		Schema: map[string]*schema.Schema{
+			"index": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      "node",
+				Description:  "Search index the object belongs to: `node`, `role`, `environment` or `client`.",
+				ValidateFunc: validation.StringInSlice([]string{"node", "role", "environment", "client"}, false),
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the object to reindex. Whole indexes can't be rebuilt through the API; run `chef-server-ctl reindex` on the server instead.",
+			},
+			"triggers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"supported": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the object could be reindexed through the API. False when it doesn't exist, such as a deleted node lingering in the index, or the server doesn't accept saving it back.",
+			},
+		},
+	}
+}
+
+// searchReindexPaths maps a search index to the API collection whose
+// objects are indexed into it.
+var searchReindexPaths = map[string]string{
+	"node":        "nodes",
+	"role":        "roles",
+	"environment": "environments",
+	"client":      "clients",
+}
+
+func CreateSearchReindex(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+
+	index := d.Get("index").(string)
+	name := d.Get("name").(string)
+
+	// Saving an object back unchanged makes the server push it through the
+	// indexer again, which replaces any stale document.
+	path := searchReindexPaths[index] + "/" + name
+	err := reindexObject(ctx, client, path)
+	if err != nil && !isChefNotFound(err) && !isReindexUnsupported(err) {
+		return chefErrToDiag("Error reindexing Chef search", err, cty.GetAttrPath("name"))
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", index, name))
+	d.Set("supported", err == nil)
+
+	switch {
+	case err == nil:
+		return nil
+	case isChefNotFound(err):
+		return diag.Diagnostics{
+			{
+				Severity: diag.Warning,
+				Summary:  "Search reindexing is not supported for missing objects",
+				Detail: fmt.Sprintf("%s does not exist, so it can't be saved back to reindex it. A deleted object "+
+					"lingering in the search index can only be removed on the server, with `chef-server-ctl reindex`.", path),
+				AttributePath: cty.GetAttrPath("name"),
+			},
+		}
+	default:
+		return diag.Diagnostics{
+			{
+				Severity: diag.Warning,
+				Summary:  "Search reindexing is not supported",
+				Detail: fmt.Sprintf("The Chef server does not accept saving %s back (%s), so it can't be reindexed "+
+					"through the API. Run `chef-server-ctl reindex` on the server instead.", path, err),
+				AttributePath: cty.GetAttrPath("name"),
+			},
+		}
+	}
+}
+
+func ReadSearchReindex(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func DeleteSearchReindex(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
+
+// reindexObject saves the object at path back unchanged. The save is made
+// conditional on any ETag or Last-Modified the read returned, but Chef
+// Server sends neither for these objects, so in practice it is not: a save
+// made in between, such as by chef-client, is overwritten with the copy
+// read a round trip earlier.
+func reindexObject(ctx context.Context, client *chefClient, path string) error {
+	var object map[string]interface{}
+	validators, err := client.getWithValidators(ctx, path, &object)
+	if err != nil {
+		return err
+	}
+
+	return client.conditionalPut(ctx, path, object, validators)
+}
+
+// isReindexUnsupported reports whether err is the server refusing the
+// method outright, rather than the request failing.
+func isReindexUnsupported(err error) bool {
+	var errRes *chefc.ErrorResponse
+	if errors.As(err, &errRes) && errRes.Response != nil {
+		code := errRes.StatusCode()
+		return code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented
+	}
+	return false
+}
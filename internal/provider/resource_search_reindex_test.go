@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestCreateSearchReindex_object(t *testing.T) {
+	var ifMatch string
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/organizations/test/nodes/web1" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if r.Method == "PUT" {
+			ifMatch = r.Header.Get("If-Match")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"name":"web1"}`))
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefSearchReindex().Schema, map[string]interface{}{
+		"name": "web1",
+	})
+	if diags := CreateSearchReindex(context.Background(), d, c); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if ifMatch != `"v1"` {
+		t.Fatalf("expected the node to be saved back guarded by its ETag, got If-Match %q", ifMatch)
+	}
+	if d.Id() != "node/web1" || !d.Get("supported").(bool) {
+		t.Fatalf("unexpected id %q", d.Id())
+	}
+}
+
+func TestCreateSearchReindex_notFound(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefSearchReindex().Schema, map[string]interface{}{
+		"index": "role",
+		"name":  "web",
+	})
+	diags := CreateSearchReindex(context.Background(), d, c)
+	if diags.HasError() {
+		t.Fatalf("expected a missing object to be reported as unsupported, got %v", diags)
+	}
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected a warning, got %v", diags)
+	}
+	if d.Id() != "role/web" || d.Get("supported").(bool) {
+		t.Fatalf("expected an unsupported reindex to be recorded, got %q", d.Id())
+	}
+}
+
+func TestCreateSearchReindex_unsupported(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"web1"}`))
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefSearchReindex().Schema, map[string]interface{}{
+		"name": "web1",
+	})
+	diags := CreateSearchReindex(context.Background(), d, c)
+	if diags.HasError() || len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected a warning, got %v", diags)
+	}
+	if d.Get("supported").(bool) {
+		t.Fatal("expected supported to be false")
+	}
+}
+
+func TestCreateSearchReindex_forbidden(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefSearchReindex().Schema, map[string]interface{}{
+		"name": "web1",
+	})
+	if diags := CreateSearchReindex(context.Background(), d, c); !diags.HasError() {
+		t.Fatal("expected other failures to be errors")
+	}
+	if d.Id() != "" {
+		t.Fatalf("expected nothing to be recorded, got %q", d.Id())
+	}
+}
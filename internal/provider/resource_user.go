@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func resourceChefUser() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateUser,
+		UpdateContext: UpdateUser,
+		ReadContext:   ReadUser,
+		DeleteContext: DeleteUser,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"username": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"email": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"display_name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"first_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"last_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"password": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Password for logging in to the Chef server. It is only ever sent to the server, never read back, so changes made outside of Terraform are not detected.",
+			},
+			"create_key": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				ForceNew:    true,
+				Description: "If set, the Chef server generates a default key pair for the user when it is created.",
+			},
+			"private_key": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Private key generated by the Chef server when create_key is set. The server only returns it when the user is created.",
+			},
+		},
+	}
+}
+
+func CreateUser(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	user := userFromResourceData(d)
+	user.Password = d.Get("password").(string)
+	user.CreateKey = d.Get("create_key").(bool)
+
+	result, err := c.Global.Users.Create(user)
+	if err != nil {
+		return chefErrToDiag("Error creating user", err, cty.GetAttrPath("username"))
+	}
+
+	d.SetId(user.UserName)
+	// private_key is never refreshed: the server only returns it once.
+	d.Set("private_key", result.ChefKey.PrivateKey)
+	return ReadUser(ctx, d, meta)
+}
+
+func UpdateUser(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	// Updating a user replaces the whole object, so every attribute is sent
+	// again. The password is only included when it changed, since it can't
+	// be compared against the server's.
+	user := userFromResourceData(d)
+	if d.HasChange("password") {
+		user.Password = d.Get("password").(string)
+	}
+
+	if _, err := c.Global.Users.Update(d.Id(), user); err != nil {
+		return chefErrToDiag("Error updating user", err, cty.GetAttrPath("username"))
+	}
+
+	return ReadUser(ctx, d, meta)
+}
+
+func ReadUser(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	user, err := c.Global.Users.Get(d.Id())
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading user", err, cty.GetAttrPath("username"))
+	}
+
+	d.Set("username", d.Id())
+	d.Set("email", user.Email)
+	d.Set("display_name", user.DisplayName)
+	d.Set("first_name", user.FirstName)
+	d.Set("last_name", user.LastName)
+	return nil
+}
+
+func DeleteUser(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	if err := c.Global.Users.Delete(d.Id()); err != nil && !isChefNotFound(err) {
+		return chefErrToDiag("Error deleting user", err, cty.GetAttrPath("username"))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func userFromResourceData(d *schema.ResourceData) chefc.User {
+	return chefc.User{
+		UserName:    d.Get("username").(string),
+		Email:       d.Get("email").(string),
+		DisplayName: d.Get("display_name").(string),
+		FirstName:   d.Get("first_name").(string),
+		LastName:    d.Get("last_name").(string),
+	}
+}
@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -17,6 +18,11 @@ func resourceChefUserKey() *schema.Resource {
 		UpdateContext: UpdateUserKey,
 		ReadContext:   ReadUserKey,
 		DeleteContext: DeleteUserKey,
+		CustomizeDiff: diffUserKey,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: UserKeyImporter,
+		},
 
 		Schema: map[string]*schema.Schema{
 			"user": {
@@ -29,9 +35,39 @@ func resourceChefUserKey() *schema.Resource {
 				Optional: true,
 				Default:  "default",
 			},
+			"expiration_date": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          chefTimestampInfinity,
+				Description:      "When the key expires, as an ISO 8601 timestamp, or `infinity` for a key that never expires.",
+				ValidateFunc:     validateChefExpirationDate,
+				DiffSuppressFunc: chefExpirationDiffSuppressFunc,
+			},
 			"public_key": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ConflictsWith:    []string{"create_key"},
+				DiffSuppressFunc: publicKeyDiffSuppressFunc,
+				ValidateFunc:     validatePublicKeyPEM,
+			},
+			"create_key": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"public_key"},
+				Description:   "If set, the Chef server generates the key pair instead of public_key being given.",
+			},
+			"private_key": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Private key generated by the Chef server when create_key is set. The server only returns it when a key is generated, so it is only populated when the key is created or rotated; an imported key has none.",
+			},
+			"rotate_trigger": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Any value, such as a date. Changing it generates a new key pair and swaps it in under the same key_name, updating private_key. The new key is added and confirmed present before the old one is replaced. Requires create_key.",
 			},
 		},
 	}
@@ -50,14 +86,25 @@ func CreateUserKey(ctx context.Context, d *schema.ResourceData, meta interface{}
 		return err
 	}
 
-	if _, err := c.Global.Users.AddKey(key.User, key.Key); err != nil {
-		return diag.Diagnostics{
-			{
-				Severity:      diag.Error,
-				Summary:       "Error creating user key",
-				Detail:        fmt.Sprint(err),
-				AttributePath: cty.GetAttrPath("key_name"),
-			},
+	if d.Get("create_key").(bool) {
+		privateKey, err := generateAccessKey(ctx, c.Global, "users/"+key.User, key.Key.Name, key.Key.ExpirationDate)
+		if err != nil {
+			return chefErrToDiag("Error creating user key", err, cty.GetAttrPath("create_key"))
+		}
+		d.Set("private_key", privateKey)
+	} else {
+		if key.Key.PublicKey == "" {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Missing public key",
+					Detail:        "public_key must be set unless create_key is set.",
+					AttributePath: cty.GetAttrPath("public_key"),
+				},
+			}
+		}
+		if err := addAccessKey(ctx, c.Global, "users/"+key.User, key.Key); err != nil {
+			return chefErrToDiag("Error creating user key", err, cty.GetAttrPath("key_name"))
 		}
 	}
 
@@ -73,15 +120,16 @@ func UpdateUserKey(ctx context.Context, d *schema.ResourceData, meta interface{}
 		return err
 	}
 
-	if _, err := c.Global.Users.UpdateKey(key.User, key.Key.Name, key.Key); err != nil {
-		return diag.Diagnostics{
-			{
-				Severity:      diag.Error,
-				Summary:       "Error updating user key",
-				Detail:        fmt.Sprint(err),
-				AttributePath: cty.GetAttrPath("key_name"),
-			},
+	if d.HasChange("rotate_trigger") && d.Get("create_key").(bool) {
+		diags := rotateUserKey(ctx, c, d, key)
+		if diags.HasError() {
+			return diags
 		}
+		return append(diags, ReadUserKey(ctx, d, meta)...)
+	}
+
+	if err := updateAccessKey(ctx, c.Global, "users/"+key.User+"/keys/"+key.Key.Name, key.Key); err != nil {
+		return chefErrToDiag("Error updating user key", err, cty.GetAttrPath("key_name"))
 	}
 
 	d.SetId(key.User + "+" + key.Key.Name)
@@ -91,31 +139,25 @@ func UpdateUserKey(ctx context.Context, d *schema.ResourceData, meta interface{}
 func ReadUserKey(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*chefClient)
 
-	key, err := userKeyFromResourceData(d)
-	if err != nil {
-		return err
+	key, diags := userKeyFromResourceData(d)
+	if diags != nil {
+		return diags
 	}
 
-	if k, err := c.Global.Users.GetKey(key.User, key.Key.Name); err == nil {
-		d.Set("user", key.User)
-		d.Set("key_name", k.Name)
-		d.Set("public_key", k.PublicKey)
-	} else {
-		if errRes, ok := err.(*chefc.ErrorResponse); ok {
-			if errRes.Response.StatusCode == 404 {
-				d.SetId("")
-			}
-		} else {
-			return diag.Diagnostics{
-				{
-					Severity:      diag.Error,
-					Summary:       "Error reading user key",
-					Detail:        fmt.Sprint(err),
-					AttributePath: cty.GetAttrPath("key_name"),
-				},
-			}
+	// private_key is never refreshed: the server only returns it once.
+	k, err := getAccessKey(ctx, c.Global, fmt.Sprintf("users/%s/keys/%s", key.User, key.Key.Name))
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
 		}
+		return chefErrToDiag("Error reading user key", err, cty.GetAttrPath("key_name"))
 	}
+
+	d.Set("user", key.User)
+	d.Set("key_name", k.Name)
+	d.Set("public_key", canonicalPublicKeyPEM(k.PublicKey))
+	d.Set("expiration_date", k.ExpirationDate.String())
 	return nil
 }
 
@@ -126,19 +168,88 @@ func DeleteUserKey(ctx context.Context, d *schema.ResourceData, meta interface{}
 	if err != nil {
 		return err
 	}
-	if _, err := c.Global.Users.DeleteKey(key.User, key.Key.Name); err == nil {
+	if err := deleteAccessKey(ctx, c.Global, "users/"+key.User+"/keys/"+key.Key.Name); err == nil {
 		d.SetId("")
 		return nil
 	} else {
+		return chefErrToDiag("Error deleting user key", err, cty.GetAttrPath("key_name"))
+	}
+}
+
+// UserKeyImporter accepts IDs of the form user+key_name. Only the first +
+// separates the two, since user names can't contain one but key names can.
+func UserKeyImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	id := d.Id()
+	i := strings.Index(id, "+")
+	if i <= 0 || i == len(id)-1 {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected user+key_name", id)
+	}
+
+	d.Set("user", id[:i])
+	d.Set("key_name", id[i+1:])
+	return []*schema.ResourceData{d}, nil
+}
+
+// diffUserKey plans a new private_key and public_key whenever
+// rotate_trigger changes, and refuses to rotate a key whose public key is
+// given in the configuration, since there is no private key to generate.
+func diffUserKey(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.HasChange("rotate_trigger") {
+		return nil
+	}
+	if !d.Get("create_key").(bool) {
+		return fmt.Errorf("rotate_trigger can only rotate keys generated with create_key")
+	}
+	if err := d.SetNewComputed("private_key"); err != nil {
+		return err
+	}
+	return d.SetNewComputed("public_key")
+}
+
+// rotateUserKey replaces a generated key with a new one under the same
+// name. The new pair is first generated as a separate key, so the server
+// holds it before anything is replaced; only once it reads back is the
+// original key's public key swapped for it with Users.UpdateKey. Until
+// then the old private key keeps working.
+func rotateUserKey(ctx context.Context, c *chefClient, d *schema.ResourceData, key *chefUserKey) diag.Diagnostics {
+	path := "users/" + key.User
+	rotating := key.Key.Name + "-rotating"
+
+	// A rotation that failed part way may have left its key behind.
+	if err := deleteAccessKey(ctx, c.Global, path+"/keys/"+rotating); err != nil && !isChefNotFound(err) {
+		return chefErrToDiag("Error removing a previous rotation key", err, cty.GetAttrPath("rotate_trigger"))
+	}
+
+	privateKey, err := generateAccessKey(ctx, c.Global, path, rotating, key.Key.ExpirationDate)
+	if err != nil {
+		return chefErrToDiag("Error generating rotated user key", err, cty.GetAttrPath("rotate_trigger"))
+	}
+
+	generated, err := getAccessKey(ctx, c.Global, path+"/keys/"+rotating)
+	if err == nil && generated.PublicKey == "" {
+		err = fmt.Errorf("the server returned no public key for %s", rotating)
+	}
+	if err != nil {
+		return chefErrToDiag("Error confirming rotated user key", err, cty.GetAttrPath("rotate_trigger"))
+	}
+
+	key.Key.PublicKey = canonicalPublicKeyPEM(generated.PublicKey)
+	if err := updateAccessKey(ctx, c.Global, "users/"+key.User+"/keys/"+key.Key.Name, key.Key); err != nil {
+		return chefErrToDiag("Error replacing user key", err, cty.GetAttrPath("key_name"))
+	}
+	d.Set("private_key", privateKey)
+
+	if err := deleteAccessKey(ctx, c.Global, path+"/keys/"+rotating); err != nil && !isChefNotFound(err) {
 		return diag.Diagnostics{
 			{
-				Severity:      diag.Error,
-				Summary:       "Error deleting user key",
-				Detail:        fmt.Sprint(err),
-				AttributePath: cty.GetAttrPath("key_name"),
+				Severity:      diag.Warning,
+				Summary:       "Error removing the rotation key",
+				Detail:        fmt.Sprintf("%s was rotated, but the temporary key %s holding the same public key could not be removed and should be deleted by hand: %s", key.Key.Name, rotating, err),
+				AttributePath: cty.GetAttrPath("rotate_trigger"),
 			},
 		}
 	}
+	return nil
 }
 
 func userKeyFromResourceData(d *schema.ResourceData) (*chefUserKey, diag.Diagnostics) {
@@ -146,8 +257,8 @@ func userKeyFromResourceData(d *schema.ResourceData) (*chefUserKey, diag.Diagnos
 		User: d.Get("user").(string),
 		Key: chefc.AccessKey{
 			Name:           d.Get("key_name").(string),
-			PublicKey:      d.Get("public_key").(string),
-			ExpirationDate: "infinity",
+			PublicKey:      canonicalPublicKeyPEM(d.Get("public_key").(string)),
+			ExpirationDate: normalizeChefTimestamp(d.Get("expiration_date").(string)),
 		},
 	}
 	return key, nil
@@ -0,0 +1,301 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// resourceChefUserKeys manages a batch of a user's keys as one resource.
+// Only the keys it declares are managed: keys the user has that were never
+// listed are left alone, and removing one from keys deletes just that key.
+func resourceChefUserKeys() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateUserKeys,
+		UpdateContext: UpdateUserKeys,
+		ReadContext:   ReadUserKeysResource,
+		DeleteContext: DeleteUserKeys,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: UserKeysImporter,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"user": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"keys": {
+				Type:     schema.TypeSet,
+				Required: true,
+				// Keys are identified by name alone, so that a changed
+				// public key or expiration updates the key in place.
+				Set: func(v interface{}) int {
+					return schema.HashString(v.(map[string]interface{})["name"])
+				},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"public_key": {
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: publicKeyDiffSuppressFunc,
+							ValidateFunc:     validatePublicKeyPEM,
+						},
+						"expiration_date": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          chefTimestampInfinity,
+							Description:      "When the key expires, as an ISO 8601 timestamp, or `infinity` for a key that never expires.",
+							ValidateFunc:     validateChefExpirationDate,
+							DiffSuppressFunc: chefExpirationDiffSuppressFunc,
+						},
+					},
+				},
+			},
+			"concurrency": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     4,
+				Description: "Number of keys read or written at once.",
+			},
+		},
+	}
+}
+
+func CreateUserKeys(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId(d.Get("user").(string))
+	return reconcileUserKeys(ctx, d, meta, nil)
+}
+
+func UpdateUserKeys(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	old, _ := d.GetChange("keys")
+	return reconcileUserKeys(ctx, d, meta, userKeysByName(old.(*schema.Set)))
+}
+
+// ReadUserKeysResource refreshes each managed key, dropping any that no
+// longer exist so that they are planned to be added again.
+func ReadUserKeysResource(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	user := d.Id()
+
+	items, err := listAccessKeys(ctx, client.Global, "users/"+user)
+	if err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error listing user keys", err, cty.GetAttrPath("user"))
+	}
+	exists := make(map[string]bool, len(items))
+	for _, item := range items {
+		exists[item.Name] = true
+	}
+
+	var mu sync.Mutex
+	var keys []interface{}
+	var ops []bulkOperation
+	for _, key := range userKeysByName(d.Get("keys").(*schema.Set)) {
+		key := key
+		if !exists[key.Name] {
+			continue
+		}
+		ops = append(ops, bulkOperation{
+			ID: "key/" + key.Name,
+			Run: func() error {
+				k, err := getAccessKey(ctx, client.Global, fmt.Sprintf("users/%s/keys/%s", user, key.Name))
+				if isChefNotFound(err) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				keys = append(keys, flattenUserKeysItem(key, k))
+				return nil
+			},
+		})
+	}
+	if err := runBulkConcurrent(ops, d.Get("concurrency").(int)); err != nil {
+		return chefErrToDiag("Error reading user keys", err, cty.GetAttrPath("keys"))
+	}
+
+	d.Set("user", user)
+	d.Set("keys", keys)
+	return nil
+}
+
+func DeleteUserKeys(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*chefClient)
+	user := d.Id()
+
+	var ops []bulkOperation
+	for name := range userKeysByName(d.Get("keys").(*schema.Set)) {
+		name := name
+		ops = append(ops, bulkOperation{
+			ID:  "key/" + name,
+			Run: func() error { return deleteUserKey(ctx, client, user, name) },
+		})
+	}
+	sortBulkOperations(ops)
+
+	if err := runBulkConcurrent(ops, d.Get("concurrency").(int)); err != nil {
+		return chefErrToDiag("Error deleting user keys", err, cty.GetAttrPath("keys"))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// UserKeysImporter takes a user name and brings every key the user
+// currently has under management.
+func UserKeysImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*chefClient)
+
+	items, err := listAccessKeys(ctx, client.Global, "users/"+d.Id())
+	if err != nil {
+		return nil, fmt.Errorf("listing keys of user %s: %s", d.Id(), err)
+	}
+
+	keys := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, map[string]interface{}{"name": item.Name})
+	}
+	d.Set("user", d.Id())
+	d.Set("keys", keys)
+	return []*schema.ResourceData{d}, nil
+}
+
+// reconcileUserKeys adds declared keys that are new, updates ones that
+// changed from previous and deletes previous keys no longer declared. Keys
+// that fail are dropped from state by the read that follows, so the next
+// plan retries them.
+func reconcileUserKeys(ctx context.Context, d *schema.ResourceData, meta interface{}, previous map[string]chefc.AccessKey) diag.Diagnostics {
+	client := meta.(*chefClient)
+	user := d.Id()
+	desired := userKeysByName(d.Get("keys").(*schema.Set))
+
+	adding := make(map[string]bool)
+	deleting := make(map[string]bool)
+	var ops []bulkOperation
+	for name, key := range desired {
+		key := key
+		old, ok := previous[name]
+		switch {
+		case !ok:
+			adding[name] = true
+			ops = append(ops, bulkOperation{
+				ID:  "key/" + name,
+				Run: func() error { return addAccessKey(ctx, client.Global, "users/"+user, key) },
+			})
+		case !sameUserKey(old, key):
+			ops = append(ops, bulkOperation{
+				ID:  "key/" + name,
+				Run: func() error { return updateAccessKey(ctx, client.Global, "users/"+user+"/keys/"+key.Name, key) },
+			})
+		}
+	}
+	for name := range previous {
+		name := name
+		if _, ok := desired[name]; !ok {
+			deleting[name] = true
+			ops = append(ops, bulkOperation{
+				ID:  "key/" + name,
+				Run: func() error { return deleteUserKey(ctx, client, user, name) },
+			})
+		}
+	}
+	sortBulkOperations(ops)
+
+	err := runBulkConcurrent(ops, d.Get("concurrency").(int))
+	diags := ReadUserKeysResource(ctx, d, meta)
+	if err == nil || diags.HasError() {
+		return diags
+	}
+
+	// A key that failed to be added may be someone else's key by the same
+	// name, so it isn't taken over; one that failed to be deleted is still
+	// there, so it stays in state for the next apply to delete.
+	if bulkErr, ok := err.(*bulkError); ok {
+		keys := d.Get("keys").(*schema.Set)
+		for _, id := range bulkErr.Failed {
+			name := strings.TrimPrefix(id, "key/")
+			switch {
+			case adding[name]:
+				keys.Remove(map[string]interface{}{"name": name})
+			case deleting[name]:
+				key := previous[name]
+				keys.Add(map[string]interface{}{
+					"name":            name,
+					"public_key":      key.PublicKey,
+					"expiration_date": key.ExpirationDate,
+				})
+			}
+		}
+		d.Set("keys", keys)
+	}
+	return append(chefErrToDiag("Error updating user keys", err, cty.GetAttrPath("keys")), diags...)
+}
+
+func deleteUserKey(ctx context.Context, client *chefClient, user, name string) error {
+	if err := deleteAccessKey(ctx, client.Global, "users/"+user+"/keys/"+name); err != nil && !isChefNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// userKeysByName returns the keys in set, keyed by name, with public keys
+// and expiration dates normalized.
+func userKeysByName(set *schema.Set) map[string]chefc.AccessKey {
+	keys := make(map[string]chefc.AccessKey, set.Len())
+	for _, v := range set.List() {
+		m := v.(map[string]interface{})
+		expiration, _ := m["expiration_date"].(string)
+		if expiration == "" {
+			expiration = chefTimestampInfinity
+		}
+		publicKey, _ := m["public_key"].(string)
+		keys[m["name"].(string)] = chefc.AccessKey{
+			Name:           m["name"].(string),
+			PublicKey:      canonicalPublicKeyPEM(publicKey),
+			ExpirationDate: normalizeChefTimestamp(expiration),
+		}
+	}
+	return keys
+}
+
+func sameUserKey(a, b chefc.AccessKey) bool {
+	return a.PublicKey == b.PublicKey && normalizeChefExpiration(a.ExpirationDate) == normalizeChefExpiration(b.ExpirationDate)
+}
+
+// flattenUserKeysItem returns the server's view of a key. The declared
+// expiration date is kept when the server only wrote it differently, such
+// as a far future date for infinity.
+func flattenUserKeysItem(declared chefc.AccessKey, k chefAccessKey) map[string]interface{} {
+	expiration := k.ExpirationDate.String()
+	if normalizeChefExpiration(expiration) == normalizeChefExpiration(declared.ExpirationDate) {
+		expiration = declared.ExpirationDate
+	}
+	return map[string]interface{}{
+		"name":            declared.Name,
+		"public_key":      canonicalPublicKeyPEM(k.PublicKey),
+		"expiration_date": expiration,
+	}
+}
+
+func sortBulkOperations(ops []bulkOperation) {
+	sort.Slice(ops, func(i, j int) bool { return ops[i].ID < ops[j].ID })
+}
@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// testUserKeyServer serves alice's keys from keys, counting the requests
+// that change them by method.
+func testUserKeyServer(t *testing.T, keys map[string]chefc.AccessKey) (*chefClient, map[string][]string) {
+	var mu sync.Mutex
+	changes := map[string][]string{}
+
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		const prefix = "/organizations/test/users/alice/keys"
+		name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		switch {
+		case r.Method == "GET" && name == "":
+			items := []chefc.KeyItem{}
+			for n := range keys {
+				items = append(items, chefc.KeyItem{Name: n})
+			}
+			json.NewEncoder(w).Encode(items)
+		case r.Method == "POST":
+			var key chefc.AccessKey
+			json.NewDecoder(r.Body).Decode(&key)
+			changes["POST"] = append(changes["POST"], key.Name)
+			if _, ok := keys[key.Name]; ok {
+				w.WriteHeader(http.StatusConflict)
+				w.Write([]byte(`{"error":["key already exists"]}`))
+				return
+			}
+			keys[key.Name] = key
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		case r.Method == "PUT":
+			var key chefc.AccessKey
+			json.NewDecoder(r.Body).Decode(&key)
+			changes["PUT"] = append(changes["PUT"], name)
+			keys[name] = key
+			json.NewEncoder(w).Encode(key)
+		default:
+			key, ok := keys[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":["not found"]}`))
+				return
+			}
+			if r.Method == "DELETE" {
+				changes["DELETE"] = append(changes["DELETE"], name)
+				delete(keys, name)
+			}
+			json.NewEncoder(w).Encode(key)
+		}
+	})
+	return c, changes
+}
+
+func testUserKeysConfig(keys ...map[string]interface{}) map[string]interface{} {
+	list := make([]interface{}, len(keys))
+	for i, k := range keys {
+		list[i] = k
+	}
+	return map[string]interface{}{"user": "alice", "keys": list}
+}
+
+func TestUserKeys(t *testing.T) {
+	laptop, ci, deploy := testPublicKeyPEM(t), testPublicKeyPEM(t), testPublicKeyPEM(t)
+	keys := map[string]chefc.AccessKey{
+		"default": {Name: "default", PublicKey: testPublicKeyPEM(t), ExpirationDate: "infinity"},
+	}
+	c, changes := testUserKeyServer(t, keys)
+
+	d := schema.TestResourceDataRaw(t, resourceChefUserKeys().Schema, testUserKeysConfig(
+		map[string]interface{}{"name": "laptop", "public_key": laptop},
+		map[string]interface{}{"name": "ci", "public_key": ci, "expiration_date": "2030-01-02T03:04:05Z"},
+	))
+	if diags := CreateUserKeys(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "alice" || d.Get("keys").(*schema.Set).Len() != 2 {
+		t.Fatalf("expected both keys in state, got %s %v", d.Id(), d.Get("keys"))
+	}
+	if keys["ci"].ExpirationDate != "2030-01-02T03:04:05Z" || keys["laptop"].PublicKey != laptop {
+		t.Fatalf("unexpected keys on the server %v", keys)
+	}
+
+	// ci is dropped, laptop gets an expiration date and deploy is added.
+	state := d.State()
+	d = resourceChefUserKeys().Data(state)
+	d.Set("keys", []interface{}{
+		map[string]interface{}{"name": "laptop", "public_key": laptop, "expiration_date": "2031-01-01T00:00:00Z"},
+		map[string]interface{}{"name": "deploy", "public_key": deploy, "expiration_date": "infinity"},
+	})
+	d.SetId("alice")
+	old := resourceChefUserKeys().Data(state)
+	if diags := reconcileUserKeys(context.Background(), d, c, userKeysByName(old.Get("keys").(*schema.Set))); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	for _, writes := range changes {
+		sort.Strings(writes)
+	}
+	if got := fmt.Sprint(changes["POST"], changes["PUT"], changes["DELETE"]); got != "[ci deploy laptop] [laptop] [ci]" {
+		t.Fatalf("expected only the changed keys to be written, got %s", got)
+	}
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if got := fmt.Sprint(names); got != "[default deploy laptop]" {
+		t.Fatalf("expected undeclared keys to be left alone, got %s", got)
+	}
+
+	// A key replaced outside Terraform shows up as drift.
+	keys["deploy"] = chefc.AccessKey{Name: "deploy", PublicKey: laptop, ExpirationDate: "infinity"}
+	if diags := ReadUserKeysResource(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	for _, v := range d.Get("keys").(*schema.Set).List() {
+		m := v.(map[string]interface{})
+		if m["name"] == "deploy" && m["public_key"] != laptop {
+			t.Fatalf("expected the replaced public key in state, got %v", m["public_key"])
+		}
+	}
+}
+
+func TestCreateUserKeys_conflict(t *testing.T) {
+	keys := map[string]chefc.AccessKey{
+		"default": {Name: "default", PublicKey: testPublicKeyPEM(t), ExpirationDate: "infinity"},
+	}
+	c, _ := testUserKeyServer(t, keys)
+
+	d := schema.TestResourceDataRaw(t, resourceChefUserKeys().Schema, testUserKeysConfig(
+		map[string]interface{}{"name": "default", "public_key": testPublicKeyPEM(t)},
+		map[string]interface{}{"name": "laptop", "public_key": testPublicKeyPEM(t)},
+	))
+	diags := CreateUserKeys(context.Background(), d, c)
+	if !diags.HasError() {
+		t.Fatal("expected adding an existing key to fail")
+	}
+
+	set := d.Get("keys").(*schema.Set)
+	if set.Len() != 1 || set.List()[0].(map[string]interface{})["name"] != "laptop" {
+		t.Fatalf("expected only the key that was added in state, got %v", set.List())
+	}
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func resourceChefUserPassword() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateUserPassword,
+		UpdateContext: UpdateUserPassword,
+		ReadContext:   ReadUserPassword,
+		DeleteContext: DeleteUserPassword,
+
+		Schema: map[string]*schema.Schema{
+			"user": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"password": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"triggers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"generated": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func CreateUserPassword(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := resetUserPassword(d, meta); diags != nil {
+		return diags
+	}
+
+	d.SetId(d.Get("user").(string))
+	return ReadUserPassword(ctx, d, meta)
+}
+
+func UpdateUserPassword(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if d.HasChange("password") {
+		if diags := resetUserPassword(d, meta); diags != nil {
+			return diags
+		}
+	}
+
+	return ReadUserPassword(ctx, d, meta)
+}
+
+func ReadUserPassword(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+
+	if _, err := c.Global.Users.Get(d.Id()); err != nil {
+		if isChefNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return chefErrToDiag("Error reading user", err, cty.GetAttrPath("user"))
+	}
+
+	return nil
+}
+
+func DeleteUserPassword(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// There is no way to un-set a password, so the last one set stays in
+	// effect on the server.
+	d.SetId("")
+	return nil
+}
+
+// resetUserPassword sets the user's password to the configured value, or to
+// a random one when none is configured. A generated password is never
+// written to state or logs, which effectively locks out password logins
+// until it is reset again.
+func resetUserPassword(d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*chefClient)
+	name := d.Get("user").(string)
+
+	password := d.Get("password").(string)
+	generated := password == ""
+	if generated {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Error generating password",
+					Detail:   fmt.Sprint(err),
+				},
+			}
+		}
+		password = base64.RawURLEncoding.EncodeToString(buf)
+	}
+
+	// Updating a user replaces the whole object, so start from the current
+	// user rather than sending the password alone.
+	user, err := c.Global.Users.Get(name)
+	if err != nil {
+		return chefErrToDiag("Error reading user", err, cty.GetAttrPath("user"))
+	}
+	user.Password = password
+
+	if _, err := c.Global.Users.Update(name, user); err != nil {
+		var errRes *chefc.ErrorResponse
+		if errors.As(err, &errRes) && errRes.Response != nil && errRes.StatusCode() == http.StatusBadRequest {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Chef server rejected the new password",
+					Detail:        fmt.Sprintf("The password does not meet the server's password policy: %s", errRes.StatusMsg()),
+					AttributePath: cty.GetAttrPath("password"),
+				},
+			}
+		}
+		return chefErrToDiag("Error resetting user password", err, cty.GetAttrPath("password"))
+	}
+
+	d.Set("generated", generated)
+	return nil
+}
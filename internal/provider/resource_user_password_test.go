@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestCreateUserPassword_generated(t *testing.T) {
+	var sent map[string]interface{}
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			json.NewDecoder(r.Body).Decode(&sent)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"username":"jdoe","email":"jdoe@example.com"}`))
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefUserPassword().Schema, map[string]interface{}{
+		"user": "jdoe",
+	})
+	if diags := CreateUserPassword(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if sent["email"] != "jdoe@example.com" {
+		t.Fatalf("existing user fields were not preserved: %v", sent)
+	}
+	if password, _ := sent["password"].(string); len(password) < 32 {
+		t.Fatalf("expected a generated password, got %q", password)
+	}
+	if !d.Get("generated").(bool) {
+		t.Fatal("expected generated to be true")
+	}
+	if d.Get("password").(string) != "" {
+		t.Fatal("generated password must not be stored")
+	}
+}
+
+func TestCreateUserPassword_policyViolation(t *testing.T) {
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "PUT" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":["Password must have at least 6 characters"]}`))
+			return
+		}
+		w.Write([]byte(`{"username":"jdoe"}`))
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceChefUserPassword().Schema, map[string]interface{}{
+		"user":     "jdoe",
+		"password": "abc",
+	})
+	diags := CreateUserPassword(context.Background(), d, c)
+	if !diags.HasError() {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(diags[0].Detail, "at least 6 characters") {
+		t.Fatalf("policy message not surfaced: %q", diags[0].Detail)
+	}
+	if strings.Contains(diags[0].Detail, "abc") {
+		t.Fatal("password leaked into diagnostics")
+	}
+}
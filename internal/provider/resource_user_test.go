@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestUserLifecycle(t *testing.T) {
+	users := map[string]chefc.User{}
+	var sent []chefc.User
+	config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/users":
+			var user chefc.User
+			json.NewDecoder(r.Body).Decode(&user)
+			sent = append(sent, user)
+			users[user.UserName] = user
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(chefc.UserResult{
+				Uri:     "https://chef/users/alice",
+				ChefKey: chefc.ChefKey{Name: "default", PrivateKey: "PRIVATE"},
+			})
+		case r.URL.Path == "/users/alice":
+			user, ok := users["alice"]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":["not found"]}`))
+				return
+			}
+			switch r.Method {
+			case "PUT":
+				json.NewDecoder(r.Body).Decode(&user)
+				sent = append(sent, user)
+				users["alice"] = user
+				json.NewEncoder(w).Encode(chefc.UserResult{Uri: "https://chef/users/alice"})
+				return
+			case "DELETE":
+				delete(users, "alice")
+			}
+			// The server never returns the password.
+			user.Password = ""
+			json.NewEncoder(w).Encode(user)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "organizations/test/")
+	global, err := chefc.NewClient(&config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c := &chefClient{global, global, &clientOptions{}, ""}
+
+	d := schema.TestResourceDataRaw(t, resourceChefUser().Schema, map[string]interface{}{
+		"username":     "alice",
+		"email":        "alice@example.com",
+		"display_name": "Alice",
+		"password":     "hunter22",
+		"create_key":   true,
+	})
+	if diags := CreateUser(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if created := sent[0]; created.Password != "hunter22" || !created.CreateKey || created.Email != "alice@example.com" {
+		t.Fatalf("unexpected user sent on create %+v", created)
+	}
+	if d.Id() != "alice" || d.Get("private_key").(string) != "PRIVATE" {
+		t.Fatalf("unexpected state id=%q private_key=%q", d.Id(), d.Get("private_key"))
+	}
+	if d.Get("password").(string) != "hunter22" {
+		t.Fatal("expected the configured password to be kept rather than read back")
+	}
+
+	d.Set("last_name", "Smith")
+	if diags := UpdateUser(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if updated := sent[1]; updated.Password != "hunter22" || updated.Email != "alice@example.com" || updated.LastName != "Smith" {
+		t.Fatalf("expected the whole user to be sent on update, got %+v", updated)
+	}
+	if d.Get("last_name").(string) != "Smith" {
+		t.Fatalf("expected last_name to be read back, got %q", d.Get("last_name"))
+	}
+
+	if diags := DeleteUser(context.Background(), d, c); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	d.SetId("alice")
+	if diags := ReadUser(context.Background(), d, c); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected a deleted user to be removed from state, got id=%q %v", d.Id(), diags)
+	}
+}
@@ -0,0 +1,212 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// resourceChefValidatorKey manages the keys of the organization's
+// validator client, ORG-validator, which bootstraps new nodes. The
+// validator is left with exactly one key, generated by the provider, or
+// with none at all when disabled.
+func resourceChefValidatorKey() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: CreateValidatorKey,
+		UpdateContext: UpdateValidatorKey,
+		ReadContext:   ReadValidatorKey,
+		DeleteContext: DeleteValidatorKey,
+
+		Schema: map[string]*schema.Schema{
+			"rotation": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: "Changing this value replaces the validator key with a new one.",
+			},
+			"disabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If set, every validator key is revoked, so that nodes can no longer bootstrap with it.",
+			},
+			"create_key": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If set, the Chef server generates the key pair instead of the provider, and returns its private key in private_key_pem. Changing this value replaces the validator key.",
+			},
+			"validator_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"key_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"public_key": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"private_key_pem": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func validatorActor(meta interface{}) keyActor {
+	c := meta.(*chefClient)
+	return keyActor{Type: "client", Name: c.Org + "-validator", client: c.Client}
+}
+
+func CreateValidatorKey(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := applyValidatorKey(ctx, d, meta); diags != nil {
+		return diags
+	}
+
+	d.SetId(validatorActor(meta).Name)
+	return ReadValidatorKey(ctx, d, meta)
+}
+
+func UpdateValidatorKey(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if d.HasChanges("rotation", "disabled", "create_key") {
+		if diags := applyValidatorKey(ctx, d, meta); diags != nil {
+			return diags
+		}
+	}
+
+	return ReadValidatorKey(ctx, d, meta)
+}
+
+func ReadValidatorKey(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	actor := validatorActor(meta)
+
+	keys, err := listAccessKeys(ctx, actor.client, actor.path())
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  "Error listing validator keys",
+				Detail:   fmt.Sprintf("%s: %s", actor.Name, err),
+			},
+		}
+	}
+
+	// Any key other than the managed one, or a missing managed key, means
+	// the validator was changed outside Terraform and must be re-applied.
+	keyName := d.Get("key_name").(string)
+	expected := 0
+	if !d.Get("disabled").(bool) {
+		expected = 1
+	}
+	found := 0
+	for _, key := range keys {
+		if key.Name != keyName {
+			found = -1
+			break
+		}
+		found++
+	}
+	if found != expected {
+		d.SetId("")
+		return nil
+	}
+
+	// The public key is refreshed from the server, which also confirms the
+	// managed key can still be read by name.
+	if keyName != "" {
+		key, err := getAccessKey(ctx, actor.client, fmt.Sprintf("%s/keys/%s", actor.path(), keyName))
+		if err != nil {
+			if isChefNotFound(err) {
+				d.SetId("")
+				return nil
+			}
+			return chefErrToDiag("Error reading validator key", err, cty.GetAttrPath("key_name"))
+		}
+		d.Set("public_key", key.PublicKey)
+	}
+
+	d.Set("validator_name", actor.Name)
+	return nil
+}
+
+func DeleteValidatorKey(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// The managed key is left in place; deleting it would stop nodes from
+	// bootstrapping just because Terraform stopped managing the validator.
+	d.SetId("")
+	return nil
+}
+
+// applyValidatorKey either revokes every validator key or replaces them
+// all with a newly generated one.
+func applyValidatorKey(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	actor := validatorActor(meta)
+
+	keys, err := listAccessKeys(ctx, actor.client, actor.path())
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  "Error listing validator keys",
+				Detail:   fmt.Sprintf("%s: %s", actor.Name, err),
+			},
+		}
+	}
+
+	keyName, publicKey, privateKey := "", "", ""
+	if !d.Get("disabled").(bool) {
+		keyName = "validator-" + time.Now().UTC().Format("20060102T150405Z")
+		if d.Get("create_key").(bool) {
+			// The server only returns the private key; the public key is
+			// filled in by the read that follows.
+			privateKey, err = generateAccessKey(ctx, actor.client, actor.path(), keyName, "infinity")
+		} else if privateKey, publicKey, err = generateKeyPair(); err == nil {
+			err = actor.addKey(ctx, chefc.AccessKey{
+				Name:           keyName,
+				PublicKey:      publicKey,
+				ExpirationDate: "infinity",
+			})
+		}
+		if err != nil {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Error adding validator key",
+					Detail:        fmt.Sprintf("%s: %s", actor.Name, err),
+					AttributePath: cty.GetAttrPath("rotation"),
+				},
+			}
+		}
+	}
+
+	d.Set("key_name", keyName)
+	d.Set("public_key", publicKey)
+	d.Set("private_key_pem", privateKey)
+
+	for _, key := range keys {
+		if key.Name == keyName {
+			continue
+		}
+		if err := actor.deleteKey(ctx, key.Name); err != nil && !isChefNotFound(err) {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Error revoking validator key",
+					Detail:        fmt.Sprintf("%s key %s: %s", actor.Name, key.Name, err),
+					AttributePath: cty.GetAttrPath("disabled"),
+				},
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// chefServices lists the client services whose base path can be overridden
+// with the service_base_paths provider setting, keyed by the name used in
+// configuration. Each entry copies the service from a client built against
+// the overridden base path onto the provider's client.
+var chefServices = map[string]func(dst, src *chefc.Client){
+	"acls":               func(dst, src *chefc.Client) { dst.ACLs = src.ACLs },
+	"associations":       func(dst, src *chefc.Client) { dst.Associations = src.Associations },
+	"authenticate_user":  func(dst, src *chefc.Client) { dst.AuthenticateUser = src.AuthenticateUser },
+	"clients":            func(dst, src *chefc.Client) { dst.Clients = src.Clients },
+	"containers":         func(dst, src *chefc.Client) { dst.Containers = src.Containers },
+	"cookbook_artifacts": func(dst, src *chefc.Client) { dst.CookbookArtifacts = src.CookbookArtifacts },
+	"cookbooks":          func(dst, src *chefc.Client) { dst.Cookbooks = src.Cookbooks },
+	"data":               func(dst, src *chefc.Client) { dst.DataBags = src.DataBags },
+	"environments":       func(dst, src *chefc.Client) { dst.Environments = src.Environments },
+	"groups":             func(dst, src *chefc.Client) { dst.Groups = src.Groups },
+	"license":            func(dst, src *chefc.Client) { dst.License = src.License },
+	"nodes":              func(dst, src *chefc.Client) { dst.Nodes = src.Nodes },
+	"organizations":      func(dst, src *chefc.Client) { dst.Organizations = src.Organizations },
+	"policies":           func(dst, src *chefc.Client) { dst.Policies = src.Policies },
+	"policy_groups":      func(dst, src *chefc.Client) { dst.PolicyGroups = src.PolicyGroups },
+	"principals":         func(dst, src *chefc.Client) { dst.Principals = src.Principals },
+	"required_recipe":    func(dst, src *chefc.Client) { dst.RequiredRecipe = src.RequiredRecipe },
+	"roles":              func(dst, src *chefc.Client) { dst.Roles = src.Roles },
+	"sandboxes":          func(dst, src *chefc.Client) { dst.Sandboxes = src.Sandboxes },
+	"search":             func(dst, src *chefc.Client) { dst.Search = src.Search },
+	"stats":              func(dst, src *chefc.Client) { dst.Stats = src.Stats },
+	"status":             func(dst, src *chefc.Client) { dst.Status = src.Status },
+	"universe":           func(dst, src *chefc.Client) { dst.Universe = src.Universe },
+	"updated_since":      func(dst, src *chefc.Client) { dst.UpdatedSince = src.UpdatedSince },
+	"users":              func(dst, src *chefc.Client) { dst.Users = src.Users },
+}
+
+func chefServiceNames() []string {
+	names := make([]string, 0, len(chefServices))
+	for name := range chefServices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyServiceBasePaths points individual services of client at a
+// different base path, for Chef-compatible servers that do not lay out
+// their API exactly like Chef Server. Each path is resolved against the
+// configured server_url, so it may be relative, absolute or a full URL,
+// and must end with a slash.
+func applyServiceBasePaths(opts *clientOptions, client *chefc.Client, config chefc.Config, overrides map[string]string) error {
+	base, err := url.Parse(config.BaseURL)
+	if err != nil {
+		return err
+	}
+
+	for service, path := range overrides {
+		assign, ok := chefServices[service]
+		if !ok {
+			return fmt.Errorf("unknown service %q in service_base_paths, expected one of: %s",
+				service, strings.Join(chefServiceNames(), ", "))
+		}
+		if !strings.HasSuffix(path, "/") {
+			return fmt.Errorf("service_base_paths path %q for %s must end with a slash", path, service)
+		}
+
+		ref, err := url.Parse(path)
+		if err != nil {
+			return fmt.Errorf("service_base_paths path for %s: %s", service, err)
+		}
+
+		serviceConfig := config
+		serviceConfig.BaseURL = base.ResolveReference(ref).String()
+		serviceClient, err := opts.newClient(serviceConfig)
+		if err != nil {
+			return err
+		}
+		assign(client, serviceClient)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestApplyServiceBasePaths(t *testing.T) {
+	var paths []string
+	config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	})
+
+	client, err := chefc.NewClient(&config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := applyServiceBasePaths(&clientOptions{}, client, config, map[string]string{"nodes": "/api/"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	client.Nodes.List()
+	client.Roles.List()
+
+	expected := []string{"/api/nodes", "/organizations/test/roles"}
+	if len(paths) != 2 || paths[0] != expected[0] || paths[1] != expected[1] {
+		t.Fatalf("wrong request paths; expected %v, got %v", expected, paths)
+	}
+}
+
+func TestApplyServiceBasePaths_invalid(t *testing.T) {
+	config := testChefConfig(t, nil)
+	client, err := chefc.NewClient(&config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := applyServiceBasePaths(&clientOptions{}, client, config, map[string]string{"bogus": "/api/"}); err == nil {
+		t.Fatal("expected an error for an unknown service")
+	}
+	if err := applyServiceBasePaths(&clientOptions{}, client, config, map[string]string{"nodes": "/api"}); err == nil {
+		t.Fatal("expected an error for a path without a trailing slash")
+	}
+}
@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// chefTimestampInfinity is the value the Chef server uses for keys that
+// never expire.
+const chefTimestampInfinity = "infinity"
+
+// chefTimestampLayouts are the formats the Chef server has been observed to
+// return timestamps in, depending on version and endpoint.
+var chefTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+}
+
+// chefTimestamp is a timestamp as returned by the Chef server, normalized on
+// decode to RFC 3339 in UTC without fractional seconds so that reformatting
+// on the server side does not show up as drift. The literal "infinity" is
+// preserved, as is any value that cannot be parsed.
+type chefTimestamp string
+
+func (t *chefTimestamp) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*t = chefTimestamp(normalizeChefTimestamp(raw))
+	return nil
+}
+
+func (t chefTimestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(t))
+}
+
+func (t chefTimestamp) String() string {
+	return string(t)
+}
+
+func normalizeChefTimestamp(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, chefTimestampInfinity) {
+		return strings.ToLower(value)
+	}
+
+	for _, layout := range chefTimestampLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC().Truncate(time.Second).Format(time.RFC3339)
+		}
+	}
+
+	return value
+}
+
+// validateChefExpirationDate accepts "infinity" or a timestamp in one of
+// the formats the Chef server understands.
+func validateChefExpirationDate(val interface{}, key string) (warns []string, errs []error) {
+	value := strings.TrimSpace(val.(string))
+	if strings.EqualFold(value, chefTimestampInfinity) {
+		return
+	}
+	for _, layout := range chefTimestampLayouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return
+		}
+	}
+	errs = append(errs, fmt.Errorf("%s must be %q or an ISO 8601 timestamp such as 2030-01-02T03:04:05Z, got %q", key, chefTimestampInfinity, value))
+	return
+}
+
+// chefExpirationDiffSuppressFunc compares expiration dates after
+// normalization. Servers that can't store "infinity" return the largest
+// date they can instead, so any date from year 9999 on is treated as
+// infinity too.
+func chefExpirationDiffSuppressFunc(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeChefExpiration(old) == normalizeChefExpiration(new)
+}
+
+func normalizeChefExpiration(value string) string {
+	value = normalizeChefTimestamp(value)
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil && parsed.Year() >= 9999 {
+		return chefTimestampInfinity
+	}
+	return value
+}
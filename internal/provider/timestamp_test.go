@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChefTimestamp_unmarshal(t *testing.T) {
+	cases := map[string]string{
+		`"infinity"`:                         "infinity",
+		`"Infinity"`:                         "infinity",
+		`""`:                                 "",
+		`"2030-01-02T03:04:05Z"`:             "2030-01-02T03:04:05Z",
+		`"2030-01-02T03:04:05.123Z"`:         "2030-01-02T03:04:05Z",
+		`"2030-01-02T03:04:05.123456+00:00"`: "2030-01-02T03:04:05Z",
+		`"2030-01-02T05:04:05+02:00"`:        "2030-01-02T03:04:05Z",
+		`"2030-01-02T03:04:05+0000"`:         "2030-01-02T03:04:05Z",
+		`"2030-01-02T03:04:05"`:              "2030-01-02T03:04:05Z",
+		`"2030-01-02 03:04:05 UTC"`:          "2030-01-02T03:04:05Z",
+		`"2030-01-02 03:04:05 +0000"`:        "2030-01-02T03:04:05Z",
+		`"2030-01-02 03:04:05"`:              "2030-01-02T03:04:05Z",
+		`"next tuesday"`:                     "next tuesday",
+	}
+
+	for in, expected := range cases {
+		var got chefTimestamp
+		if err := json.Unmarshal([]byte(in), &got); err != nil {
+			t.Errorf("%s: err: %s", in, err)
+			continue
+		}
+		if got.String() != expected {
+			t.Errorf("%s: expected %q, got %q", in, expected, got)
+		}
+	}
+}
+
+func TestChefTimestamp_marshal(t *testing.T) {
+	out, err := json.Marshal(chefTimestamp("infinity"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(out) != `"infinity"` {
+		t.Fatalf("wrong JSON: %s", out)
+	}
+}
+
+func TestChefExpirationDate(t *testing.T) {
+	for _, valid := range []string{"infinity", "Infinity", "2030-01-02T03:04:05Z", "2030-01-02 03:04:05"} {
+		if _, errs := validateChefExpirationDate(valid, "expiration_date"); len(errs) != 0 {
+			t.Errorf("%q: unexpected errors %v", valid, errs)
+		}
+	}
+	for _, invalid := range []string{"", "never", "2030-13-02T03:04:05Z"} {
+		if _, errs := validateChefExpirationDate(invalid, "expiration_date"); len(errs) == 0 {
+			t.Errorf("%q: expected an error", invalid)
+		}
+	}
+
+	cases := []struct {
+		old, new string
+		same     bool
+	}{
+		{"infinity", "infinity", true},
+		{"9999-12-31T23:59:59Z", "infinity", true},
+		{"2030-01-02T03:04:05Z", "2030-01-02T05:04:05+02:00", true},
+		{"2030-01-02T03:04:05Z", "infinity", false},
+	}
+	for _, c := range cases {
+		if got := chefExpirationDiffSuppressFunc("expiration_date", c.old, c.new, nil); got != c.same {
+			t.Errorf("%q vs %q: expected suppressed=%v, got %v", c.old, c.new, c.same, got)
+		}
+	}
+}
@@ -0,0 +1,879 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"mime"
+	"net"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	chefc "github.com/go-chef/chef"
+)
+
+// clientOptions holds the provider settings that apply to the HTTP layer
+// underneath every Chef client the provider creates.
+type clientOptions struct {
+	MaxRetries  int
+	RetryDelay  time.Duration
+	RetryBudget *retryBudget
+
+	// Concurrency, when set, is shared by every client so that the limit
+	// on in-flight requests applies across the whole provider.
+	Concurrency *concurrencyLimit
+
+	// RateLimit, when set, is likewise shared, and caps the rate requests
+	// are sent at across the whole provider.
+	RateLimit *rateLimit
+
+	// Metrics receives request, retry and error events. Nil disables them.
+	Metrics requestMetrics
+
+	// JSONContentTypes are media types, in addition to the built-in ones,
+	// whose responses are decoded as JSON.
+	JSONContentTypes []string
+
+	// StrictDecoding rejects response fields the provider's types don't
+	// have, rather than silently dropping them. It only applies to
+	// responses decoded by chefClient.do; go-chef's own service calls
+	// decode leniently whatever it is set to.
+	StrictDecoding bool
+
+	// ServerAPIVersion is sent, and signed, as X-Ops-Server-API-Version.
+	// Zero keeps go-chef's default of 1.
+	ServerAPIVersion int
+
+	// ChefVersion is sent as X-Chef-Version. Empty keeps go-chef's
+	// chefc.ChefVersion.
+	ChefVersion string
+
+	// MaxIdleConns, MaxIdleConnsPerHost and IdleConnTimeout size the idle
+	// connection pool of each client's http.Transport. Zero keeps the
+	// net/http defaults.
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+
+	// DisableCompression stops responses being requested gzip-compressed.
+	DisableCompression bool
+
+	// RequestTimeout limits how long each attempt at a request may take,
+	// including reading its response. Waits between retries and for the
+	// rate or concurrency limits don't count. Zero means no limit.
+	RequestTimeout time.Duration
+
+	// HTTPDebug, when set, is the context whose logger every request and
+	// response is traced to. Nil disables tracing.
+	HTTPDebug context.Context
+	// Connection is the provider's client configuration less its
+	// credentials: the TLS, proxy and signing settings that clients created
+	// to act as another user or client of the same server share.
+	Connection chefc.Config
+}
+
+func (o *clientOptions) metrics() requestMetrics {
+	if o.Metrics == nil {
+		return noopMetrics{}
+	}
+	return o.Metrics
+}
+
+// newClient creates a Chef client for config with the provider's transport
+// settings applied.
+func (o *clientOptions) newClient(config chefc.Config) (*chefc.Client, error) {
+	client, err := chefc.NewClient(&config)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := chefHTTPClient(client)
+	transport := httpClient.Transport
+	if tr, ok := transport.(*http.Transport); ok {
+		tr.MaxIdleConns = o.MaxIdleConns
+		tr.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+		tr.IdleConnTimeout = o.IdleConnTimeout
+		tr.DisableCompression = o.DisableCompression
+	}
+	if o.HTTPDebug != nil {
+		// Underneath apiVersionTransport, so that the headers and signature
+		// logged are the ones sent, after any re-signing.
+		transport = newHTTPDebugTransport(o.HTTPDebug, transport)
+	}
+	if o.ServerAPIVersion > 1 || o.ChefVersion != "" {
+		apiVersion := &apiVersionTransport{
+			base:        transport,
+			auth:        client.Auth,
+			chefVersion: o.ChefVersion,
+		}
+		if o.ServerAPIVersion > 1 {
+			apiVersion.version = strconv.Itoa(o.ServerAPIVersion)
+		}
+		transport = apiVersion
+	}
+	httpClient.Transport = o.wrapTransport(transport)
+	return client, nil
+}
+
+func (o *clientOptions) wrapTransport(base http.RoundTripper) http.RoundTripper {
+	if o.RequestTimeout > 0 {
+		// Innermost, rather than on the http.Client, so that the timeout
+		// applies to each attempt and not to the waits between them or for
+		// a rate limit token or concurrency slot.
+		base = &timeoutTransport{base: base, timeout: o.RequestTimeout}
+	}
+	base = &contentTypeTransport{base: base, extraJSON: o.JSONContentTypes}
+	if o.Concurrency != nil {
+		base = &concurrencyTransport{base: base, limit: o.Concurrency}
+	}
+	if o.RateLimit != nil {
+		// Outside the concurrency limit, so that a request waiting its
+		// turn doesn't hold a slot.
+		base = &rateLimitTransport{base: base, limit: o.RateLimit}
+	}
+	if o.Metrics != nil {
+		base = &metricsTransport{base: base, metrics: o.Metrics}
+	}
+	base = &errorBodyTransport{base: base}
+	if o.MaxRetries > 0 {
+		base = &retryTransport{
+			base:       base,
+			maxRetries: o.MaxRetries,
+			delay:      o.RetryDelay,
+			budget:     o.RetryBudget,
+			metrics:    o.metrics(),
+
+			maintenanceDelay: maintenanceRetryDelay,
+		}
+	}
+	return base
+}
+
+// chefHTTPClient returns the http.Client a Chef client sends its requests
+// through. go-chef does not expose it, but the provider needs to wrap its
+// transport to add retries and similar behavior to every request, including
+// those made by the library's own services.
+func chefHTTPClient(c *chefc.Client) *http.Client {
+	return (*http.Client)(reflect.ValueOf(c).Elem().FieldByName("client").UnsafePointer())
+}
+
+// apiVersionTransport replaces the server API version and Chef version
+// go-chef sends. Version 1.3 signatures cover the X-Ops-Server-API-Version
+// header, so requests are re-signed after it is changed; version 1.0
+// signatures don't, and only need the header set. Neither signs
+// X-Chef-Version.
+type apiVersionTransport struct {
+	base    http.RoundTripper
+	auth    *chefc.AuthConfig
+	version string
+
+	// chefVersion, when set, is sent as X-Chef-Version.
+	chefVersion string
+}
+
+func (t *apiVersionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Unsigned requests, such as cookbook file downloads from the
+	// bookshelf, are left alone.
+	if req.Header.Get("X-Ops-Authorization-1") == "" {
+		return t.base.RoundTrip(req)
+	}
+	resign := t.version != "" && req.Header.Get("X-Ops-Server-API-Version") != t.version
+	replaceChefVersion := t.chefVersion != "" && req.Header.Get("X-Chef-Version") != t.chefVersion
+	if !resign && !replaceChefVersion {
+		return t.base.RoundTrip(req)
+	}
+
+	r := req.Clone(req.Context())
+	if replaceChefVersion {
+		r.Header.Set("X-Chef-Version", t.chefVersion)
+	}
+	if resign {
+		if err := resignAPIVersion(t.auth, r, t.version); err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(r)
+}
+
+// resignAPIVersion sets a signed request's server API version, replacing
+// its signature when the authentication protocol signs the version.
+func resignAPIVersion(auth *chefc.AuthConfig, req *http.Request, version string) error {
+	req.Header.Set("X-Ops-Server-API-Version", version)
+	if auth.AuthenticationVersion != "1.3" {
+		return nil
+	}
+
+	vals := map[string]string{
+		"Method":                   req.Method,
+		"Path":                     req.URL.Path,
+		"X-Ops-Content-Hash":       req.Header.Get("X-Ops-Content-Hash"),
+		"X-Ops-Sign":               req.Header.Get("X-Ops-Sign"),
+		"X-Ops-Timestamp":          req.Header.Get("X-Ops-Timestamp"),
+		"X-Ops-UserId":             req.Header.Get("X-Ops-UserId"),
+		"X-Ops-Server-API-Version": version,
+	}
+	signature, err := chefc.GenerateDigestSignature(auth.PrivateKey, auth.SignatureContent(vals))
+	if err != nil {
+		return err
+	}
+
+	for name := range req.Header {
+		if strings.HasPrefix(name, "X-Ops-Authorization-") {
+			req.Header.Del(name)
+		}
+	}
+	for i, chunk := range chefc.Base64BlockEncode(signature, 60) {
+		req.Header.Set(fmt.Sprintf("X-Ops-Authorization-%d", i+1), chunk)
+	}
+	return nil
+}
+
+// retryBudget is a token bucket shared by every request the provider makes.
+// Each retry spends a token and tokens refill at a fixed rate, so a
+// degraded server cannot multiply per-request retries across a large apply
+// into an unbounded amount of retrying.
+type retryBudget struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	rate     float64
+	last     time.Time
+	now      func() time.Time
+}
+
+// newRetryBudget allows up to retries retries per period. A non-positive
+// retries means retries are unlimited, and a nil budget is returned.
+func newRetryBudget(retries int, period time.Duration) *retryBudget {
+	if retries <= 0 || period <= 0 {
+		return nil
+	}
+
+	return &retryBudget{
+		capacity: float64(retries),
+		tokens:   float64(retries),
+		rate:     float64(retries) / period.Seconds(),
+		last:     time.Now(),
+		now:      time.Now,
+	}
+}
+
+// take spends a token, reporting false when the budget is exhausted.
+func (b *retryBudget) take() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// maintenanceRetryDelay is how long to wait before retrying a request the
+// server turned away because it is in maintenance mode. Maintenance windows
+// last minutes rather than seconds, so there is no point retrying quickly.
+const maintenanceRetryDelay = 30 * time.Second
+
+// maxRetryDelay caps both the exponential backoff between retries and any
+// Retry-After the server asks for.
+const maxRetryDelay = time.Minute
+
+// retryTransport retries requests that failed at the network level, were
+// rate limited or hit a transient server error, up to maxRetries times each
+// and within the shared budget. The wait between attempts starts at delay
+// and doubles each time, with jitter, unless the server sends Retry-After.
+// Requests refused for maintenance wait maintenanceDelay instead.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	delay      time.Duration
+	budget     *retryBudget
+	metrics    requestMetrics
+
+	maintenanceDelay time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// Hold bodies that can't be replayed in memory, so that every
+		// attempt sends the bytes the request was signed with.
+		var err error
+		if req, err = replayableRequest(req); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 {
+			r = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		res, err := t.base.RoundTrip(r)
+		if !retryableResponse(req.Method, res, err) || attempt >= t.maxRetries {
+			return res, err
+		}
+		if !t.budget.take() {
+			log.Printf("[WARN] Chef retry budget exhausted, not retrying %s %s", req.Method, req.URL)
+			return res, err
+		}
+		delay := backoffDelay(t.delay, attempt)
+		if res != nil {
+			if maintenanceMode(res) && t.maintenanceDelay > delay {
+				delay = t.maintenanceDelay
+			} else if after, ok := retryAfter(res, time.Now()); ok {
+				delay = after
+			}
+		}
+		// A wait that would outlast the request's context ends in nothing
+		// but a deadline error, so the failure in hand is returned instead.
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < delay {
+			log.Printf("[DEBUG] Not retrying %s %s: waiting %s would pass the request's deadline", req.Method, req.URL, delay)
+			return res, err
+		}
+		if res != nil {
+			if maintenanceMode(res) {
+				log.Printf("[INFO] Chef server is in maintenance mode, waiting %s before retrying %s %s", delay, req.Method, req.URL)
+			}
+			res.Body.Close()
+		}
+
+		log.Printf("[DEBUG] Retrying %s %s in %s (attempt %d of %d)", req.Method, req.URL, delay, attempt+1, t.maxRetries)
+		if t.metrics != nil {
+			t.metrics.RequestRetried(req.Method, metricsEndpoint(req.URL.Path), attempt+1)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// retryableResponse reports whether a failed request may be sent again.
+// Requests that aren't idempotent, such as the POSTs that create clients,
+// users and keys, may have been carried out even though they failed, and a
+// retry would then fail with a 409 and leave an object Terraform doesn't
+// know about. They are only retried when the server provably never acted on
+// them: the connection couldn't be made, or the server turned the request
+// away with a 429 or 503 asking for a retry.
+func retryableResponse(method string, res *http.Response, err error) bool {
+	if !idempotentMethod(method) {
+		if err != nil {
+			return dialError(err)
+		}
+		switch res.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return res.Header.Get("Retry-After") != "" || maintenanceMode(res)
+		}
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// dialError reports whether err happened while connecting, before any of
+// the request was sent.
+func dialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// replayableRequest returns a copy of req with its body read into memory
+// and GetBody set.
+func replayableRequest(req *http.Request) (*http.Request, error) {
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	r.Body, _ = r.GetBody()
+	return r, nil
+}
+
+// backoffDelay is the wait before retry number attempt+1: base doubled for
+// each earlier attempt, capped at maxRetryDelay, then jittered to between
+// half and all of that so that clients retrying together spread out.
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	delay := base
+	for i := 0; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as an
+// HTTP date, capped at maxRetryDelay.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(res.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		delay = at.Sub(now)
+	} else {
+		return 0, false
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay, true
+}
+
+// timeoutTransport limits how long each attempt at a request may take,
+// from sending it until its response body has been read.
+type timeoutTransport struct {
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	res, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := releaseAfterBody(req, res, cancel); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// concurrencyLimit is a semaphore bounding the number of requests in flight
+// at once, so that high Terraform parallelism can't exhaust the server's
+// workers.
+type concurrencyLimit struct {
+	slots chan struct{}
+}
+
+// newConcurrencyLimit allows up to n requests in flight. A non-positive n
+// means no limit, and a nil limit is returned.
+func newConcurrencyLimit(n int) *concurrencyLimit {
+	if n <= 0 {
+		return nil
+	}
+	return &concurrencyLimit{slots: make(chan struct{}, n)}
+}
+
+// concurrencyTransport holds a slot of the limit from sending a request
+// until its response body has been read, since the connection stays busy
+// on the server until then.
+type concurrencyTransport struct {
+	base  http.RoundTripper
+	limit *concurrencyLimit
+}
+
+func (t *concurrencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.limit.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	var once sync.Once
+	release := func() { once.Do(func() { <-t.limit.slots }) }
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	if err := releaseAfterBody(req, res, release); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// streamedBodyContextKey marks the context of a request whose caller reads
+// the response body as it arrives, and always closes it.
+type streamedBodyContextKey struct{}
+
+// withStreamedBody marks requests made with ctx as streaming their response
+// bodies; see releaseAfterBody.
+func withStreamedBody(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamedBodyContextKey{}, true)
+}
+
+// releaseAfterBody arranges for release to run once res's body is done
+// with. go-chef's Client.Do replaces the body it is handed without closing
+// it, and may stop decoding before EOF, so the body is normally read in
+// full here and release run before returning. Requests marked with
+// withStreamedBody keep their body, which releases at EOF, on a read error
+// or on Close, whichever comes first.
+func releaseAfterBody(req *http.Request, res *http.Response, release func()) error {
+	if req.Context().Value(streamedBodyContextKey{}) != nil {
+		res.Body = &releasingBody{ReadCloser: res.Body, release: release}
+		return nil
+	}
+
+	defer release()
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return nil
+}
+
+// releasingBody may call release more than once, so it must be idempotent,
+// as a context's cancel func and the concurrency slot's release are.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.release()
+	}
+	return n, err
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
+// rateLimit is a token bucket shared by every request the provider makes,
+// including retries. Tokens refill at the configured rate, and up to a
+// second's worth can be saved up for a burst.
+type rateLimit struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// newRateLimit allows perSecond requests a second. A non-positive
+// perSecond means no limit, and a nil limit is returned.
+func newRateLimit(perSecond float64) *rateLimit {
+	if perSecond <= 0 {
+		return nil
+	}
+
+	burst := math.Max(1, math.Floor(perSecond))
+	return &rateLimit{
+		rate:   perSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token and returns how long to wait before using it.
+// Tokens may be taken before they have refilled, so that requests queue
+// up in the order they arrived.
+func (l *rateLimit) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel gives back a token reserved for a request that was never sent.
+func (l *rateLimit) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = math.Min(l.burst, l.tokens+1)
+}
+
+// wait blocks until a request may be sent, or ctx is done.
+func (l *rateLimit) wait(ctx context.Context) error {
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	}
+}
+
+// rateLimitTransport waits for the rate limit before sending each request.
+// It sits inside retryTransport, so that every retry waits for a token too,
+// and outside timeoutTransport, so that the wait doesn't use up a request's
+// timeout.
+type rateLimitTransport struct {
+	base  http.RoundTripper
+	limit *rateLimit
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limit.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// contentTypeTransport canonicalizes response content types. go-chef only
+// decodes a body as JSON, or as text into a string, when the Content-Type
+// is exactly "application/json" or "text/plain", so parameters, uppercase
+// variants and the vendor types some endpoints and proxies use would
+// otherwise fall through to its fallback handling.
+//
+// It also corrects the Content-Type go-chef gives request bodies, which it
+// detects by decoding them into a struct: empty bodies and JSON arrays are
+// labeled text/plain. Content-Type is not part of the request signature, so
+// it can be changed after signing. Unsigned requests, such as sandbox
+// uploads to presigned URLs, keep the Content-Type they were given.
+type contentTypeTransport struct {
+	base      http.RoundTripper
+	extraJSON []string
+}
+
+func (t *contentTypeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("X-Ops-Authorization-1") != "" {
+		if contentType, ok := requestContentType(req); ok && contentType != req.Header.Get("Content-Type") {
+			req = req.Clone(req.Context())
+			if contentType == "" {
+				req.Header.Del("Content-Type")
+			} else {
+				req.Header.Set("Content-Type", contentType)
+			}
+		}
+	}
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+
+	if contentType := res.Header.Get("Content-Type"); contentType != "" {
+		res.Header.Set("Content-Type", canonicalContentType(contentType, t.extraJSON))
+	}
+	return res, nil
+}
+
+// requestContentType detects the Content-Type for req's body: none for an
+// empty body, application/json for well-formed JSON and otherwise whatever
+// http.DetectContentType makes of it. ok is false when the body can't be
+// read without consuming it.
+func requestContentType(req *http.Request) (contentType string, ok bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", true
+	}
+	if req.GetBody == nil {
+		return "", false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", false
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", false
+	}
+
+	switch {
+	case len(data) == 0:
+		return "", true
+	case json.Valid(data):
+		return "application/json", true
+	default:
+		return http.DetectContentType(data), true
+	}
+}
+
+// canonicalContentType maps any JSON media type to "application/json" and
+// plain text to "text/plain", leaving other types as they are.
+func canonicalContentType(contentType string, extraJSON []string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+
+	switch {
+	case mediaType == "application/json",
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasPrefix(mediaType, "application/x-chef"):
+		return "application/json"
+	case mediaType == "text/plain":
+		return "text/plain"
+	}
+	for _, extra := range extraJSON {
+		if strings.EqualFold(mediaType, strings.TrimSpace(extra)) {
+			return "application/json"
+		}
+	}
+	return contentType
+}
+
+// errorBodyMaxLength caps how much of a non-JSON error page is kept as the
+// error message.
+const errorBodyMaxLength = 200
+
+var (
+	htmlTagPattern    = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]*>`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// errorBodyTransport rewrites error responses whose body isn't JSON, such
+// as the HTML or plain text pages proxies and load balancers in front of
+// Chef Server return, into Chef's JSON error format. go-chef only extracts
+// error messages from JSON, so without this the message would be empty.
+type errorBodyTransport struct {
+	base http.RoundTripper
+}
+
+func (t *errorBodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil || res.StatusCode < 400 {
+		return res, err
+	}
+
+	data, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var msg string
+	switch {
+	case maintenanceBody(res.StatusCode, data):
+		res.Header.Set(maintenanceModeHeader, "true")
+		msg = fmt.Sprintf("Chef server is in maintenance mode (%d %s); retry once the maintenance window has ended",
+			res.StatusCode, http.StatusText(res.StatusCode))
+	case len(data) > 0 && json.Valid(data):
+		res.Body = io.NopCloser(bytes.NewReader(data))
+		return res, nil
+	default:
+		msg = errorBodyMessage(res, data)
+	}
+
+	body, _ := json.Marshal(map[string][]string{"error": {msg}})
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	res.ContentLength = int64(len(body))
+	res.Header.Del("Content-Length")
+	res.Header.Set("Content-Type", "application/json")
+	return res, nil
+}
+
+// errorBodyMessage summarizes a non-JSON error response as its status, the
+// server that produced it when known, and the start of the page text, such
+// as "502 Bad Gateway (nginx): upstream unavailable".
+func errorBodyMessage(res *http.Response, data []byte) string {
+	msg := res.Status
+	if msg == "" {
+		msg = fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+	}
+	if server := res.Header.Get("Server"); server != "" {
+		msg += " (" + server + ")"
+	}
+
+	text := htmlTagPattern.ReplaceAllString(string(data), " ")
+	text = strings.TrimSpace(whitespacePattern.ReplaceAllString(text, " "))
+	if runes := []rune(text); len(runes) > errorBodyMaxLength {
+		text = strings.TrimSpace(string(runes[:errorBodyMaxLength])) + "..."
+	}
+	if text != "" {
+		msg += ": " + text
+	}
+	return msg
+}
+
+// maintenanceModeHeader marks a response errorBodyTransport recognized as
+// a maintenance mode refusal, so that retryTransport can tell it apart from
+// an overloaded server without reading the body again.
+const maintenanceModeHeader = "X-Terraform-Chef-Maintenance"
+
+var maintenancePattern = regexp.MustCompile(`(?i)\bmaintenance\b`)
+
+// maintenanceBody reports whether a response is Chef Server's maintenance
+// mode page. Both maintenance mode and an overloaded server answer 503, but
+// only the maintenance page mentions maintenance.
+func maintenanceBody(status int, data []byte) bool {
+	return status == http.StatusServiceUnavailable && maintenancePattern.Match(data)
+}
+
+func maintenanceMode(res *http.Response) bool {
+	return res.Header.Get(maintenanceModeHeader) != ""
+}
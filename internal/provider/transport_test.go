@@ -0,0 +1,911 @@
+package provider
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestRetryBudget(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newRetryBudget(2, time.Minute)
+	b.last = now
+	b.now = func() time.Time { return now }
+
+	if !b.take() || !b.take() {
+		t.Fatal("expected the first two retries to be allowed")
+	}
+	if b.take() {
+		t.Fatal("expected the budget to be exhausted")
+	}
+
+	now = now.Add(30 * time.Second)
+	if !b.take() {
+		t.Fatal("expected the budget to have refilled one token")
+	}
+	if b.take() {
+		t.Fatal("expected the budget to be exhausted again")
+	}
+}
+
+func TestRetryBudget_unlimited(t *testing.T) {
+	b := newRetryBudget(0, time.Minute)
+	for i := 0; i < 100; i++ {
+		if !b.take() {
+			t.Fatal("a nil budget must always allow retries")
+		}
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	var calls int
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &retryTransport{base: http.DefaultTransport, maxRetries: 3},
+	}
+	req, _ := http.NewRequest("PUT", server.URL, strings.NewReader(`{"name":"web"}`))
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK || calls != 3 {
+		t.Fatalf("expected success after 3 calls, got %d after %d", res.StatusCode, calls)
+	}
+	for _, body := range bodies {
+		if body != `{"name":"web"}` {
+			t.Fatalf("request body was not replayed: %q", body)
+		}
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransport_post(t *testing.T) {
+	response := func(status int, header http.Header) func() (*http.Response, error) {
+		return func() (*http.Response, error) {
+			if header == nil {
+				header = http.Header{}
+			}
+			return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+	}
+	failure := func(err error) func() (*http.Response, error) {
+		return func() (*http.Response, error) { return nil, err }
+	}
+
+	cases := []struct {
+		name   string
+		first  func() (*http.Response, error)
+		method string
+		calls  int
+	}{
+		{"server error", response(http.StatusBadGateway, nil), "POST", 1},
+		{"unavailable", response(http.StatusServiceUnavailable, nil), "POST", 1},
+		{"reset", failure(errors.New("connection reset by peer")), "POST", 1},
+		{"retry after", response(http.StatusServiceUnavailable, http.Header{"Retry-After": {"0"}}), "POST", 2},
+		{"rate limited", response(http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}}), "POST", 2},
+		{"maintenance", response(http.StatusServiceUnavailable, http.Header{maintenanceModeHeader: {"true"}}), "POST", 2},
+		{"dial", failure(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}), "POST", 2},
+		{"idempotent", response(http.StatusBadGateway, nil), "DELETE", 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			transport := &retryTransport{
+				base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					calls++
+					if calls == 1 {
+						return tc.first()
+					}
+					return response(http.StatusOK, nil)()
+				}),
+				maxRetries: 3,
+			}
+			req, _ := http.NewRequest(tc.method, "https://chef.example.com/organizations/test/clients", strings.NewReader(`{}`))
+			if res, err := transport.RoundTrip(req); err == nil {
+				res.Body.Close()
+			}
+			if calls != tc.calls {
+				t.Fatalf("expected %d calls, got %d", tc.calls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_budgetExhausted(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &retryTransport{
+			base:       http.DefaultTransport,
+			maxRetries: 5,
+			budget:     newRetryBudget(1, time.Hour),
+		},
+	}
+	res, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	res.Body.Close()
+
+	if calls != 2 {
+		t.Fatalf("expected the budget to allow a single retry, got %d calls", calls)
+	}
+}
+
+func TestChefHTTPClient(t *testing.T) {
+	c := testChefClient(t, nil)
+	if chefHTTPClient(c.Client) == nil {
+		t.Fatal("expected the underlying http.Client")
+	}
+}
+
+func TestNewClient_idleConnections(t *testing.T) {
+	config := testChefConfig(t, nil)
+	opts := &clientOptions{
+		MaxIdleConns:        20,
+		MaxIdleConnsPerHost: 5,
+		IdleConnTimeout:     45 * time.Second,
+	}
+	client, err := opts.newClient(config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// With no other options set, the chain is errorBody, contentType and
+	// then go-chef's own transport.
+	base := chefHTTPClient(client).Transport.(*errorBodyTransport).base.(*contentTypeTransport).base
+	tr, ok := base.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected go-chef's *http.Transport, got %T", base)
+	}
+	if tr.MaxIdleConns != 20 || tr.MaxIdleConnsPerHost != 5 || tr.IdleConnTimeout != 45*time.Second {
+		t.Fatalf("unexpected pool settings %d, %d, %s", tr.MaxIdleConns, tr.MaxIdleConnsPerHost, tr.IdleConnTimeout)
+	}
+	if tr.TLSHandshakeTimeout != 10*time.Second {
+		t.Fatalf("expected go-chef's timeouts to be kept, got %s", tr.TLSHandshakeTimeout)
+	}
+}
+
+func TestNewClient_compression(t *testing.T) {
+	var acceptEncoding string
+	config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.Contains(acceptEncoding, "gzip") {
+			w.Write([]byte(`{"name":"web1"}`))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte(`{"name":"web1"}`))
+		zw.Close()
+	})
+
+	for _, disable := range []bool{false, true} {
+		client, err := (&clientOptions{DisableCompression: disable}).newClient(config)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		node, err := client.Nodes.Get("web1")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if node.Name != "web1" {
+			t.Fatalf("expected node web1, got %q", node.Name)
+		}
+		if compressed := acceptEncoding == "gzip"; compressed == disable {
+			t.Fatalf("DisableCompression %t: unexpected Accept-Encoding %q", disable, acceptEncoding)
+		}
+	}
+}
+
+func TestNewClient_requestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	client, err := (&clientOptions{RequestTimeout: 50 * time.Millisecond}).newClient(config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := client.Nodes.Get("web1"); err == nil {
+		t.Fatal("expected the request to time out")
+	}
+
+	client, err = (&clientOptions{}).newClient(config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if timeout := chefHTTPClient(client).Timeout; timeout != 0 {
+		t.Fatalf("expected no timeout, got %s", timeout)
+	}
+}
+
+func TestErrorBodyTransport(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			"html",
+			"text/html",
+			"<html><head><title>502 Bad Gateway</title></head><body><h1>upstream unavailable</h1></body></html>",
+			"502 Bad Gateway (nginx): 502 Bad Gateway upstream unavailable",
+		},
+		{"text", "text/plain", "upstream unavailable\n", "502 Bad Gateway (nginx): upstream unavailable"},
+		{"empty", "text/plain", "", "502 Bad Gateway (nginx)"},
+		{"json", "application/json", `{"error":["no upstream"]}`, "no upstream"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Server", "nginx")
+				w.Header().Set("Content-Type", tc.contentType)
+				w.WriteHeader(http.StatusBadGateway)
+				w.Write([]byte(tc.body))
+			})
+			client, err := (&clientOptions{}).newClient(config)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			_, err = client.Roles.Get("web")
+			var errRes *chefc.ErrorResponse
+			if !errors.As(err, &errRes) {
+				t.Fatalf("expected an ErrorResponse, got %v", err)
+			}
+			if got := errRes.StatusMsg(); got != tc.want {
+				t.Fatalf("expected message %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestErrorBodyTransport_maintenance(t *testing.T) {
+	cases := []struct {
+		name        string
+		body        string
+		maintenance bool
+	}{
+		{"maintenance", "<html><body>Chef Server is down for maintenance</body></html>", true},
+		{"overloaded", "<html><body>Service Unavailable</body></html>", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &errorBodyTransport{base: http.DefaultTransport}}
+			res, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			body, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+
+			if got := maintenanceMode(res); got != tc.maintenance {
+				t.Fatalf("expected maintenance mode %v, got %v", tc.maintenance, got)
+			}
+			if got := strings.Contains(string(body), "maintenance mode"); got != tc.maintenance {
+				t.Fatalf("unexpected error body %s", body)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_maintenanceDelay(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("down for maintenance"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &retryTransport{
+			base:             &errorBodyTransport{base: http.DefaultTransport},
+			maxRetries:       3,
+			maintenanceDelay: 200 * time.Millisecond,
+		},
+	}
+	start := time.Now()
+	res, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK || calls != 2 {
+		t.Fatalf("expected success after 2 calls, got %d after %d", res.StatusCode, calls)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("expected to wait the maintenance delay before retrying, waited %s", elapsed)
+	}
+}
+
+func TestErrorBodyMessage_truncated(t *testing.T) {
+	res := &http.Response{Status: "500 Internal Server Error", StatusCode: 500, Header: http.Header{}}
+	msg := errorBodyMessage(res, []byte(strings.Repeat("x", 1000)))
+	if want := "500 Internal Server Error: " + strings.Repeat("x", errorBodyMaxLength) + "..."; msg != want {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
+
+func TestConcurrencyTransport(t *testing.T) {
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &concurrencyTransport{
+			base:  http.DefaultTransport,
+			limit: newConcurrencyLimit(2),
+		},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := client.Get(server.URL)
+			if err != nil {
+				t.Errorf("err: %s", err)
+				return
+			}
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Fatalf("expected at most 2 requests in flight, saw %d", peak)
+	}
+}
+
+// go-chef's Client.Do never closes the response body it is handed, so the
+// slot must be released without waiting for Close.
+func TestNewClient_concurrencyReleasedThroughChefDo(t *testing.T) {
+	config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"web1"}`))
+	})
+
+	client, err := (&clientOptions{
+		Concurrency:    newConcurrencyLimit(1),
+		RequestTimeout: time.Second,
+	}).newClient(config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < 4; i++ {
+			if _, err := client.Nodes.Get("web1"); err != nil {
+				done <- fmt.Errorf("request %d: %s", i, err)
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("requests blocked waiting for a concurrency slot")
+	}
+}
+
+func TestConcurrencyTransport_streamedBodyReleasedAtEOF(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("streamed"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &concurrencyTransport{
+			base:  http.DefaultTransport,
+			limit: newConcurrencyLimit(1),
+		},
+	}
+
+	req, err := http.NewRequestWithContext(withStreamedBody(context.Background()), "GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer res.Body.Close()
+	if body, _ := io.ReadAll(res.Body); string(body) != "streamed" {
+		t.Fatalf("expected the body to be streamed through, got %q", body)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err = http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	second, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("expected the slot to be released at EOF, got %s", err)
+	}
+	second.Body.Close()
+}
+
+func TestNewConcurrencyLimit_unlimited(t *testing.T) {
+	if newConcurrencyLimit(0) != nil {
+		t.Fatal("expected no limit for 0")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newRateLimit(2)
+	l.last = now
+	l.now = func() time.Time { return now }
+
+	if l.reserve() != 0 || l.reserve() != 0 {
+		t.Fatal("expected a burst of one second's worth of requests")
+	}
+	if delay := l.reserve(); delay != 500*time.Millisecond {
+		t.Fatalf("expected the next request to wait 500ms, got %s", delay)
+	}
+	if delay := l.reserve(); delay != time.Second {
+		t.Fatalf("expected the request after to queue behind it, got %s", delay)
+	}
+
+	now = now.Add(10 * time.Second)
+	if l.reserve() != 0 || l.reserve() != 0 || l.reserve() == 0 {
+		t.Fatal("expected the saved-up burst to be capped at one second's worth")
+	}
+}
+
+func TestNewClient_rateLimitOutsideTimeout(t *testing.T) {
+	config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"web1"}`))
+	})
+
+	// After the burst of two, each request waits half a second for a token,
+	// longer than the request timeout.
+	client, err := (&clientOptions{
+		RateLimit:      newRateLimit(2),
+		RequestTimeout: 100 * time.Millisecond,
+	}).newClient(config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := client.Nodes.Get("web1"); err != nil {
+			t.Fatalf("request %d: %s", i, err)
+		}
+	}
+}
+
+func TestRateLimitTransport_contextCancelled(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	limit := newRateLimit(0.1)
+	client := &http.Client{Transport: &rateLimitTransport{base: http.DefaultTransport, limit: limit}}
+
+	res, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	res.Body.Close()
+
+	// The next token is ten seconds away.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	start := time.Now()
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to end with the context, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected a cancelled request not to wait for the bucket, waited %s", elapsed)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("expected the cancelled request not to be sent, got %d requests", n)
+	}
+	if limit.tokens < -0.5 {
+		t.Fatalf("expected the cancelled request's token to be given back, got %v tokens", limit.tokens)
+	}
+}
+
+func TestNewRateLimit_unlimited(t *testing.T) {
+	if newRateLimit(0) != nil {
+		t.Fatal("expected no limit for 0")
+	}
+}
+
+func TestCanonicalContentType(t *testing.T) {
+	extra := []string{"application/x-custom-data"}
+	cases := map[string]string{
+		"application/json":                "application/json",
+		"Application/JSON; charset=UTF-8": "application/json",
+		"application/vnd.chef+json":       "application/json",
+		"application/x-chef-cookbook":     "application/json",
+		"application/X-Custom-Data":       "application/json",
+		"TEXT/PLAIN; charset=utf-8":       "text/plain",
+		"text/html":                       "text/html",
+		"not a; valid=\"content type":     "not a; valid=\"content type",
+	}
+	for in, want := range cases {
+		if got := canonicalContentType(in, extra); got != want {
+			t.Errorf("%q: expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestContentTypeTransport_decodes(t *testing.T) {
+	config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "Application/JSON; charset=utf-8")
+		w.Write([]byte(`{"name":"web"}`))
+	})
+	client, err := (&clientOptions{}).newClient(config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	role, err := client.Roles.Get("web")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if role.Name != "web" {
+		t.Fatalf("unexpected role %#v", role)
+	}
+}
+
+func TestContentTypeTransport_requests(t *testing.T) {
+	var got []string
+	config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Content-Type"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	})
+	client, err := (&clientOptions{}).newClient(config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var gzipped bytes.Buffer
+	zw := gzip.NewWriter(&gzipped)
+	zw.Write([]byte("cookbook"))
+	zw.Close()
+
+	cases := []struct {
+		body     []byte
+		expected string
+	}{
+		{nil, ""},
+		{[]byte{}, ""},
+		{[]byte(`{"name":"web1"}`), "application/json"},
+		{[]byte(`["web1","web2"]`), "application/json"},
+		{[]byte(`{"name":`), "text/plain; charset=utf-8"},
+		{gzipped.Bytes(), "application/x-gzip"},
+	}
+	for i, c := range cases {
+		var body io.Reader
+		if c.body != nil {
+			body = bytes.NewReader(c.body)
+		}
+		req, err := client.NewRequest("PUT", "nodes/web1", body)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if _, err := client.Do(req, nil); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if got[i] != c.expected {
+			t.Errorf("case %d: expected Content-Type %q, got %q", i, c.expected, got[i])
+		}
+	}
+
+	// Unsigned requests, such as sandbox uploads, are left alone.
+	req, err := http.NewRequest("PUT", client.BaseURL.String()+"bookshelf/abc", strings.NewReader(`{"name":"web1"}`))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/x-binary")
+	res, err := chefHTTPClient(client).Do(req)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	res.Body.Close()
+	if last := got[len(got)-1]; last != "application/x-binary" {
+		t.Errorf("expected an unsigned request to keep its Content-Type, got %q", last)
+	}
+}
+
+func TestAPIVersionTransport_signed(t *testing.T) {
+	for _, authVersion := range []string{"1.0", "1.3"} {
+		t.Run(authVersion, func(t *testing.T) {
+			var client *chefc.Client
+			config := testChefConfig(t, func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("X-Ops-Server-API-Version"); got != "2" {
+					t.Errorf("expected server API version 2, got %q", got)
+				}
+				if authVersion != "1.3" {
+					return
+				}
+
+				vals := map[string]string{
+					"Method":                   r.Method,
+					"Path":                     r.URL.Path,
+					"X-Ops-Content-Hash":       r.Header.Get("X-Ops-Content-Hash"),
+					"X-Ops-Sign":               r.Header.Get("X-Ops-Sign"),
+					"X-Ops-Timestamp":          r.Header.Get("X-Ops-Timestamp"),
+					"X-Ops-UserId":             r.Header.Get("X-Ops-UserId"),
+					"X-Ops-Server-API-Version": r.Header.Get("X-Ops-Server-API-Version"),
+				}
+				content := client.Auth.SignatureContent(vals)
+				if !strings.Contains(content, "X-Ops-Server-API-Version:2") {
+					t.Errorf("expected the canonical string to contain the version, got %q", content)
+				}
+
+				var encoded string
+				for i := 1; r.Header.Get(fmt.Sprintf("X-Ops-Authorization-%d", i)) != ""; i++ {
+					encoded += r.Header.Get(fmt.Sprintf("X-Ops-Authorization-%d", i))
+				}
+				signature, _ := base64.StdEncoding.DecodeString(encoded)
+				hashed := sha256.Sum256([]byte(content))
+				if err := rsa.VerifyPKCS1v15(&client.Auth.PrivateKey.PublicKey, crypto.SHA256, hashed[:], signature); err != nil {
+					t.Errorf("signature does not cover the server API version: %s", err)
+				}
+			})
+			config.AuthenticationVersion = authVersion
+
+			var err error
+			client, err = (&clientOptions{ServerAPIVersion: 2}).newClient(config)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			req, err := client.NewRequest("GET", "users/alice/keys", nil)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			res, err := client.Do(req, nil)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			res.Body.Close()
+		})
+	}
+}
+
+func TestRetryTransport_retryAfter(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"name":"web"}` {
+			t.Errorf("request body was not replayed: %q", body)
+		}
+		if calls < 2 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &retryTransport{base: http.DefaultTransport, maxRetries: 3},
+	}
+	// A reader net/http can't rewind on its own.
+	req, _ := http.NewRequest("POST", server.URL, io.MultiReader(strings.NewReader(`{"name":"web"}`)))
+	start := time.Now()
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK || calls != 2 {
+		t.Fatalf("expected success after 2 calls, got %d after %d", res.StatusCode, calls)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("expected to wait for Retry-After before retrying, waited %s", elapsed)
+	}
+}
+
+func TestRetryTransport_contextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &retryTransport{base: http.DefaultTransport, maxRetries: 3, delay: time.Hour},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(100*time.Millisecond, cancel)
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("expected waiting to retry to stop when the context is cancelled")
+	}
+}
+
+func TestRetryTransport_deadline(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("down for maintenance"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &retryTransport{
+			base:             &errorBodyTransport{base: http.DefaultTransport},
+			maxRetries:       3,
+			maintenanceDelay: time.Hour,
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("expected the maintenance response rather than a deadline error, got %s", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if calls != 1 || !strings.Contains(string(body), "maintenance mode") {
+		t.Fatalf("expected the maintenance response without retrying, got %s after %d calls", body, calls)
+	}
+}
+
+func TestRetryTransport_requestTimeoutPerAttempt(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("down for maintenance"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The wait for maintenance to end is longer than the request timeout,
+	// which only applies to each attempt.
+	client := &http.Client{
+		Transport: &retryTransport{
+			base: &errorBodyTransport{
+				base: &timeoutTransport{base: http.DefaultTransport, timeout: 100 * time.Millisecond},
+			},
+			maxRetries:       3,
+			maintenanceDelay: 300 * time.Millisecond,
+		},
+	}
+	res, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || calls != 2 {
+		t.Fatalf("expected success after 2 calls, got %d after %d", res.StatusCode, calls)
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	for attempt, max := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		for i := 0; i < 20; i++ {
+			if d := backoffDelay(time.Second, attempt); d < max/2 || d > max {
+				t.Fatalf("attempt %d: delay %s outside [%s, %s]", attempt, d, max/2, max)
+			}
+		}
+	}
+	if d := backoffDelay(time.Second, 20); d > maxRetryDelay {
+		t.Fatalf("expected the delay to be capped at %s, got %s", maxRetryDelay, d)
+	}
+	if d := backoffDelay(0, 3); d != 0 {
+		t.Fatalf("expected no delay without a base delay, got %s", d)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"5":                             5 * time.Second,
+		"3600":                          maxRetryDelay,
+		"Wed, 02 Jan 2030 03:04:15 GMT": 10 * time.Second,
+		"Wed, 02 Jan 2030 03:04:00 GMT": 0,
+	}
+	for value, want := range cases {
+		res := &http.Response{Header: http.Header{"Retry-After": []string{value}}}
+		if got, ok := retryAfter(res, now); !ok || got != want {
+			t.Errorf("%q: expected %s, got %s (%v)", value, want, got, ok)
+		}
+	}
+	if _, ok := retryAfter(&http.Response{Header: http.Header{"Retry-After": []string{"soon"}}}, now); ok {
+		t.Error("expected an unparseable Retry-After to be ignored")
+	}
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// chefVersion is a cookbook version, which Chef limits to at most three
+// numeric components; missing components are zero.
+type chefVersion [3]int
+
+func parseChefVersion(s string) (chefVersion, int, error) {
+	var v chefVersion
+
+	parts := strings.Split(strings.TrimSpace(s), ".")
+	if len(parts) > 3 {
+		return v, 0, fmt.Errorf("version %q has more than three components", s)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, 0, fmt.Errorf("version %q is not of the form x.y.z", s)
+		}
+		v[i] = n
+	}
+	return v, len(parts), nil
+}
+
+func (v chefVersion) compare(o chefVersion) int {
+	for i := range v {
+		if v[i] != o[i] {
+			if v[i] < o[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// chefVersionConstraint is a cookbook version constraint such as "= 1.2.3",
+// ">= 2.0" or "~> 1.4".
+type chefVersionConstraint struct {
+	op      string
+	version chefVersion
+	parts   int
+}
+
+func parseChefVersionConstraint(s string) (chefVersionConstraint, error) {
+	var c chefVersionConstraint
+
+	s = strings.TrimSpace(s)
+	for _, op := range []string{"~>", ">=", "<=", "=", ">", "<"} {
+		if strings.HasPrefix(s, op) {
+			c.op = op
+			s = strings.TrimSpace(strings.TrimPrefix(s, op))
+			break
+		}
+	}
+	if c.op == "" {
+		// A bare version is an exact pin.
+		c.op = "="
+	}
+
+	var err error
+	if c.version, c.parts, err = parseChefVersion(s); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// satisfiedBy reports whether v meets the constraint. "~> x.y" allows any
+// x.*, at or above x.y, and "~> x.y.z" allows any x.y.*, at or above x.y.z.
+func (c chefVersionConstraint) satisfiedBy(v chefVersion) bool {
+	cmp := v.compare(c.version)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case "~>":
+		if cmp < 0 {
+			return false
+		}
+		fixed := c.parts - 1
+		if fixed < 1 {
+			fixed = 1
+		}
+		for i := 0; i < fixed; i++ {
+			if v[i] != c.version[i] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
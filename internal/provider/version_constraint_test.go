@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	chefc "github.com/go-chef/chef"
+)
+
+func TestChefVersionConstraint(t *testing.T) {
+	cases := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"= 1.2.3", "1.2.3", true},
+		{"= 1.2.3", "1.2.4", false},
+		{"1.2.3", "1.2.3", true},
+		{">= 1.2", "1.2.0", true},
+		{">= 1.2", "1.1.9", false},
+		{"> 1.2", "1.2.0", false},
+		{"< 2.0", "1.9.9", true},
+		{"<= 2.0", "2.0.0", true},
+		{"~> 1.4", "1.9.0", true},
+		{"~> 1.4", "2.0.0", false},
+		{"~> 1.4", "1.3.9", false},
+		{"~> 1.4.2", "1.4.9", true},
+		{"~> 1.4.2", "1.5.0", false},
+		{"~> 2", "2.8.0", true},
+		{"~> 2", "3.0.0", false},
+	}
+
+	for _, tc := range cases {
+		c, err := parseChefVersionConstraint(tc.constraint)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.constraint, err)
+		}
+		v, _, err := parseChefVersion(tc.version)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.version, err)
+		}
+		if got := c.satisfiedBy(v); got != tc.want {
+			t.Errorf("%q satisfied by %s: expected %t, got %t", tc.constraint, tc.version, tc.want, got)
+		}
+	}
+
+	for _, bad := range []string{"= x.y", ">= 1.2.3.4", "~>"} {
+		if _, err := parseChefVersionConstraint(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestUnsatisfiableConstraints(t *testing.T) {
+	universe := chefc.Universe{
+		Books: map[string]chefc.UniverseBook{
+			"nginx": {Versions: map[string]chefc.UniverseVersion{"1.0.0": {}, "1.2.0": {}}},
+		},
+	}
+
+	if err := unsatisfiableConstraints(map[string]string{"nginx": "~> 1.1"}, universe); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err := unsatisfiableConstraints(map[string]string{"nginx": "= 9.9.9", "apt": ">= 1.0"}, universe)
+	if err == nil {
+		t.Fatal("expected unsatisfiable constraints to be reported")
+	}
+	for _, want := range []string{`nginx "= 9.9.9"`, "available: 1.0.0, 1.2.0", `apt ">= 1.0"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %s", want, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// withWebuiKey adds an optional use_webui_key attribute to a key
+// management resource. When it is set, the resource's requests are signed
+// in webui key mode, without changing how any other resource signs.
+func withWebuiKey(r *schema.Resource) *schema.Resource {
+	r.Schema["use_webui_key"] = &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+		Description: "Sign this resource's requests as the Chef server's webui key, sending `X-Ops-Request-Source: web`. " +
+			"The server then lets the request act as the user named by the provider's client_name, which some operations " +
+			"on other users' keys require. The provider's key must be the server's webui_priv.pem. That key can act as any " +
+			"user, so keep it out of configurations that don't need it.",
+	}
+
+	r.CreateContext = asWebui(r.CreateContext)
+	r.ReadContext = asWebui(r.ReadContext)
+	r.UpdateContext = asWebui(r.UpdateContext)
+	r.DeleteContext = asWebui(r.DeleteContext)
+	return r
+}
+
+func asWebui(fn func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if fn == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		if !d.Get("use_webui_key").(bool) {
+			return fn(ctx, d, meta)
+		}
+
+		c, err := meta.(*chefClient).withWebuiKey()
+		if err != nil {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Error creating webui key Chef client",
+					Detail:        fmt.Sprint(err),
+					AttributePath: cty.GetAttrPath("use_webui_key"),
+				},
+			}
+		}
+		return fn(ctx, d, c)
+	}
+}
+
+// withWebuiKey returns a copy of c whose clients sign in webui key mode.
+// The copy shares c's HTTP transport, like the clients forOrganization
+// returns.
+func (c *chefClient) withWebuiKey() (*chefClient, error) {
+	global, err := cloneChefClient(c.Global, c.Global.BaseURL.String(), true)
+	if err != nil {
+		return nil, err
+	}
+	if c.Client == c.Global {
+		return &chefClient{global, global, c.options, c.Org}, nil
+	}
+
+	client, err := cloneChefClient(c.Client, c.Client.BaseURL.String(), true)
+	if err != nil {
+		return nil, err
+	}
+	return &chefClient{client, global, c.options, c.Org}, nil
+}
@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestWithWebuiKey(t *testing.T) {
+	var mu sync.Mutex
+	sources := make(map[string]string)
+	c := testChefClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sources[r.URL.Path] = r.Header.Get("X-Ops-Request-Source")
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "default", "public_key": "", "expiration_date": "infinity"}`))
+	})
+
+	r := withWebuiKey(resourceChefUserKey())
+	for user, webui := range map[string]bool{"alice": true, "bob": false} {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"user":          user,
+			"use_webui_key": webui,
+		})
+		d.SetId(user + "/default")
+		if diags := r.ReadContext(context.Background(), d, c); diags.HasError() {
+			t.Fatalf("err: %v", diags)
+		}
+	}
+
+	if got := sources["/organizations/test/users/alice/keys/default"]; got != "web" {
+		t.Errorf("expected the webui key request to be marked as from the web, got %q", got)
+	}
+	if got := sources["/organizations/test/users/bob/keys/default"]; got != "" {
+		t.Errorf("expected other requests to be unchanged, got X-Ops-Request-Source %q", got)
+	}
+	if c.IsWebuiKey || c.Global.IsWebuiKey {
+		t.Fatal("expected the provider's clients to be left alone")
+	}
+}